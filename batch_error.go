@@ -0,0 +1,55 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchError is returned by the multi-element operations (e.g. FIFO.EnqueueBatch) when some of the elements failed.
+// It keeps the error of every failed element, by its index in the batch.
+type BatchError struct {
+	errors map[int]error
+}
+
+func newBatchError() *BatchError {
+	return &BatchError{
+		errors: make(map[int]error),
+	}
+}
+
+// add adds the error for the element at the given index
+func (st *BatchError) add(index int, err error) {
+	st.errors[index] = err
+}
+
+// len returns the number of failed elements
+func (st *BatchError) len() int {
+	return len(st.errors)
+}
+
+// Errors returns the errors by the failed elements' indexes
+func (st *BatchError) Errors() map[int]error {
+	ret := make(map[int]error, len(st.errors))
+	for index, err := range st.errors {
+		ret[index] = err
+	}
+
+	return ret
+}
+
+// Error returns the error's description, including every failed element
+func (st *BatchError) Error() string {
+	indexes := make([]int, 0, len(st.errors))
+	for index := range st.errors {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	descriptions := make([]string, len(indexes))
+	for i, index := range indexes {
+		descriptions[i] = fmt.Sprintf("[%v] %v", index, st.errors[index])
+	}
+
+	return fmt.Sprintf("%v failed element(s): %v", len(indexes), strings.Join(descriptions, "; "))
+}
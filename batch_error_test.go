@@ -0,0 +1,44 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BatchErrorTestSuite struct {
+	suite.Suite
+	batchError *BatchError
+}
+
+func (suite *BatchErrorTestSuite) SetupTest() {
+	suite.batchError = newBatchError()
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestBatchErrorTestSuite(t *testing.T) {
+	suite.Run(t, new(BatchErrorTestSuite))
+}
+
+// ***************************************************************************************
+// ** Errors / Error
+// ***************************************************************************************
+
+// errors by index
+func (suite *BatchErrorTestSuite) TestErrors() {
+	errA, errB := errors.New("a"), errors.New("b")
+	suite.batchError.add(3, errB)
+	suite.batchError.add(1, errA)
+
+	suite.Equal(2, suite.batchError.len(), "Unexpected number of errors")
+	suite.Equal(map[int]error{1: errA, 3: errB}, suite.batchError.Errors(), "Unexpected errors")
+	suite.Equal("2 failed element(s): [1] a; [3] b", suite.batchError.Error(), "Unexpected description")
+
+	// Errors returns a copy
+	suite.batchError.Errors()[5] = errA
+	suite.Equal(2, suite.batchError.len(), "Errors() must return a copy")
+}
@@ -0,0 +1,94 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// BoundedGroup caps the total number of elements held by a set of FixedFIFO queues (e.g. the shards of a partitioned
+// queue), on top of their own capacities. An element could be enqueued into a member only if the members' total length
+// stays within the group's capacity: the budget gets reserved atomically, so concurrent enqueues into different members
+// never exceed it. Once the budget is exhausted the members behave as if they were at full capacity (see
+// FixedFIFO.SetOverflowPolicy), until an element leaves any of them (dequeued, dropped or expired).
+type BoundedGroup struct {
+	// elements held by the members, plus their reserved slots (accessed atomically)
+	length   int64
+	totalMax int64
+}
+
+// NewBoundedGroup returns a new group whose members (see Register) could hold up to totalMax elements altogether. A
+// totalMax < 0 is taken as 0.
+func NewBoundedGroup(totalMax int) *BoundedGroup {
+	if totalMax < 0 {
+		totalMax = 0
+	}
+
+	return &BoundedGroup{
+		totalMax: int64(totalMax),
+	}
+}
+
+// Register adds a queue to the group, its current elements (and reserved slots, see FixedFIFO.ReserveSlot) are counted
+// into the group's budget. An error is returned if the queue already belongs to a group (a queue could belong to a
+// single group, forever) or if its elements do not fit into the available budget.
+func (st *BoundedGroup) Register(queue *FixedFIFO) error {
+	// same order as the max age sweeper: no element could be enqueued/dequeued while they get counted
+	queue.lockSweep()
+	queue.enqueueRWMutex.Lock()
+	defer queue.sweepRWMutex.Unlock()
+	defer queue.enqueueRWMutex.Unlock()
+
+	if queue.getGroup() != nil {
+		return errors.New("The queue already belongs to a group")
+	}
+
+	if !st.reserve(queue.GetLen() + int(atomic.LoadInt64(&queue.reservedSlots))) {
+		return errors.New("The group's budget is exhausted")
+	}
+	queue.group.Store(st)
+
+	return nil
+}
+
+// GetLen returns the total number of elements held by the members (reserved slots included)
+func (st *BoundedGroup) GetLen() int {
+	return int(atomic.LoadInt64(&st.length))
+}
+
+// GetCap returns the maximum number of elements the members could hold altogether
+func (st *BoundedGroup) GetCap() int {
+	return int(st.totalMax)
+}
+
+// reserve takes n elements from the budget, returning false (and taking nothing) if there are not enough of them. A
+// nil group has an unlimited budget.
+func (st *BoundedGroup) reserve(n int) bool {
+	if st == nil {
+		return true
+	}
+
+	for {
+		length := atomic.LoadInt64(&st.length)
+		if length+int64(n) > st.totalMax {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&st.length, length, length+int64(n)) {
+			return true
+		}
+	}
+}
+
+// release gives n elements back to the budget
+func (st *BoundedGroup) release(n int) {
+	if st == nil {
+		return
+	}
+
+	atomic.AddInt64(&st.length, -int64(n))
+}
+
+// getGroup returns the group the queue belongs to (nil if none), see BoundedGroup.Register
+func (st *FixedFIFO) getGroup() *BoundedGroup {
+	group, _ := st.group.Load().(*BoundedGroup)
+	return group
+}
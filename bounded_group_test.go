@@ -0,0 +1,147 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BoundedGroupTestSuite struct {
+	suite.Suite
+	group  *BoundedGroup
+	shards []*FixedFIFO
+}
+
+func (suite *BoundedGroupTestSuite) SetupTest() {
+	suite.group = NewBoundedGroup(5)
+	suite.shards = []*FixedFIFO{NewFixedFIFO(4), NewFixedFIFO(4)}
+	for _, shard := range suite.shards {
+		suite.NoError(suite.group.Register(shard), "Unexpected error")
+	}
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestBoundedGroupTestSuite(t *testing.T) {
+	suite.Run(t, new(BoundedGroupTestSuite))
+}
+
+// ***************************************************************************************
+// ** Register
+// ***************************************************************************************
+
+// the registered queue's elements get counted
+func (suite *BoundedGroupTestSuite) TestRegister() {
+	group := NewBoundedGroup(3)
+	queue := NewFixedFIFO(4)
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	_, _, err := queue.ReserveSlot()
+	suite.NoError(err, "Unexpected error")
+
+	suite.NoError(group.Register(queue), "Unexpected error")
+	suite.Equal(3, group.GetLen(), "The elements and the reserved slots must be counted")
+	suite.Equal(3, group.GetCap(), "Wrong capacity")
+
+	suite.Error(group.Register(queue), "A queue could be registered once")
+	suite.Error(suite.group.Register(queue), "A queue could belong to a single group")
+
+	other := NewFixedFIFO(4)
+	other.Enqueue(1)
+	suite.Error(group.Register(other), "The elements must fit into the budget")
+	suite.Equal(3, group.GetLen(), "Nothing must be counted if the queue could not be registered")
+}
+
+// ***************************************************************************************
+// ** Budget
+// ***************************************************************************************
+
+// the budget is shared by all the members
+func (suite *BoundedGroupTestSuite) TestBudget() {
+	for i := 0; i < 4; i++ {
+		suite.NoError(suite.shards[0].Enqueue(i), "Unexpected error")
+	}
+	suite.NoError(suite.shards[1].Enqueue(4), "Unexpected error")
+	suite.Equal(5, suite.group.GetLen(), "Wrong group's length")
+
+	suite.Error(suite.shards[1].Enqueue(5), "The group's budget is exhausted")
+	suite.Equal(1, suite.shards[1].GetLen(), "The element must not be enqueued")
+	_, _, err := suite.shards[1].ReserveSlot()
+	suite.Error(err, "No slot could be reserved once the budget is exhausted")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	suite.Error(suite.shards[1].EnqueueBatchOrWait(ctx, []interface{}{5}), "No batch fits")
+
+	suite.shards[0].Dequeue()
+	suite.Equal(4, suite.group.GetLen(), "The dequeued element gives its budget back")
+	suite.NoError(suite.shards[1].Enqueue(5), "Unexpected error")
+}
+
+// dropped elements give their budget back, no element could be dropped from an empty member
+func (suite *BoundedGroupTestSuite) TestBudgetDropOldest() {
+	for i := 0; i < 4; i++ {
+		suite.shards[0].Enqueue(i)
+	}
+	suite.shards[0].SetOverflowPolicy(OverflowDropOldest)
+	suite.shards[1].SetOverflowPolicy(OverflowDropOldest)
+	suite.shards[1].Enqueue(4)
+
+	suite.NoError(suite.shards[1].Enqueue(5), "The oldest element must be dropped")
+	suite.Equal(1, suite.shards[1].GetLen(), "Wrong length")
+	suite.Equal(5, suite.group.GetLen(), "Wrong group's length")
+
+	suite.shards[1].Dequeue()
+	suite.NoError(suite.shards[0].Enqueue(6), "The oldest element must be dropped")
+	suite.Equal(4, suite.group.GetLen(), "Wrong group's length")
+
+	group, full, empty := NewBoundedGroup(2), NewFixedFIFO(4), NewFixedFIFO(4)
+	group.Register(full)
+	group.Register(empty)
+	full.Enqueue(1)
+	full.Enqueue(2)
+	empty.SetOverflowPolicy(OverflowDropOldest)
+	suite.Error(empty.Enqueue(3), "Nothing could be dropped from this member")
+	suite.Equal(0, empty.GetLen(), "The element must not be enqueued")
+}
+
+// reserved slots hold their budget until committed/canceled
+func (suite *BoundedGroupTestSuite) TestBudgetReserveSlot() {
+	commit, _, err := suite.shards[0].ReserveSlot()
+	suite.NoError(err, "Unexpected error")
+	_, cancel, err := suite.shards[1].ReserveSlot()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, suite.group.GetLen(), "The reserved slots must be counted")
+
+	commit(1)
+	cancel()
+	suite.Equal(1, suite.group.GetLen(), "The canceled slot gives its budget back")
+	suite.Equal(1, suite.shards[0].GetLen(), "The committed element must be enqueued")
+}
+
+// concurrent producers never exceed the budget
+func (suite *BoundedGroupTestSuite) TestBudgetMultipleGRs() {
+	var (
+		wg       sync.WaitGroup
+		enqueued int64
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(shard *FixedFIFO) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if shard.Enqueue(j) == nil {
+					atomic.AddInt64(&enqueued, 1)
+				}
+			}
+		}(suite.shards[i%2])
+	}
+	wg.Wait()
+
+	suite.Equal(int64(5), enqueued, "The budget must not be exceeded")
+	suite.Equal(5, suite.shards[0].GetLen()+suite.shards[1].GetLen(), "Wrong members' length")
+}
@@ -0,0 +1,122 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ByteFixedFIFO is a FIFO (First In First Out) concurrent queue of []byte elements, having a fixed capacity measured in
+// bytes: the total length of the enqueued elements can't exceed maxBytes.
+type ByteFixedFIFO struct {
+	slice       [][]byte
+	bytes       int
+	maxBytes    int
+	rwmutex     sync.RWMutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+}
+
+// NewByteFixedFIFO returns a new ByteFixedFIFO concurrent queue
+func NewByteFixedFIFO(maxBytes int) *ByteFixedFIFO {
+	ret := &ByteFixedFIFO{}
+	ret.initialize(maxBytes)
+
+	return ret
+}
+
+func (st *ByteFixedFIFO) initialize(maxBytes int) {
+	st.slice = make([][]byte, 0)
+	st.maxBytes = maxBytes
+}
+
+// Enqueue enqueues a []byte element. An error will be returned if the element is not a []byte or if there is not
+// enough room left for it.
+func (st *ByteFixedFIFO) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return errors.New("The queue is locked")
+	}
+
+	element, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("ByteFixedFIFO only accepts []byte elements, got: %T", value)
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.bytes+len(element) > st.maxBytes {
+		return fmt.Errorf("ByteFixedFIFO queue is at full capacity: %v + %v bytes > %v bytes", st.bytes, len(element), st.maxBytes)
+	}
+
+	st.slice = append(st.slice, element)
+	st.bytes += len(element)
+
+	return nil
+}
+
+// Dequeue dequeues a []byte element
+func (st *ByteFixedFIFO) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if len(st.slice) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	element := st.slice[0]
+	st.slice[0] = nil
+	st.slice = st.slice[1:]
+	st.bytes -= len(element)
+
+	return element, nil
+}
+
+// GetBytes returns the total bytes of the enqueued elements
+func (st *ByteFixedFIFO) GetBytes() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.bytes
+}
+
+// GetLen returns the number of enqueued elements
+func (st *ByteFixedFIFO) GetLen() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return len(st.slice)
+}
+
+// GetCap returns the queue's capacity, in bytes
+func (st *ByteFixedFIFO) GetCap() int {
+	return st.maxBytes
+}
+
+// Lock locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *ByteFixedFIFO) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *ByteFixedFIFO) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *ByteFixedFIFO) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
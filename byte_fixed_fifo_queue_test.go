@@ -0,0 +1,102 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	byteFixedFIFOMaxBytes = 10
+)
+
+type ByteFixedFIFOTestSuite struct {
+	suite.Suite
+	fifo *ByteFixedFIFO
+}
+
+func (suite *ByteFixedFIFOTestSuite) SetupTest() {
+	suite.fifo = NewByteFixedFIFO(byteFixedFIFOMaxBytes)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestByteFixedFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(ByteFixedFIFOTestSuite))
+}
+
+// ***************************************************************************************
+// ** Queue interface
+// ***************************************************************************************
+
+// ByteFixedFIFO implements the Queue interface
+func (suite *ByteFixedFIFOTestSuite) TestQueueInterface() {
+	var queue Queue = suite.fifo
+
+	suite.Equal(byteFixedFIFOMaxBytes, queue.GetCap(), "The capacity must be measured in bytes")
+	queue.Lock()
+	suite.True(queue.IsLocked(), "Queue must be locked after Lock()")
+	suite.Error(queue.Enqueue([]byte("a")), "Locked queue does not allow to enqueue elements")
+	_, err := queue.Dequeue()
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+	queue.Unlock()
+	suite.False(queue.IsLocked(), "Queue must be unlocked after Unlock()")
+}
+
+// ***************************************************************************************
+// ** Enqueue / Dequeue / GetBytes
+// ***************************************************************************************
+
+// enqueue && dequeue []byte elements
+func (suite *ByteFixedFIFOTestSuite) TestEnqueueDequeueSingleGR() {
+	suite.NoError(suite.fifo.Enqueue([]byte("hello")), "Unexpected error")
+	suite.NoError(suite.fifo.Enqueue([]byte("world")), "Unexpected error")
+	suite.Equal(10, suite.fifo.GetBytes(), "Unexpected bytes")
+	suite.Equal(2, suite.fifo.GetLen(), "Unexpected length")
+
+	val, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]byte("hello"), val, "Wrong element's value")
+	suite.Equal(5, suite.fifo.GetBytes(), "Unexpected bytes")
+}
+
+// elements exceeding the available bytes are rejected
+func (suite *ByteFixedFIFOTestSuite) TestEnqueueFullCapacitySingleGR() {
+	suite.NoError(suite.fifo.Enqueue([]byte("123456")), "Unexpected error")
+	suite.Error(suite.fifo.Enqueue([]byte("12345")), "error expected exceeding the max bytes")
+	suite.NoError(suite.fifo.Enqueue([]byte("1234")), "Unexpected error")
+	suite.Equal(byteFixedFIFOMaxBytes, suite.fifo.GetBytes(), "Unexpected bytes")
+}
+
+// non []byte elements are rejected
+func (suite *ByteFixedFIFOTestSuite) TestEnqueueTypeErrorSingleGR() {
+	suite.Error(suite.fifo.Enqueue("string"), "error expected for non []byte elements")
+	suite.Equal(0, suite.fifo.GetLen(), "Unexpected length")
+}
+
+// dequeue an empty queue
+func (suite *ByteFixedFIFOTestSuite) TestDequeueEmptyQueueSingleGR() {
+	val, err := suite.fifo.Dequeue()
+	suite.Error(err, "Can't dequeue an empty queue")
+	suite.Nil(val, "Can't get a value different than nil from an empty queue")
+}
+
+// concurrent enqueues never exceed the max bytes
+func (suite *ByteFixedFIFOTestSuite) TestEnqueueMultipleGRs() {
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			suite.fifo.Enqueue([]byte("123"))
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(9, suite.fifo.GetBytes(), "Unexpected bytes")
+	suite.Equal(3, suite.fifo.GetLen(), "Unexpected length")
+}
@@ -0,0 +1,30 @@
+package goconcurrentqueue
+
+import (
+	"time"
+)
+
+// Clock provides the time to the queues' time based features (timestamps, windows, timeouts, ...). A custom Clock
+// (see FIFO.SetClock and FixedFIFO.SetClock) lets tests control the time.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock backed by the time package (default)
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// clockHolder wraps a Clock to be stored in an atomic.Value (it requires a consistent concrete type)
+type clockHolder struct {
+	clock Clock
+}
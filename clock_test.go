@@ -0,0 +1,42 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves forward by Advance (or by After, which advances to the deadline and fires
+// immediately)
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now: time.Unix(1000, 0),
+	}
+}
+
+func (st *fakeClock) Now() time.Time {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.now
+}
+
+func (st *fakeClock) After(d time.Duration) <-chan time.Time {
+	st.Advance(d)
+
+	ch := make(chan time.Time, 1)
+	ch <- st.Now()
+	return ch
+}
+
+// Advance moves the time forward
+func (st *fakeClock) Advance(d time.Duration) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.now = st.now.Add(d)
+}
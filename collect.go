@@ -0,0 +1,42 @@
+//go:build go1.18
+// +build go1.18
+
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Collect dequeues all the elements of q (it consumes the queue) returning them as a []T, in dequeue order (dequeue
+// transform applied). If any element is not a T, a QueueError (QueueErrorCodeTypeMismatch) is returned and the
+// elements are returned to the queue's head, in their original order (see FIFO.DequeueTx). An empty queue returns an
+// empty slice.
+func Collect[T any](q *FIFO) ([]T, error) {
+	tx, err := q.DequeueTx(int(^uint(0) >> 1))
+	if err != nil {
+		if q.IsLocked() {
+			return nil, err
+		}
+		// empty queue
+		return []T{}, nil
+	}
+
+	elements := tx.Elements()
+	ret := make([]T, len(elements))
+	for i, element := range elements {
+		value, ok := element.(T)
+		if !ok {
+			tx.Rollback()
+			return nil, NewQueueError(QueueErrorCodeTypeMismatch, fmt.Sprintf("element %v is %T, not %v", i, element,
+				reflect.TypeOf((*T)(nil)).Elem()))
+		}
+		ret[i] = value
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
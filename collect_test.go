@@ -0,0 +1,75 @@
+//go:build go1.18
+// +build go1.18
+
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ***************************************************************************************
+// ** Collect
+// ***************************************************************************************
+
+// single Collect lock verification
+func (suite *FIFOTestSuite) TestCollectLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := Collect[int](suite.fifo)
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// the elements are returned as a typed slice
+func (suite *FIFOTestSuite) TestCollectSingleGR() {
+	values, err := Collect[int](suite.fifo)
+	suite.NoError(err, "No error expected for an empty queue")
+	suite.Equal([]int{}, values, "Empty slice expected")
+
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	values, err = Collect[int](suite.fifo)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]int{0, 1, 2}, values, "Wrong collected elements")
+	suite.Equal(0, suite.fifo.GetLen(), "Collect must consume the queue")
+
+	// interfaces
+	suite.fifo.Enqueue(fmt.Errorf("a"))
+	errs, err := Collect[error](suite.fifo)
+	suite.NoError(err, "Unexpected error")
+	suite.Len(errs, 1, "Wrong collected elements")
+}
+
+// a type mismatch keeps the queue untouched
+func (suite *FIFOTestSuite) TestCollectTypeMismatchSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue("2")
+	suite.fifo.Enqueue(3)
+
+	_, err := Collect[int](suite.fifo)
+	queueErr, ok := err.(*QueueError)
+	suite.True(ok, "QueueError expected")
+	suite.Equal(QueueErrorCodeTypeMismatch, queueErr.Code(), "Wrong error code")
+	suite.Equal([]interface{}{1, "2", 3}, dequeueAll(suite.fifo), "The queue must be kept untouched")
+}
+
+// collect while other GRs enqueue
+func (suite *FIFOTestSuite) TestCollectMultipleGRs() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+
+	total := 0
+	for total < 100 {
+		values, err := Collect[int](suite.fifo)
+		suite.NoError(err, "Unexpected error")
+		total += len(values)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,59 @@
+package goconcurrentqueue
+
+import (
+	"reflect"
+)
+
+// Equaler is implemented by elements that define their own equality. It is used by the queues' operations that compare
+// elements (FIFO.Contains, FIFO.EnqueueUnique, ...).
+type Equaler interface {
+	// Equals returns true whether the element is equal to other
+	Equals(other interface{}) bool
+}
+
+// equal returns true whether a and b are equal. a.Equals(b) is used if a implements Equaler, otherwise a == b
+// (uncomparable values, like slices or maps, are never equal; neither are the structs or arrays holding them).
+func equal(a, b interface{}) (isEqual bool) {
+	if equaler, ok := a.(Equaler); ok {
+		return equaler.Equals(b)
+	}
+
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+
+	// a comparable type could still hold an uncomparable value (e.g. a slice at an interface{} field): == panics
+	defer func() {
+		if recover() != nil {
+			isEqual = false
+		}
+	}()
+
+	return a == b
+}
+
+// hashable returns true whether value could be used as a map key (uncomparable values, like slices or maps, can't;
+// neither can the structs or arrays holding them)
+func hashable(value interface{}) (isHashable bool) {
+	if value == nil {
+		return true
+	}
+
+	if !reflect.TypeOf(value).Comparable() {
+		return false
+	}
+
+	// hashing panics for the same values == does, see equal
+	defer func() {
+		if recover() != nil {
+			isHashable = false
+		}
+	}()
+	_ = value == value
+
+	return true
+}
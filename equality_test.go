@@ -0,0 +1,68 @@
+package goconcurrentqueue
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// caseInsensitive implements Equaler
+type caseInsensitive string
+
+func (st caseInsensitive) Equals(other interface{}) bool {
+	o, ok := other.(caseInsensitive)
+	return ok && strings.EqualFold(string(st), string(o))
+}
+
+type equalityTestSuite struct {
+	suite.Suite
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestEqualityTestSuite(t *testing.T) {
+	suite.Run(t, new(equalityTestSuite))
+}
+
+// ***************************************************************************************
+// ** equal
+// ***************************************************************************************
+
+// comparable values
+func (suite *equalityTestSuite) TestComparable() {
+	suite.True(equal(1, 1), "Equal values expected")
+	suite.False(equal(1, 2), "Different values expected")
+	suite.False(equal(1, "1"), "Different types must not be equal")
+	suite.True(equal(nil, nil), "nil values must be equal")
+	suite.False(equal(nil, 1), "nil must not be equal to a non nil value")
+}
+
+// uncomparable values are never equal (no panic)
+func (suite *equalityTestSuite) TestUncomparable() {
+	suite.False(equal([]int{1}, []int{1}), "Uncomparable values must not be equal")
+	suite.False(equal(map[int]int{}, 1), "Uncomparable values must not be equal")
+
+	// comparable types holding uncomparable values
+	type holder struct{ v interface{} }
+	suite.False(equal(holder{[]int{1}}, holder{[]int{1}}), "Structs holding uncomparable values must not be equal")
+	suite.False(equal([1]interface{}{map[int]int{}}, [1]interface{}{map[int]int{}}), "Arrays holding uncomparable values must not be equal")
+	suite.True(equal(holder{1}, holder{1}), "Equal values expected")
+}
+
+// values usable as map keys
+func (suite *equalityTestSuite) TestHashable() {
+	suite.True(hashable(1), "Comparable values must be hashable")
+	suite.True(hashable(nil), "nil must be hashable")
+	suite.False(hashable([]int{1}), "Uncomparable values must not be hashable")
+	suite.False(hashable(struct{ v interface{} }{[]int{1}}), "Structs holding uncomparable values must not be hashable")
+	suite.True(hashable(struct{ v interface{} }{1}), "Comparable values must be hashable")
+}
+
+// Equaler values
+func (suite *equalityTestSuite) TestEqualer() {
+	suite.True(equal(caseInsensitive("Hello"), caseInsensitive("hELLO")), "Equals must be used")
+	suite.False(equal(caseInsensitive("Hello"), "Hello"), "Equals must be used")
+}
@@ -0,0 +1,66 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FanIn starts a goroutine per source moving its elements into dst (as soon as they get enqueued), returning the
+// function to stop them. The relative order of the elements of the same source is preserved.
+//
+// dst's overflow policy (see FixedFIFO.SetOverflowPolicy) decides what happens once dst is full: OverflowReject
+// (default) blocks the source's goroutine until dst has an available slot, OverflowDropOldest/OverflowDropNewest drop
+// elements. An element waiting for a slot when stop gets called is enqueued back into its source (at the tail), or
+// discarded through the source's dead letter handler if it could not be (see FixedFIFO.SetDeadLetterHandler).
+// Locked sources/dst make the goroutines wait until they get unlocked.
+func FanIn(dst *FixedFIFO, sources ...*FixedFIFO) (stop func()) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		wg          sync.WaitGroup
+	)
+
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source *FixedFIFO) {
+			defer wg.Done()
+			fanIn(ctx, dst, source)
+		}(source)
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// fanIn moves the source's elements into dst until ctx gets done
+func fanIn(ctx context.Context, dst, source *FixedFIFO) {
+	for {
+		value, err := source.DequeueOrWaitForNextElementContext(ctx)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(workerLockedRetryWait):
+				// locked (or paused) source
+				continue
+			}
+		}
+
+		backoff := enqueueBackoffMin
+		for dst.Enqueue(value) != nil {
+			select {
+			case <-ctx.Done():
+				source.requeue(value)
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > enqueueBackoffMax {
+				backoff = enqueueBackoffMax
+			}
+		}
+	}
+}
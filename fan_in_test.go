@@ -0,0 +1,165 @@
+package goconcurrentqueue
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FanInTestSuite struct {
+	suite.Suite
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestFanInTestSuite(t *testing.T) {
+	suite.Run(t, new(FanInTestSuite))
+}
+
+// ***************************************************************************************
+// ** FanIn
+// ***************************************************************************************
+
+// waitForLen waits (up to one second) until queue has len elements
+func waitForLen(queue Queue, len int) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if queue.GetLen() == len {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	return false
+}
+
+// the elements of every source get moved into dst, keeping their relative order
+func (suite *FanInTestSuite) TestFanInMultipleGRs() {
+	var (
+		totalElements = 50
+		dst           = NewFixedFIFO(2 * totalElements)
+		sourceA       = NewFixedFIFO(totalElements)
+		sourceB       = NewFixedFIFO(totalElements)
+	)
+
+	stop := FanIn(dst, sourceA, sourceB)
+	defer stop()
+
+	for i := 0; i < totalElements; i++ {
+		sourceA.Enqueue(i)
+		sourceB.Enqueue(totalElements + i)
+	}
+	suite.True(waitForLen(dst, 2*totalElements), "All the elements must be moved into dst")
+
+	nextA, nextB := 0, totalElements
+	for _, value := range dst.TryDequeueN(2 * totalElements) {
+		if value.(int) < totalElements {
+			suite.Equal(nextA, value, "The relative order of the source's elements must be preserved")
+			nextA++
+		} else {
+			suite.Equal(nextB, value, "The relative order of the source's elements must be preserved")
+			nextB++
+		}
+	}
+}
+
+// a full dst blocks the sources, the waiting element goes back to its source on stop
+func (suite *FanInTestSuite) TestFanInFullDstMultipleGRs() {
+	var (
+		dst    = NewFixedFIFO(1)
+		source = NewFixedFIFO(5)
+	)
+	for i := 0; i < 3; i++ {
+		source.Enqueue(i)
+	}
+
+	stop := FanIn(dst, source)
+	suite.True(waitForLen(source, 1), "The source must be waiting for an available slot")
+	suite.Equal(1, dst.GetLen(), "dst must be full")
+
+	value, _ := dst.Dequeue()
+	suite.Equal(0, value, "Wrong element's value")
+	suite.True(waitForLen(source, 0), "The waiting element must be moved once a slot gets available")
+
+	stop()
+	suite.Equal(1, source.GetLen(), "The waiting element must be enqueued back into its source")
+	value, _ = dst.Dequeue()
+	suite.Equal(1, value, "Wrong element's value")
+	value, _ = source.Dequeue()
+	suite.Equal(2, value, "Wrong element's value")
+}
+
+// dst dropping elements (overflow policy)
+func (suite *FanInTestSuite) TestFanInDropMultipleGRs() {
+	var (
+		dst    = NewFixedFIFO(1)
+		source = NewFixedFIFO(5)
+	)
+	dst.SetOverflowPolicy(OverflowDropNewest)
+	for i := 0; i < 3; i++ {
+		source.Enqueue(i)
+	}
+
+	stop := FanIn(dst, source)
+	defer stop()
+
+	suite.True(waitForLen(source, 0), "The elements must be moved (or dropped)")
+	value, _ := dst.Dequeue()
+	suite.Equal(0, value, "The newest elements must be dropped")
+}
+
+// stop returns while a source's dequeues are paused
+func (suite *FanInTestSuite) TestFanInStopPausedSourceMultipleGRs() {
+	var (
+		dst    = NewFixedFIFO(1)
+		source = NewFixedFIFO(1)
+	)
+	source.PauseDequeue()
+
+	stop := FanIn(dst, source)
+	for atomic.LoadInt64(&source.dequeueWaiters) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		suite.Fail("stop must return while the source is paused")
+	}
+}
+
+// the waiting element gets discarded if it could not be enqueued back into its source
+func (suite *FanInTestSuite) TestFanInStopLockedSourceMultipleGRs() {
+	var (
+		dst     = NewFixedFIFO(1)
+		source  = NewFixedFIFO(1)
+		dropped = make(chan interface{}, 1)
+	)
+	dst.Enqueue(0)
+	source.Enqueue(1)
+	source.SetDeadLetterHandler(func(value interface{}, reason string) {
+		suite.Equal(DeadLetterReasonRequeueFailed, reason, "Unexpected reason")
+		dropped <- value
+	})
+
+	stop := FanIn(dst, source)
+	suite.True(waitForLen(source, 0), "The source must be waiting for an available slot")
+	source.Lock()
+	stop()
+
+	select {
+	case value := <-dropped:
+		suite.Equal(1, value, "The waiting element must be discarded")
+	default:
+		suite.Fail("The waiting element must be discarded")
+	}
+}
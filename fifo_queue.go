@@ -1,18 +1,150 @@
 package goconcurrentqueue
 
 import (
+	"container/list"
+	"context"
 	"fmt"
+	"math/rand"
+	"sort"
 	"sync"
+	"time"
+	"unsafe"
 
 	"github.com/pkg/errors"
 )
 
+// DequeueMeta holds extra information about a dequeued element, see FIFO.DequeueWithMeta
+type DequeueMeta struct {
+	// time the element spent at the queue (0 if timestamp tracking is disabled)
+	TimeInQueue time.Duration
+	// number of enqueued elements after the element was removed
+	Len int
+}
+
+// Reasons to discard an element, see FIFO.SetDeadLetterHandler and FixedFIFO.SetDeadLetterHandler
+const (
+	DeadLetterReasonOverflow       = "overflow"
+	DeadLetterReasonExpired        = "expired"
+	DeadLetterReasonValidation     = "validation"
+	DeadLetterReasonRetryExhausted = "retry_exhausted"
+	DeadLetterReasonUnmatched      = "unmatched"
+	DeadLetterReasonRequeueFailed  = "requeue_failed"
+	DeadLetterReasonCanceled       = "canceled"
+)
+
+// WalkAction defines what FIFO.Walk does with the visited element
+type WalkAction int
+
+const (
+	// WalkKeep keeps the visited element and visits the next one
+	WalkKeep WalkAction = iota
+	// WalkRemove removes the visited element and visits the next one
+	WalkRemove
+	// WalkStop keeps the visited element and stops visiting
+	WalkStop
+)
+
+// elementMeta keeps per-element bookkeeping
+type elementMeta struct {
+	enqueuedAt time.Time
+	// closed once the element leaves the queue (nil == nobody waits for it), see EnqueueWithDone
+	done chan struct{}
+	// element's key, see EnqueueOrUpdate
+	key   string
+	keyed bool
+	// context the element was enqueued with (nil == none), see EnqueueCtx
+	ctx context.Context
+}
+
 // FIFO (First In First Out) concurrent queue
 type FIFO struct {
-	slice       []interface{}
+	// 64-bit averages (accessed atomically) are kept first to guarantee their alignment on 32-bit platforms, see
+	// OperationLatency
+	enqueueLatency int64
+	dequeueLatency int64
+	// rejected enqueues by reason (accessed atomically), see RejectionStats
+	rejections [rejectionReasonCount]uint64
+	// 1 == the operation latency gets tracked (accessed atomically), see SetTrackOpLatency
+	trackOpLatency int32
+
+	slice []interface{}
+	// per-element metadata, meta[i] belongs to slice[i] (nil == no metadata is tracked)
+	meta        []elementMeta
 	rwmutex     sync.RWMutex
 	lockRWmutex sync.RWMutex
 	isLocked    bool
+	// latest dequeued elements (nil == disabled)
+	history *ring
+	// function to be called for every discarded element
+	deadLetterHandler func(value interface{}, reason string)
+	// EnqueueDebounced: last accepted enqueue per key
+	debounceLastSeen map[string]time.Time
+	// EnqueueDebounced: largest window used so far && last cleanup of debounceLastSeen
+	debounceMaxWindow   time.Duration
+	debounceLastCleanup time.Time
+	// EnqueueIdempotent: accepted keys (sorted by acceptance time) && how long/how many of them are kept
+	idempotencyKeys      map[string]*list.Element
+	idempotencyOrder     *list.List
+	idempotencyRetention time.Duration
+	idempotencyMaxKeys   int
+	// function applied to every dequeued element (nil == identity): the dequeue clone followed by the dequeue transform
+	dequeueTransform func(interface{}) interface{}
+	// function applied to every element before being enqueued (nil == identity)
+	enqueueTransform func(interface{}) (interface{}, error)
+	// functions to copy the enqueued/dequeued elements (nil == no copy) && the dequeue transform as it was set, see
+	// SetCloneOnEnqueue, SetCloneOnDequeue and SetDequeueTransform
+	cloneOnEnqueue      func(interface{}) interface{}
+	cloneOnDequeue      func(interface{}) interface{}
+	dequeueTransformSet func(interface{}) interface{}
+	// max size of the enqueued elements && function returning their size (nil == unlimited), see SetMaxElementSize
+	maxElementSize int
+	elementSizer   func(interface{}) int
+	// closed (and set to nil) once a new element gets enqueued, it wakes up the waiting consumers
+	enqueueSignalChan chan struct{}
+	// pool where the processed elements are returned (see RecycleDequeued) && function to reset them
+	elementPool  *sync.Pool
+	elementReset func(interface{})
+	// per-key quota, see SetKeyQuota
+	quotaKeyFn  func(interface{}) string
+	quotaMax    int
+	quotaCounts map[string]int
+	// random generator to pick the element to dequeue (nil == strict FIFO order), see SetDequeueShuffle
+	shuffleRand *rand.Rand
+	// time provider (nil == real time), see SetClock
+	clock Clock
+	// whether the enqueue time of the elements gets tracked, see SetTimestampTracking
+	timestampTracking bool
+	// enqueue/dequeue hooks && hook calls waiting for the lock to be released, see SetHooksSynchronous
+	enqueueHook      func(interface{})
+	dequeueHook      func(interface{})
+	hooksSynchronous bool
+	pendingHooks     []hookCall
+	// 1 == there are pending hook calls (accessed atomically)
+	hooksPending int32
+	// total enqueued && dequeued elements, see WaitUntilQuiescent
+	operations uint64
+	// functions to encode/decode the elements, see SetCodec
+	codecEncode func(interface{}) ([]byte, error)
+	codecDecode func([]byte) (interface{}, error)
+	// queue receiving the enqueued elements (nil == no redirect), see SetEnqueueRedirect
+	enqueueRedirect *FIFO
+	// recorded operations, see StartRecording
+	recording    bool
+	operationLog []OpRecord
+	// elements' order (nil == FIFO order), see SetOrdering
+	less func(a, b interface{}) bool
+	// value returned by Dequeue if the queue is empty, see SetEmptyValue
+	emptyValue    interface{}
+	emptyValueSet bool
+	// next expected sequence && function returning the elements' sequence (nil == disabled), see SetExpectedSequence
+	expectedSeq uint64
+	seqOf       func(interface{}) uint64
+	// sequence number to be assigned to the next enqueued element, see EnqueueBatchSeq
+	enqueueSeq uint64
+	// closed to stop the starvation monitor (nil == not running), see SetStarvationThreshold
+	starvationStop chan struct{}
+	// changes every time the first element changes, see PeekVersioned
+	headVersion uint64
 }
 
 // NewFIFO returns a new FIFO concurrent queue
@@ -29,35 +161,585 @@ func (st *FIFO) initialize() {
 
 // Enqueue enqueues an element
 func (st *FIFO) Enqueue(value interface{}) error {
+	defer trackOpLatency(&st.enqueueLatency, st.opLatencyStart())
+
 	if st.isLocked {
-		return errors.New("The queue is locked")
+		return st.rejectLocked()
+	}
+
+	if redirect := st.getEnqueueRedirect(); redirect != nil {
+		return redirect.enqueueHere(value)
+	}
+
+	return st.enqueueHere(value)
+}
+
+// enqueueHere is Enqueue ignoring the enqueue redirect
+func (st *FIFO) enqueueHere(value interface{}) error {
+	if st.isLocked {
+		return st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	_, err = st.enqueue(value)
+	return err
+}
+
+// EnqueueBatch enqueues multiple elements in order. The elements that could not be enqueued (e.g. rejected by the
+// enqueue transform or the key quota) are reported by a *BatchError, the rest of them are enqueued anyway.
+func (st *FIFO) EnqueueBatch(values []interface{}) error {
+	if st.isLocked {
+		return st.rejectLocked()
+	}
+
+	if redirect := st.getEnqueueRedirect(); redirect != nil {
+		return redirect.enqueueBatchHere(values)
+	}
+
+	return st.enqueueBatchHere(values)
+}
+
+// enqueueBatchHere is EnqueueBatch ignoring the enqueue redirect
+func (st *FIFO) enqueueBatchHere(values []interface{}) error {
+	if st.isLocked {
+		return st.rejectLocked()
+	}
+
+	var (
+		batchError  = newBatchError()
+		transformed = make([]interface{}, len(values))
+	)
+	for i, value := range values {
+		var err error
+		if transformed[i], err = st.transformEnqueued(value); err != nil {
+			batchError.add(i, err)
+		}
+	}
+
+	st.rwmutex.Lock()
+	for i, value := range transformed {
+		if _, failed := batchError.errors[i]; failed {
+			continue
+		}
+
+		if _, err := st.enqueue(value); err != nil {
+			batchError.add(i, err)
+		}
+	}
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	if batchError.len() > 0 {
+		return batchError
+	}
+
+	return nil
+}
+
+// SetEnqueueRedirect makes Enqueue and EnqueueBatch enqueue the elements into dst instead of this queue (using dst's
+// lock, enqueue transform, quota, ...), e.g. to drain this queue while the new elements accumulate at dst. The redirect
+// is not followed transitively: dst's own redirect gets ignored. A nil dst (or this same queue) restores the default
+// behavior. The rest of the enqueue methods are not redirected.
+func (st *FIFO) SetEnqueueRedirect(dst *FIFO) {
+	if dst == st {
+		dst = nil
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.enqueueRedirect = dst
+}
+
+// getEnqueueRedirect returns the queue the enqueued elements get redirected to (nil == no redirect)
+func (st *FIFO) getEnqueueRedirect() *FIFO {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.enqueueRedirect
+}
+
+// enqueue adds an element at the tail (or at its sorted position, see SetOrdering), returning the index it was inserted
+// at. It must be called holding st.rwmutex.
+func (st *FIFO) enqueue(value interface{}) (int, error) {
+	if err := st.checkKeyQuota(value); err != nil {
+		return -1, err
+	}
+
+	index := len(st.slice)
+	if st.less != nil {
+		// after the elements not greater than value (stable)
+		index = sort.Search(len(st.slice), func(i int) bool { return st.less(value, st.slice[i]) })
+	}
+	st.insertAt(index, value)
+
+	return index, nil
+}
+
+// insertAt inserts an element at the given index. It must be called holding st.rwmutex.
+func (st *FIFO) insertAt(index int, value interface{}) {
+	if index == 0 {
+		st.headChanged()
+	}
+	if index == len(st.slice) {
+		st.slice = append(st.slice, value)
+		if st.meta != nil {
+			st.meta = append(st.meta, st.newElementMeta())
+		}
+	} else {
+		st.slice = append(st.slice, nil)
+		copy(st.slice[index+1:], st.slice[index:])
+		st.slice[index] = value
+		if st.meta != nil {
+			st.meta = append(st.meta, elementMeta{})
+			copy(st.meta[index+1:], st.meta[index:])
+			st.meta[index] = st.newElementMeta()
+		}
+	}
+	st.trackKey(value, 1)
+	st.enqueueSeq++
+	st.enqueued(value)
+	st.signalEnqueue()
+}
+
+// EnqueueAt inserts an element at the given index (0 == head, GetLen() == tail), shifting the later elements back
+func (st *FIFO) EnqueueAt(index int, value interface{}) error {
+	if st.isLocked {
+		return st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if index < 0 || index > len(st.slice) {
+		return fmt.Errorf("index out of bounds: %v", index)
 	}
 
+	if err := st.checkKeyQuota(value); err != nil {
+		return err
+	}
+	st.insertAt(index, value)
+
+	return nil
+}
+
+// EnqueueBatchFront inserts all the given elements at the head, keeping their order: values[0] becomes the next element
+// to be dequeued, e.g. to put back a dequeued batch that could not be processed. The elements are inserted at once
+// (under a single lock) and none of them gets enqueued if any of them fails (enqueue transform, key quota, ...). The
+// ordering (see SetOrdering) does not apply to them.
+func (st *FIFO) EnqueueBatchFront(values []interface{}) error {
+	if st.isLocked {
+		return st.rejectLocked()
+	}
+
+	transformed := make([]interface{}, len(values))
+	for i, value := range values {
+		var err error
+		if transformed[i], err = st.transformEnqueued(value); err != nil {
+			return fmt.Errorf("element %v could not be enqueued: %v", i, err)
+		}
+	}
+	if len(transformed) == 0 {
+		return nil
+	}
+
+	defer st.runPendingHooks()
 	st.rwmutex.Lock()
 	defer st.rwmutex.Unlock()
 
-	st.slice = append(st.slice, value)
+	if err := st.checkKeyQuotaBatch(transformed); err != nil {
+		return err
+	}
+	st.insertFront(transformed)
+
 	return nil
 }
 
+// insertFront inserts the elements at the head keeping their order (values[0] becomes the first element), ignoring the
+// key quota. It must be called holding st.rwmutex.
+func (st *FIFO) insertFront(values []interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	slice := make([]interface{}, 0, len(values)+len(st.slice))
+	st.slice = append(append(slice, values...), st.slice...)
+	if st.meta != nil {
+		meta := make([]elementMeta, 0, len(st.slice))
+		for range values {
+			meta = append(meta, st.newElementMeta())
+		}
+		st.meta = append(meta, st.meta...)
+	}
+	for _, value := range values {
+		st.trackKey(value, 1)
+		st.enqueueSeq++
+		st.enqueued(value)
+	}
+	st.headChanged()
+	st.signalEnqueue()
+}
+
+// SetOrdering makes the queue keep its elements sorted by less: the enqueued elements get inserted after the elements
+// not greater than them (stable, O(n)) and Dequeue returns the minimum. The already enqueued elements get sorted
+// (stable) at once. Methods placing elements at a given position (EnqueueAt, Promote, ReplaceWhere, requeued
+// elements, ...) could break the order, the later insertions assume a sorted queue. A nil less restores the FIFO order
+// for the new elements.
+func (st *FIFO) SetOrdering(less func(a, b interface{}) bool) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.less = less
+	if less != nil {
+		sort.Stable(fifoSorter{st})
+		st.headChanged()
+	}
+}
+
+// fifoSorter sorts the FIFO's elements (along with their metadata) by its less function
+type fifoSorter struct {
+	fifo *FIFO
+}
+
+func (st fifoSorter) Len() int {
+	return len(st.fifo.slice)
+}
+
+func (st fifoSorter) Less(i, j int) bool {
+	return st.fifo.less(st.fifo.slice[i], st.fifo.slice[j])
+}
+
+func (st fifoSorter) Swap(i, j int) {
+	st.fifo.slice[i], st.fifo.slice[j] = st.fifo.slice[j], st.fifo.slice[i]
+	if st.fifo.meta != nil {
+		st.fifo.meta[i], st.fifo.meta[j] = st.fifo.meta[j], st.fifo.meta[i]
+	}
+}
+
+// signalEnqueue wakes up the consumers waiting for new elements. It must be called holding st.rwmutex.
+func (st *FIFO) signalEnqueue() {
+	if st.enqueueSignalChan != nil {
+		close(st.enqueueSignalChan)
+		st.enqueueSignalChan = nil
+	}
+}
+
+// enqueueSignal returns a channel to be closed once a new element gets enqueued. It must be called holding
+// st.rwmutex.
+func (st *FIFO) enqueueSignal() <-chan struct{} {
+	if st.enqueueSignalChan == nil {
+		st.enqueueSignalChan = make(chan struct{})
+	}
+
+	return st.enqueueSignalChan
+}
+
+// EnqueueDebounced enqueues an element only if no other element having the same key was enqueued (by
+// EnqueueDebounced) within the last window. It returns true whether the element was enqueued.
+func (st *FIFO) EnqueueDebounced(value interface{}, key string, window time.Duration) (bool, error) {
+	if st.isLocked {
+		return false, st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return false, err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	now := st.now()
+	if st.debounceLastSeen == nil {
+		st.debounceLastSeen = make(map[string]time.Time)
+		st.debounceLastCleanup = now
+	}
+	st.cleanupDebounced(now, window)
+
+	if lastSeen, ok := st.debounceLastSeen[key]; ok && now.Sub(lastSeen) < window {
+		st.countRejection(rejectionDuplicate)
+		return false, nil
+	}
+
+	if _, err := st.enqueue(value); err != nil {
+		return false, err
+	}
+	st.debounceLastSeen[key] = now
+
+	return true, nil
+}
+
+// cleanupDebounced periodically removes the keys not seen within the largest window used so far.
+// It must be called holding st.rwmutex.
+func (st *FIFO) cleanupDebounced(now time.Time, window time.Duration) {
+	if window > st.debounceMaxWindow {
+		st.debounceMaxWindow = window
+	}
+
+	if now.Sub(st.debounceLastCleanup) < st.debounceMaxWindow {
+		return
+	}
+
+	for key, lastSeen := range st.debounceLastSeen {
+		if now.Sub(lastSeen) >= st.debounceMaxWindow {
+			delete(st.debounceLastSeen, key)
+		}
+	}
+	st.debounceLastCleanup = now
+}
+
 // Dequeue dequeues an element
 func (st *FIFO) Dequeue() (interface{}, error) {
+	defer trackOpLatency(&st.dequeueLatency, st.opLatencyStart())
+
 	if st.isLocked {
 		return nil, errors.New("The queue is locked")
 	}
 
+	st.rwmutex.Lock()
+	if err := st.checkSequence(); err != nil {
+		st.rwmutex.Unlock()
+		return nil, err
+	}
+	elementToReturn, _, err := st.dequeueNext()
+	if err == nil {
+		st.advanceSequence(elementToReturn)
+	}
+	transform := st.dequeueTransform
+	emptyValue, emptyValueSet := st.emptyValue, st.emptyValueSet
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	if err != nil {
+		if emptyValueSet {
+			return emptyValue, nil
+		}
+		return nil, err
+	}
+
+	return applyDequeueTransform(transform, elementToReturn), nil
+}
+
+// DequeueOrDefault dequeues an element, returning def instead if no element could be dequeued (empty or locked queue,
+// sequence gap, see SetExpectedSequence). It neither blocks nor allocates if the queue is empty.
+func (st *FIFO) DequeueOrDefault(def interface{}) interface{} {
+	if st.isLocked {
+		return def
+	}
+
+	st.rwmutex.Lock()
+	if len(st.slice) == 0 || st.checkSequence() != nil {
+		st.rwmutex.Unlock()
+		return def
+	}
+	elementToReturn, _, _ := st.dequeueNext()
+	st.advanceSequence(elementToReturn)
+	transform := st.dequeueTransform
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	return applyDequeueTransform(transform, elementToReturn)
+}
+
+// SetEmptyValue makes Dequeue return (value, nil) instead of an error if the queue is empty. value could be nil. The
+// rest of the dequeue methods are not affected. See ClearEmptyValue.
+func (st *FIFO) SetEmptyValue(value interface{}) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.emptyValue = value
+	st.emptyValueSet = true
+}
+
+// ClearEmptyValue restores the default behavior: Dequeue returns an error if the queue is empty, see SetEmptyValue
+func (st *FIFO) ClearEmptyValue() {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.emptyValue = nil
+	st.emptyValueSet = false
+}
+
+// DequeueOrWaitForNextElement dequeues an element, waiting for a new element if the queue is empty
+func (st *FIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	for {
+		if st.isLocked {
+			return nil, errors.New("The queue is locked")
+		}
+
+		st.rwmutex.Lock()
+		if len(st.slice) > 0 {
+			elementToReturn, _, _ := st.dequeueNext()
+			transform := st.dequeueTransform
+			st.rwmutex.Unlock()
+			st.runPendingHooks()
+
+			return applyDequeueTransform(transform, elementToReturn), nil
+		}
+		signal := st.enqueueSignal()
+		st.rwmutex.Unlock()
+
+		<-signal
+	}
+}
+
+// DequeueOrEnqueue dequeues an element if the queue is not empty (didDequeue == true), otherwise it enqueues fallback
+// and returns it (didDequeue == false). Both happen under a single lock, so no other operation could take place in
+// between. The fallback is enqueued as it is: the enqueue transform is not applied to it.
+func (st *FIFO) DequeueOrEnqueue(fallback interface{}) (dequeued interface{}, didDequeue bool, err error) {
+	if st.isLocked {
+		return nil, false, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	if len(st.slice) == 0 {
+		_, err := st.enqueue(fallback)
+		st.rwmutex.Unlock()
+		st.runPendingHooks()
+
+		if err != nil {
+			return nil, false, err
+		}
+		return fallback, false, nil
+	}
+
+	elementToReturn, _, _ := st.dequeueNext()
+	transform := st.dequeueTransform
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	return applyDequeueTransform(transform, elementToReturn), true, nil
+}
+
+// DequeueWithMeta dequeues an element, returning extra information about it
+func (st *FIFO) DequeueWithMeta() (interface{}, DequeueMeta, error) {
+	if st.isLocked {
+		return nil, DequeueMeta{}, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	elementToReturn, meta, err := st.dequeueNext()
+	transform := st.dequeueTransform
+	dequeueMeta := DequeueMeta{
+		Len: len(st.slice),
+	}
+	now := st.now()
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	if err != nil {
+		return nil, DequeueMeta{}, err
+	}
+
+	if !meta.enqueuedAt.IsZero() {
+		dequeueMeta.TimeInQueue = now.Sub(meta.enqueuedAt)
+	}
+
+	return applyDequeueTransform(transform, elementToReturn), dequeueMeta, nil
+}
+
+// dequeueNext removes the next element to be dequeued: the first one, or a random one if dequeue shuffle is enabled.
+// It must be called holding st.rwmutex.
+func (st *FIFO) dequeueNext() (interface{}, elementMeta, error) {
+	return st.dequeueIndex(st.nextIndex())
+}
+
+// nextIndex returns the index of the next element to be dequeued (see dequeueNext), 0 if the queue is empty. It must be
+// called holding st.rwmutex.
+func (st *FIFO) nextIndex() int {
+	if st.shuffleRand == nil || len(st.slice) < 2 {
+		return 0
+	}
+
+	return st.shuffleRand.Intn(len(st.slice))
+}
+
+// dequeueIndex removes the element at index as a dequeued one, see nextIndex. It must be called holding st.rwmutex.
+func (st *FIFO) dequeueIndex(index int) (interface{}, elementMeta, error) {
+	if index == 0 {
+		return st.dequeueHead()
+	}
+
+	var meta elementMeta
+	if st.meta != nil {
+		meta = st.meta[index]
+	}
+	elementToReturn := st.removeAt(index)
+	st.dequeued(elementToReturn)
+
+	return elementToReturn, meta, nil
+}
+
+// SetDequeueShuffle makes Dequeue (and DequeueWithMeta) return a pseudo-randomly chosen element instead of the first
+// one. The random generator uses the given seed, so the dequeue order is reproducible. It is meant for testing
+// consumers against out of order processing.
+func (st *FIFO) SetDequeueShuffle(seed int64) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.shuffleRand = rand.New(rand.NewSource(seed))
+}
+
+// DisableDequeueShuffle restores the strict FIFO order (default behavior), see SetDequeueShuffle
+func (st *FIFO) DisableDequeueShuffle() {
 	st.rwmutex.Lock()
 	defer st.rwmutex.Unlock()
 
+	st.shuffleRand = nil
+}
+
+// dequeueHead removes the first element. It must be called holding st.rwmutex.
+func (st *FIFO) dequeueHead() (interface{}, elementMeta, error) {
 	len := len(st.slice)
 	if len == 0 {
-		return nil, fmt.Errorf("queue is empty")
+		return nil, elementMeta{}, fmt.Errorf("queue is empty")
 	}
 
 	elementToReturn := st.slice[0]
 	st.slice = st.slice[1:]
+	st.trackKey(elementToReturn, -1)
+	st.headChanged()
+
+	var meta elementMeta
+	if st.meta != nil {
+		meta = st.meta[0]
+		st.meta = st.meta[1:]
+		meta.release()
+	}
+	st.dequeued(elementToReturn)
+
+	return elementToReturn, meta, nil
+}
+
+// Peek returns the first element's value and keeps the element at the queue
+func (st *FIFO) Peek() (interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if len(st.slice) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
 
-	return elementToReturn, nil
+	return st.slice[0], nil
 }
 
 // Get returns an element's value and keeps the element at the queue
@@ -76,6 +758,23 @@ func (st *FIFO) Get(index int) (interface{}, error) {
 	return st.slice[index], nil
 }
 
+// GetFromTail returns the value of the element n positions from the tail (0 == the most recently enqueued) and keeps
+// the element at the queue
+func (st *FIFO) GetFromTail(n int) (interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if n < 0 || len(st.slice) <= n {
+		return nil, fmt.Errorf("index out of bounds: %v", n)
+	}
+
+	return st.slice[len(st.slice)-1-n], nil
+}
+
 // Remove removes an element from the queue
 func (st *FIFO) Remove(index int) error {
 	if st.isLocked {
@@ -90,11 +789,1256 @@ func (st *FIFO) Remove(index int) error {
 	}
 
 	// remove the element
-	st.slice = append(st.slice[:index], st.slice[index+1:]...)
+	st.removeAt(index)
 
 	return nil
 }
 
+// RemoveValue removes the first element (starting from the head) equal to value (see Equaler), keeping the order of
+// the rest. It returns true whether an element was removed (false if the queue is locked). It takes O(n).
+func (st *FIFO) RemoveValue(value interface{}) bool {
+	if st.isLocked {
+		return false
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	index := st.indexOf(value)
+	if index == -1 {
+		return false
+	}
+	st.removeAt(index)
+
+	return true
+}
+
+// Contains returns true whether the queue has an element equal to value (see Equaler)
+func (st *FIFO) Contains(value interface{}) bool {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.indexOf(value) != -1
+}
+
+// TypeHistogram returns the number of enqueued elements by their Go type name (e.g. "int", "*main.Job", "<nil>"),
+// counted at once. The queue is kept untouched.
+func (st *FIFO) TypeHistogram() map[string]int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	ret := make(map[string]int)
+	for _, value := range st.slice {
+		ret[fmt.Sprintf("%T", value)]++
+	}
+
+	return ret
+}
+
+// EnqueueUnique enqueues an element only if the queue has no other element equal to it (see Equaler). It returns
+// true whether the element was enqueued.
+func (st *FIFO) EnqueueUnique(value interface{}) (bool, error) {
+	if st.isLocked {
+		return false, st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return false, err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.indexOf(value) != -1 {
+		st.countRejection(rejectionDuplicate)
+		return false, nil
+	}
+	if _, err := st.enqueue(value); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// EnqueueIfTailDiffers enqueues an element only if the queue is empty or its tail (the last element, see GetFromTail)
+// is not equal to it (see Equaler), suppressing runs of equal elements. It returns true whether the element was
+// enqueued. It takes O(1).
+func (st *FIFO) EnqueueIfTailDiffers(value interface{}) (bool, error) {
+	if st.isLocked {
+		return false, st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return false, err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if len(st.slice) > 0 && equal(st.slice[len(st.slice)-1], value) {
+		st.countRejection(rejectionDuplicate)
+		return false, nil
+	}
+	if _, err := st.enqueue(value); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// indexOf returns the index of the first element equal to value, -1 if there is no such element.
+// It must be called holding st.rwmutex.
+func (st *FIFO) indexOf(value interface{}) int {
+	for i, element := range st.slice {
+		if equal(element, value) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// DequeueWhere dequeues the first element (starting from the head) satisfying the given predicate.
+// The order of the remaining elements is preserved.
+func (st *FIFO) DequeueWhere(pred func(interface{}) bool) (interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	for i := 0; i < len(st.slice); i++ {
+		if pred(st.slice[i]) {
+			elementToReturn := st.removeAt(i)
+			st.dequeued(elementToReturn)
+			transform := st.dequeueTransform
+			st.rwmutex.Unlock()
+			st.runPendingHooks()
+
+			return applyDequeueTransform(transform, elementToReturn), nil
+		}
+	}
+	st.rwmutex.Unlock()
+
+	return nil, fmt.Errorf("no element matches the predicate")
+}
+
+// PeekOrWaitForNextElement returns the first element (keeping it at the queue), waiting for a new element if the queue
+// is empty. An error will be returned if ctx gets done before.
+func (st *FIFO) PeekOrWaitForNextElement(ctx context.Context) (interface{}, error) {
+	for {
+		if st.isLocked {
+			return nil, errors.New("The queue is locked")
+		}
+
+		st.rwmutex.Lock()
+		if len(st.slice) > 0 {
+			head := st.slice[0]
+			st.rwmutex.Unlock()
+
+			return head, nil
+		}
+		signal := st.enqueueSignal()
+		st.rwmutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// WaitUntilQuiescent waits until no element gets enqueued or dequeued for quietFor (real time), returning an error if
+// ctx gets done before. The queue is checked every quietFor, so it could take up to 2 * quietFor since the latest
+// operation to return.
+func (st *FIFO) WaitUntilQuiescent(ctx context.Context, quietFor time.Duration) error {
+	if quietFor <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(quietFor)
+	defer timer.Stop()
+
+	st.rwmutex.RLock()
+	last := st.operations
+	st.rwmutex.RUnlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		st.rwmutex.RLock()
+		current := st.operations
+		st.rwmutex.RUnlock()
+		if current == last {
+			return nil
+		}
+		last = current
+		timer.Reset(quietFor)
+	}
+}
+
+// PeekOrWaitForNextElementTimeout returns the first element (keeping it at the queue), waiting up to timeout for a new
+// element if the queue is empty. An error will be returned if no element gets enqueued before the timeout.
+func (st *FIFO) PeekOrWaitForNextElementTimeout(timeout time.Duration) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	value, err := st.PeekOrWaitForNextElement(ctx)
+	if err == context.DeadlineExceeded {
+		return nil, fmt.Errorf("timeout waiting for the next element after %v", timeout)
+	}
+
+	return value, err
+}
+
+// WaitForValue dequeues the first element satisfying match, waiting for new elements if there is no such element.
+// The elements not satisfying match are either kept at the queue (keepUnmatched == true) or dequeued and dropped
+// (keepUnmatched == false, reason: DeadLetterReasonUnmatched) while looking for the matching one. An error will be
+// returned if ctx gets done before.
+func (st *FIFO) WaitForValue(ctx context.Context, match func(interface{}) bool, keepUnmatched bool) (interface{}, error) {
+	value, transform, err := st.waitForValue(ctx, match, keepUnmatched)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyDequeueTransform(transform, value), nil
+}
+
+// waitForValue is WaitForValue but it returns the element as it was stored, plus the dequeue transform to be applied.
+func (st *FIFO) waitForValue(ctx context.Context, match func(interface{}) bool, keepUnmatched bool) (interface{}, func(interface{}) interface{}, error) {
+	for {
+		if st.isLocked {
+			return nil, nil, errors.New("The queue is locked")
+		}
+
+		var dropped []interface{}
+		st.rwmutex.Lock()
+		for i := 0; i < len(st.slice); {
+			if match(st.slice[i]) {
+				elementToReturn := st.removeAt(i)
+				st.dequeued(elementToReturn)
+				transform := st.dequeueTransform
+				st.rwmutex.Unlock()
+				st.runPendingHooks()
+				st.discard(DeadLetterReasonUnmatched, dropped...)
+
+				return elementToReturn, transform, nil
+			}
+
+			if keepUnmatched {
+				i++
+			} else {
+				value, _, _ := st.dequeueHead()
+				dropped = append(dropped, value)
+			}
+		}
+		signal := st.enqueueSignal()
+		st.rwmutex.Unlock()
+		st.runPendingHooks()
+		st.discard(DeadLetterReasonUnmatched, dropped...)
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// DequeueSince dequeues all the elements enqueued at or after t (keeping their order), the older ones are kept at the
+// queue. It requires timestamp tracking (see SetTimestampTracking), elements enqueued while it was disabled are kept.
+func (st *FIFO) DequeueSince(t time.Time) ([]interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	if !st.timestampTracking {
+		st.rwmutex.Unlock()
+		return nil, fmt.Errorf("timestamp tracking is disabled")
+	}
+	// the elements without metadata (e.g. swapped from an untracked queue) have an unknown enqueue time
+	st.ensureMeta()
+
+	var (
+		ret       = make([]interface{}, 0)
+		kept      = make([]interface{}, 0, len(st.slice))
+		keptMeta  = make([]elementMeta, 0, len(st.meta))
+		transform = st.dequeueTransform
+	)
+	for i, value := range st.slice {
+		meta := st.meta[i]
+		if meta.enqueuedAt.IsZero() || meta.enqueuedAt.Before(t) {
+			kept = append(kept, value)
+			keptMeta = append(keptMeta, meta)
+			continue
+		}
+
+		ret = append(ret, value)
+		st.trackKey(value, -1)
+		meta.release()
+		st.dequeued(value)
+	}
+	st.slice = kept
+	st.meta = keptMeta
+	st.headChanged()
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	for i, value := range ret {
+		ret[i] = applyDequeueTransform(transform, value)
+	}
+
+	return ret, nil
+}
+
+// DrainInto dequeues up to len(dst) elements from the head (at once, under the queue's lock) into dst, returning the
+// number of dequeued elements (0 if the queue is empty). dst could be reused across calls: no slice gets allocated.
+func (st *FIFO) DrainInto(dst []interface{}) (int, error) {
+	if st.isLocked {
+		return 0, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	total := len(dst)
+	if total > len(st.slice) {
+		total = len(st.slice)
+	}
+	for i := 0; i < total; i++ {
+		dst[i], _, _ = st.dequeueHead()
+	}
+	transform := st.dequeueTransform
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	if transform != nil {
+		for i := 0; i < total; i++ {
+			dst[i] = applyDequeueTransform(transform, dst[i])
+		}
+	}
+
+	return total, nil
+}
+
+// DrainTail removes and returns up to the last n enqueued elements (the most recent ones), in enqueue order.
+// The older elements are kept at the queue.
+func (st *FIFO) DrainTail(n int) ([]interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	if n < 0 {
+		return nil, fmt.Errorf("invalid number of elements: %v", n)
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if n > len(st.slice) {
+		n = len(st.slice)
+	}
+
+	start := len(st.slice) - n
+	ret := make([]interface{}, n)
+	copy(ret, st.slice[start:])
+	st.slice = st.slice[:start]
+	if start == 0 {
+		st.headChanged()
+	}
+	for _, value := range ret {
+		st.trackKey(value, -1)
+	}
+	if st.meta != nil {
+		for _, meta := range st.meta[start:] {
+			meta.release()
+		}
+		st.meta = st.meta[:start]
+	}
+
+	return ret, nil
+}
+
+// Clear removes all the enqueued elements. The elements and the length are updated at once (under the queue's lock),
+// so GetLen never observes a partially cleared queue.
+func (st *FIFO) Clear() error {
+	if st.isLocked {
+		return errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for _, meta := range st.meta {
+		meta.release()
+	}
+	st.slice = make([]interface{}, 0)
+	if st.meta != nil {
+		st.meta = make([]elementMeta, 0)
+	}
+	st.recountKeys()
+	st.headChanged()
+
+	return nil
+}
+
+// TakeAndClear removes and returns all the enqueued elements (from the head to the tail, as they were stored), at once
+// under the queue's lock: every element is either returned or kept for the next call, e.g. to aggregate windows. The
+// elements are removed the same way as Clear does.
+func (st *FIFO) TakeAndClear() ([]interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	// no other reference to the slice is kept
+	ret := st.slice
+	for _, meta := range st.meta {
+		meta.release()
+	}
+	st.slice = make([]interface{}, 0)
+	if st.meta != nil {
+		st.meta = make([]elementMeta, 0)
+	}
+	st.recountKeys()
+	st.headChanged()
+
+	return ret, nil
+}
+
+// Compact merges adjacent elements: whenever merge(a, b) returns ok == true for two adjacent elements (a being the
+// closest to the head), both are replaced by the merged element. It repeats until no more merges could be done.
+func (st *FIFO) Compact(merge func(a, b interface{}) (interface{}, bool)) error {
+	if st.isLocked {
+		return errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	var (
+		compacted     = make([]interface{}, 0, len(st.slice))
+		compactedMeta []elementMeta
+	)
+	if st.meta != nil {
+		compactedMeta = make([]elementMeta, 0, len(st.meta))
+	}
+
+	for i, value := range st.slice {
+		compacted = append(compacted, value)
+		if st.meta != nil {
+			compactedMeta = append(compactedMeta, st.meta[i])
+		}
+
+		// a merged element could be merged with the previous one
+		for len(compacted) > 1 {
+			merged, ok := merge(compacted[len(compacted)-2], compacted[len(compacted)-1])
+			if !ok {
+				break
+			}
+			// the merged element keeps the metadata of the older one
+			compacted = compacted[:len(compacted)-1]
+			compacted[len(compacted)-1] = merged
+			if compactedMeta != nil {
+				compactedMeta[len(compactedMeta)-1].release()
+				compactedMeta = compactedMeta[:len(compactedMeta)-1]
+			}
+		}
+	}
+	st.slice = compacted
+	st.meta = compactedMeta
+	st.recountKeys()
+	st.headChanged()
+
+	return nil
+}
+
+// Split moves all the elements into n new queues, distributing them round-robin: the element at index i goes to the
+// queue i % n (keeping their relative order). The source queue gets empty; elements enqueued in the meantime stay at it.
+func (st *FIFO) Split(n int) ([]*FIFO, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of queues: %v", n)
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	queues := make([]*FIFO, n)
+	for i := range queues {
+		queues[i] = NewFIFO()
+		if st.meta != nil {
+			queues[i].meta = make([]elementMeta, 0)
+		}
+	}
+
+	for i, value := range st.slice {
+		queue := queues[i%n]
+		queue.slice = append(queue.slice, value)
+		if st.meta != nil {
+			queue.meta = append(queue.meta, st.meta[i])
+		}
+	}
+
+	st.slice = make([]interface{}, 0)
+	if st.meta != nil {
+		st.meta = make([]elementMeta, 0)
+	}
+	st.recountKeys()
+	st.headChanged()
+
+	return queues, nil
+}
+
+// Swap atomically exchanges the elements of both queues
+func Swap(a, b *FIFO) error {
+	if a.isLocked || b.isLocked {
+		return errors.New("The queue is locked")
+	}
+
+	unlock := lockFIFOPair(a, b)
+	defer unlock()
+
+	a.slice, b.slice = b.slice, a.slice
+	a.meta, b.meta = b.meta, a.meta
+	// the elements coming from an untracked queue have an unknown enqueue time
+	if a.timestampTracking {
+		a.ensureMeta()
+	}
+	if b.timestampTracking {
+		b.ensureMeta()
+	}
+	a.recountKeys()
+	b.recountKeys()
+	a.headChanged()
+	b.headChanged()
+	a.signalEnqueue()
+	b.signalEnqueue()
+
+	return nil
+}
+
+// TransferOne dequeues the first element and enqueues it into dst at once (holding both queues' locks), so no other
+// operation could ever see it at both queues or at none of them. The element is moved as it was stored: neither this
+// queue's dequeue transform nor dst's enqueue transform get applied, but dst's ordering (see SetOrdering) and key
+// quota are honored. The element stays at this queue if dst rejects it. It returns the transferred element.
+func (st *FIFO) TransferOne(dst *FIFO) (interface{}, error) {
+	if st == dst {
+		return nil, errors.New("the destination queue must be a different queue")
+	}
+
+	if st.isLocked || dst.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	unlock := lockFIFOPair(st, dst)
+	if len(st.slice) == 0 {
+		unlock()
+		return nil, fmt.Errorf("queue is empty")
+	}
+	if err := dst.checkKeyQuota(st.slice[0]); err != nil {
+		unlock()
+		return nil, err
+	}
+	value, _, _ := st.dequeueHead()
+	// it could not fail: the key quota was already checked
+	dst.enqueue(value)
+	unlock()
+	st.runPendingHooks()
+	dst.runPendingHooks()
+
+	return value, nil
+}
+
+// MergeSortedDequeue dequeues the lowest (according to less) of the queues' first elements: given queues whose elements
+// are sorted, successive calls return all their elements sorted (k-way merge). The first queue (in the given order)
+// wins the ties. All the queues are locked at once, so the dequeued element is the lowest head at that moment. The
+// element is returned as Dequeue does (see SetDequeueTransform), but less compares the elements as they were enqueued.
+// An error is returned if any of the queues is locked or all of them are empty.
+func MergeSortedDequeue(less func(a, b interface{}) bool, queues ...*FIFO) (interface{}, error) {
+	for _, queue := range queues {
+		if queue.isLocked {
+			return nil, errors.New("The queue is locked")
+		}
+	}
+
+	unlock := lockFIFOs(queues)
+	var lowest *FIFO
+	for _, queue := range queues {
+		if len(queue.slice) > 0 && (lowest == nil || less(queue.slice[0], lowest.slice[0])) {
+			lowest = queue
+		}
+	}
+	if lowest == nil {
+		unlock()
+		return nil, fmt.Errorf("all the queues are empty")
+	}
+	value, _, _ := lowest.dequeueHead()
+	transform := lowest.dequeueTransform
+	unlock()
+	lowest.runPendingHooks()
+
+	return applyDequeueTransform(transform, value), nil
+}
+
+// MapTo drains all the elements, applies transform to each of them and enqueues the results into dst (keeping their
+// order), returning the number of enqueued elements. Both queues get locked (following a consistent order) to drain
+// the elements and to enqueue the results, while transform and dst's enqueue transform run outside the locks. No
+// element gets lost: the elements whose results are rejected by dst (e.g. by its enqueue transform or key quota) are
+// put back at this queue's head, as they were, and they are reported by a *BatchError. All of them are put back if dst
+// gets locked in the meantime.
+func (st *FIFO) MapTo(dst *FIFO, transform func(interface{}) interface{}) (int, error) {
+	if st == dst {
+		return 0, errors.New("source and destination must be different queues")
+	}
+
+	unlock := lockFIFOPair(st, dst)
+	if st.IsLocked() || dst.IsLocked() {
+		unlock()
+		return 0, errors.New("The queue is locked")
+	}
+	values := st.slice
+	for _, meta := range st.meta {
+		meta.release()
+	}
+	st.slice = make([]interface{}, 0)
+	if st.meta != nil {
+		st.meta = make([]elementMeta, 0)
+	}
+	st.recountKeys()
+	st.headChanged()
+	for _, value := range values {
+		st.dequeued(value)
+	}
+	unlock()
+	st.runPendingHooks()
+
+	var (
+		batchError = newBatchError()
+		results    = make([]interface{}, len(values))
+	)
+	for i, value := range values {
+		var err error
+		if results[i], err = dst.transformEnqueued(transform(value)); err != nil {
+			batchError.add(i, err)
+		}
+	}
+
+	unlock = lockFIFOPair(st, dst)
+	rejected := make([]interface{}, 0)
+	dstLocked := dst.IsLocked()
+	for i, result := range results {
+		if _, failed := batchError.errors[i]; !failed && !dstLocked {
+			_, err := dst.enqueue(result)
+			if err == nil {
+				continue
+			}
+			batchError.add(i, err)
+		}
+		rejected = append(rejected, values[i])
+	}
+	st.insertFront(rejected)
+	unlock()
+	st.runPendingHooks()
+	dst.runPendingHooks()
+
+	if dstLocked {
+		return 0, errors.New("The queue is locked")
+	}
+	if batchError.len() > 0 {
+		return len(values) - batchError.len(), batchError
+	}
+
+	return len(values), nil
+}
+
+// Equal returns true whether both queues have equal elements (see Equaler) in the same order. Both queues are compared
+// at once (holding their locks), none of them gets modified.
+func (st *FIFO) Equal(other *FIFO) bool {
+	if st == other {
+		return true
+	}
+
+	unlock := lockFIFOPair(st, other)
+	defer unlock()
+
+	if len(st.slice) != len(other.slice) {
+		return false
+	}
+
+	for i, value := range st.slice {
+		if !equal(value, other.slice[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lockFIFOPair locks both queues' rwmutex following a consistent order (to avoid deadlocks) and returns the function to
+// unlock them.
+func lockFIFOPair(a, b *FIFO) func() {
+	if a == b {
+		a.rwmutex.Lock()
+		return a.rwmutex.Unlock
+	}
+
+	first, second := a, b
+	if uintptr(unsafe.Pointer(second)) < uintptr(unsafe.Pointer(first)) {
+		first, second = second, first
+	}
+
+	first.rwmutex.Lock()
+	second.rwmutex.Lock()
+
+	return func() {
+		second.rwmutex.Unlock()
+		first.rwmutex.Unlock()
+	}
+}
+
+// fifosByAddress sorts the queues by address, see lockFIFOs
+type fifosByAddress []*FIFO
+
+func (st fifosByAddress) Len() int { return len(st) }
+func (st fifosByAddress) Less(i, j int) bool {
+	return uintptr(unsafe.Pointer(st[i])) < uintptr(unsafe.Pointer(st[j]))
+}
+func (st fifosByAddress) Swap(i, j int) { st[i], st[j] = st[j], st[i] }
+
+// lockFIFOs locks all the queues' rwmutex (once per queue, even if it is repeated) following the same order as
+// lockFIFOPair, and returns the function to unlock them.
+func lockFIFOs(queues []*FIFO) func() {
+	sorted := append([]*FIFO(nil), queues...)
+	sort.Sort(fifosByAddress(sorted))
+
+	locked := sorted[:0]
+	for _, queue := range sorted {
+		if len(locked) > 0 && locked[len(locked)-1] == queue {
+			continue
+		}
+		queue.rwmutex.Lock()
+		locked = append(locked, queue)
+	}
+
+	return func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			locked[i].rwmutex.Unlock()
+		}
+	}
+}
+
+// SetDequeueTransform sets a function to be applied to every element returned by Dequeue, DequeueWithMeta and
+// DequeueWhere. The transformation runs once the element was removed, outside the queue's lock. A nil transform
+// returns the elements as they were enqueued (or cloned, see SetCloneOnDequeue).
+func (st *FIFO) SetDequeueTransform(transform func(interface{}) interface{}) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.dequeueTransformSet = transform
+	st.updateDequeueTransform()
+}
+
+// applyDequeueTransform applies transform (if any) to value
+func applyDequeueTransform(transform func(interface{}) interface{}, value interface{}) interface{} {
+	if transform == nil {
+		return value
+	}
+
+	return transform(value)
+}
+
+// SetEnqueueTransform sets a function to be applied to every element before being enqueued, the returned value is the
+// one to be stored. A non nil error rejects the element (the dead letter handler gets called using
+// DeadLetterReasonValidation). The transformation runs outside the queue's lock. A nil transform stores the elements
+// as they are.
+func (st *FIFO) SetEnqueueTransform(transform func(interface{}) (interface{}, error)) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.enqueueTransform = transform
+}
+
+// SetMaxElementSize makes the enqueues reject the elements whose size (as returned by sizer, e.g. the length of a
+// []byte) exceeds bytes, using a QueueError (QueueErrorCodeElementTooLarge). The rejected elements are discarded using
+// DeadLetterReasonValidation (see SetDeadLetterHandler). The size is checked outside the queue's lock, before the
+// enqueue clone and the enqueue transform. A nil sizer disables the check (default).
+func (st *FIFO) SetMaxElementSize(bytes int, sizer func(interface{}) int) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.maxElementSize = bytes
+	st.elementSizer = sizer
+}
+
+// transformEnqueued checks value's size and applies the enqueue clone and the enqueue transform (if any) to it. It must
+// be called without holding st.rwmutex.
+func (st *FIFO) transformEnqueued(value interface{}) (interface{}, error) {
+	st.rwmutex.RLock()
+	clone := st.cloneOnEnqueue
+	transform := st.enqueueTransform
+	maxSize, sizer := st.maxElementSize, st.elementSizer
+	st.rwmutex.RUnlock()
+
+	if sizer != nil {
+		if size := sizer(value); size > maxSize {
+			st.countRejection(rejectionTooLarge)
+			st.discard(DeadLetterReasonValidation, value)
+			return nil, NewQueueError(QueueErrorCodeElementTooLarge,
+				fmt.Sprintf("element too large: %v bytes (max: %v)", size, maxSize))
+		}
+	}
+	if clone != nil {
+		value = clone(value)
+	}
+	if transform == nil {
+		return value, nil
+	}
+
+	transformed, err := transform(value)
+	if err != nil {
+		st.countRejection(rejectionValidation)
+		st.discard(DeadLetterReasonValidation, value)
+		return nil, err
+	}
+
+	return transformed, nil
+}
+
+// SetDeadLetterHandler sets a function to be called every time an element gets discarded (the reason being one of the
+// DeadLetterReason... constants). The handler is executed outside the queue's lock. A nil handler disables it.
+func (st *FIFO) SetDeadLetterHandler(handler func(value interface{}, reason string)) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.deadLetterHandler = handler
+}
+
+// discard executes the dead letter handler (if any) for the given values.
+// It must be called without holding st.rwmutex.
+func (st *FIFO) discard(reason string, values ...interface{}) {
+	st.rwmutex.RLock()
+	handler := st.deadLetterHandler
+	st.rwmutex.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	for _, value := range values {
+		handler(value, reason)
+	}
+}
+
+// SetTimestampTracking enables/disables tracking the time each element gets enqueued.
+// Elements already enqueued when the tracking gets enabled are timestamped at that moment.
+func (st *FIFO) SetTimestampTracking(enabled bool) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.timestampTracking = enabled
+	if !enabled {
+		// the metadata is still needed by the elements enqueued using EnqueueWithDone, EnqueueOrUpdate or EnqueueCtx
+		needed := false
+		for i := range st.meta {
+			st.meta[i].enqueuedAt = time.Time{}
+			needed = needed || st.meta[i].done != nil || st.meta[i].keyed || st.meta[i].ctx != nil
+		}
+		if !needed {
+			st.meta = nil
+		}
+		return
+	}
+
+	st.ensureMeta()
+	now := st.now()
+	for i := range st.meta {
+		if st.meta[i].enqueuedAt.IsZero() {
+			st.meta[i].enqueuedAt = now
+		}
+	}
+}
+
+// EnqueueWithDone enqueues an element, returning a channel to be closed once this element leaves the queue. It lets
+// the producer know when the element was picked up (dequeued), not when it was processed.
+// The channel gets closed exactly once, also if the element is dropped instead of being dequeued (Remove, DrainTail,
+// merged by Compact, ...): there is no way to tell both cases apart from the channel. Moving the element to another
+// queue (Split, Swap) keeps its channel open.
+func (st *FIFO) EnqueueWithDone(value interface{}) (<-chan struct{}, error) {
+	if st.isLocked {
+		return nil, st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return nil, err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.ensureMeta()
+	index, err := st.enqueue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	st.meta[index].done = done
+
+	return done, nil
+}
+
+// SetClock sets the time provider for the time based features (timestamps, debounce windows, ...). A nil clock
+// restores the real time (default).
+func (st *FIFO) SetClock(clock Clock) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.clock = clock
+}
+
+// now returns the current time. It must be called holding st.rwmutex.
+func (st *FIFO) now() time.Time {
+	if st.clock == nil {
+		return time.Now()
+	}
+
+	return st.clock.Now()
+}
+
+// CancelFunc removes an element enqueued by FIFO.EnqueueCancelable, returning true whether it was still at the queue
+type CancelFunc func() bool
+
+// EnqueueCancelable enqueues an element, returning a function to remove it from the queue if it was not dequeued yet.
+// Cancel and dequeue exclude each other: exactly one of them gets the element, cancel returns false if the element
+// was already dequeued (or removed). A canceled element is handed to the dead letter handler (reason:
+// DeadLetterReasonCanceled, see SetDeadLetterHandler). It takes O(n).
+func (st *FIFO) EnqueueCancelable(value interface{}) (CancelFunc, error) {
+	done, err := st.EnqueueWithDone(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() bool {
+		st.rwmutex.Lock()
+		// the done channel identifies the element
+		for i := range st.meta {
+			if st.meta[i].done == done {
+				removed := st.removeAt(i)
+				st.rwmutex.Unlock()
+				st.discard(DeadLetterReasonCanceled, removed)
+				return true
+			}
+		}
+		st.rwmutex.Unlock()
+
+		return false
+	}, nil
+}
+
+// ensureMeta starts tracking the per-element metadata (if it was not tracked yet). It must be called holding
+// st.rwmutex.
+func (st *FIFO) ensureMeta() {
+	if st.meta == nil {
+		st.meta = make([]elementMeta, len(st.slice))
+	}
+}
+
+// EnqueueOrUpdate coalesces the elements having the same key: if an element enqueued by EnqueueOrUpdate using key is
+// still at the queue, it gets replaced by update(existing, delta); otherwise update(create(), delta) is enqueued.
+//
+// An updated element keeps its position (and enqueue time), so the updates don't move it back. create and update run
+// while holding the queue's lock, so they must not call the queue. The enqueue transform is not applied. It takes O(n).
+func (st *FIFO) EnqueueOrUpdate(key string, delta int, create func() interface{}, update func(existing interface{}, delta int) interface{}) error {
+	if st.isLocked {
+		return st.rejectLocked()
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.ensureMeta()
+	for i := range st.meta {
+		if st.meta[i].keyed && st.meta[i].key == key {
+			st.trackKey(st.slice[i], -1)
+			st.slice[i] = update(st.slice[i], delta)
+			st.trackKey(st.slice[i], 1)
+			if i == 0 {
+				st.headChanged()
+			}
+
+			return nil
+		}
+	}
+
+	index, err := st.enqueue(update(create(), delta))
+	if err != nil {
+		return err
+	}
+	st.meta[index].key = key
+	st.meta[index].keyed = true
+
+	return nil
+}
+
+// newElementMeta returns the metadata for an element being enqueued. It must be called holding st.rwmutex.
+func (st *FIFO) newElementMeta() elementMeta {
+	if !st.timestampTracking {
+		return elementMeta{}
+	}
+
+	return elementMeta{
+		enqueuedAt: st.now(),
+	}
+}
+
+// release closes the done channel (if any), it must be called once the element leaves the queue
+func (meta elementMeta) release() {
+	if meta.done != nil {
+		close(meta.done)
+	}
+}
+
+// removeAt removes the element at the given index (plus its metadata), keeping the order of the rest.
+// It must be called holding st.rwmutex.
+func (st *FIFO) removeAt(index int) interface{} {
+	value := st.slice[index]
+	st.slice = append(st.slice[:index], st.slice[index+1:]...)
+	st.trackKey(value, -1)
+	if index == 0 {
+		st.headChanged()
+	}
+	if st.meta != nil {
+		st.meta[index].release()
+		st.meta = append(st.meta[:index], st.meta[index+1:]...)
+	}
+
+	return value
+}
+
+// Promote moves all the elements satisfying pred to the head of the queue (keeping their relative order), returning the
+// number of promoted elements. Nothing is done if the queue is locked.
+func (st *FIFO) Promote(pred func(interface{}) bool) int {
+	if st.isLocked {
+		return 0
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	var (
+		promoted     = make([]interface{}, 0, len(st.slice))
+		rest         = make([]interface{}, 0, len(st.slice))
+		promotedMeta []elementMeta
+		restMeta     []elementMeta
+	)
+	if st.meta != nil {
+		promotedMeta = make([]elementMeta, 0, len(st.meta))
+		restMeta = make([]elementMeta, 0, len(st.meta))
+	}
+
+	for i, value := range st.slice {
+		if pred(value) {
+			promoted = append(promoted, value)
+			if st.meta != nil {
+				promotedMeta = append(promotedMeta, st.meta[i])
+			}
+		} else {
+			rest = append(rest, value)
+			if st.meta != nil {
+				restMeta = append(restMeta, st.meta[i])
+			}
+		}
+	}
+
+	total := len(promoted)
+	st.slice = append(promoted, rest...)
+	if total > 0 {
+		st.headChanged()
+	}
+	if st.meta != nil {
+		st.meta = append(promotedMeta, restMeta...)
+	}
+
+	return total
+}
+
+// ReplaceWhere replaces (in place, keeping the order) every element satisfying pred by replacement(element), returning
+// the number of replaced elements. The elements keep their metadata (enqueue time, done channel, ...) and the whole
+// operation runs under the queue's lock, so pred and replacement must not call the queue's methods. Nothing is done if
+// the queue is locked.
+func (st *FIFO) ReplaceWhere(pred func(interface{}) bool, replacement func(interface{}) interface{}) int {
+	if st.isLocked {
+		return 0
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	total := 0
+	for i, value := range st.slice {
+		if pred(value) {
+			st.slice[i] = replacement(value)
+			total++
+		}
+	}
+	if total > 0 {
+		st.recountKeys()
+		st.headChanged()
+	}
+
+	return total
+}
+
+// Walk visits the elements (from the head to the tail) removing the ones visitor returns WalkRemove for, until visitor
+// returns WalkStop: the rest of the elements are neither visited nor removed. It returns the number of removed elements.
+// The removed elements are not considered dequeued (same as Remove). The whole walk is a single pass under the queue's
+// lock, so visitor must not call the queue's methods. Nothing is done if the queue is locked.
+func (st *FIFO) Walk(visitor func(value interface{}) WalkAction) int {
+	if st.isLocked {
+		return 0
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	var (
+		total = len(st.slice)
+		kept  = 0
+		i     = 0
+	)
+	for ; i < total; i++ {
+		value := st.slice[i]
+		action := visitor(value)
+		if action == WalkRemove {
+			st.trackKey(value, -1)
+			if st.meta != nil {
+				st.meta[i].release()
+			}
+			continue
+		}
+
+		st.slice[kept] = value
+		if st.meta != nil {
+			st.meta[kept] = st.meta[i]
+		}
+		kept++
+		if action == WalkStop {
+			i++
+			break
+		}
+	}
+	// the not visited elements
+	if st.meta != nil {
+		copy(st.meta[kept:], st.meta[i:])
+	}
+	kept += copy(st.slice[kept:], st.slice[i:])
+
+	// no references to the removed elements are kept
+	for j := kept; j < total; j++ {
+		st.slice[j] = nil
+	}
+	st.slice = st.slice[:kept]
+	if kept < total {
+		st.headChanged()
+	}
+	if st.meta != nil {
+		for j := kept; j < total; j++ {
+			st.meta[j] = elementMeta{}
+		}
+		st.meta = st.meta[:kept]
+	}
+
+	return total - kept
+}
+
+// SetElementPool sets the pool to return the processed elements to (see RecycleDequeued). A nil pool disables recycling.
+func (st *FIFO) SetElementPool(pool *sync.Pool) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.elementPool = pool
+}
+
+// SetElementReset sets a function to reset the elements before being returned to the pool (see RecycleDequeued)
+func (st *FIFO) SetElementReset(reset func(interface{})) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.elementReset = reset
+}
+
+// RecycleDequeued resets (see SetElementReset) and returns an already processed element to the pool (see
+// SetElementPool). The element must not be used after this call. Nothing is done if no pool was set.
+func (st *FIFO) RecycleDequeued(value interface{}) {
+	st.rwmutex.RLock()
+	pool, reset := st.elementPool, st.elementReset
+	st.rwmutex.RUnlock()
+
+	if pool == nil || value == nil {
+		return
+	}
+
+	if reset != nil {
+		reset(value)
+	}
+	pool.Put(value)
+}
+
+// SetHistorySize keeps the latest n dequeued elements, see History().
+// The history is disabled if n <= 0 (default behavior).
+func (st *FIFO) SetHistorySize(n int) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if n <= 0 {
+		st.history = nil
+		return
+	}
+
+	newHistory := newRing(n)
+	// keep the latest already recorded elements
+	if st.history != nil {
+		for _, value := range st.history.slice() {
+			newHistory.add(value)
+		}
+	}
+	st.history = newHistory
+}
+
+// History returns the latest dequeued elements, from the oldest to the newest
+func (st *FIFO) History() []interface{} {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if st.history == nil {
+		return []interface{}{}
+	}
+
+	return st.history.slice()
+}
+
+// addToHistory records a dequeued element. It must be called holding st.rwmutex.
+func (st *FIFO) addToHistory(value interface{}) {
+	if st.history != nil {
+		st.history.add(value)
+	}
+}
+
 // GetLen returns the number of enqueued elements
 func (st *FIFO) GetLen() int {
 	st.rwmutex.RLock()
@@ -0,0 +1,39 @@
+package goconcurrentqueue
+
+// SetCloneOnEnqueue sets a function to copy (deep) every element before being stored, so the producers could reuse
+// (modify) the enqueued values without affecting the queue. The copy gets made outside the queue's lock, before the
+// enqueue transform (see SetEnqueueTransform). A nil clone stores the elements as they are (default).
+func (st *FIFO) SetCloneOnEnqueue(clone func(interface{}) interface{}) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.cloneOnEnqueue = clone
+}
+
+// SetCloneOnDequeue sets a function to copy (deep) every element returned by the dequeues, so the consumers could
+// modify the dequeued values without affecting anything else referencing them (e.g. an element enqueued twice). The
+// copy gets made outside the queue's lock, before the dequeue transform (see SetDequeueTransform). A nil clone returns
+// the elements as they were stored (default).
+func (st *FIFO) SetCloneOnDequeue(clone func(interface{}) interface{}) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.cloneOnDequeue = clone
+	st.updateDequeueTransform()
+}
+
+// updateDequeueTransform sets the function applied to every dequeued element: the dequeue clone followed by the
+// dequeue transform. It must be called holding st.rwmutex.
+func (st *FIFO) updateDequeueTransform() {
+	clone, transform := st.cloneOnDequeue, st.dequeueTransformSet
+	switch {
+	case clone == nil:
+		st.dequeueTransform = transform
+	case transform == nil:
+		st.dequeueTransform = clone
+	default:
+		st.dequeueTransform = func(value interface{}) interface{} {
+			return transform(clone(value))
+		}
+	}
+}
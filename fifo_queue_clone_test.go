@@ -0,0 +1,95 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+)
+
+// ***************************************************************************************
+// ** SetCloneOnEnqueue / SetCloneOnDequeue
+// ***************************************************************************************
+
+func cloneBytes(value interface{}) interface{} {
+	return append([]byte(nil), value.([]byte)...)
+}
+
+// the producer could reuse the enqueued buffer
+func (suite *FIFOTestSuite) TestSetCloneOnEnqueueSingleGR() {
+	suite.fifo.SetCloneOnEnqueue(cloneBytes)
+	buffer := []byte("abc")
+	suite.fifo.Enqueue(buffer)
+	copy(buffer, "xyz")
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]byte("abc"), value, "The enqueued element must be a copy")
+
+	// the clone runs before the enqueue transform
+	suite.fifo.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		value.([]byte)[0] = 'A'
+		return value, nil
+	})
+	suite.fifo.Enqueue(buffer)
+	suite.Equal([]byte("xyz"), buffer, "The enqueue transform must get the copy")
+
+	suite.fifo.SetCloneOnEnqueue(nil)
+	suite.fifo.SetEnqueueTransform(nil)
+	suite.fifo.Clear()
+	suite.fifo.Enqueue(buffer)
+	copy(buffer, "abc")
+	value, _ = suite.fifo.Dequeue()
+	suite.Equal([]byte("abc"), value, "A nil clone must store the elements as they are")
+}
+
+// the consumer could modify the dequeued element
+func (suite *FIFOTestSuite) TestSetCloneOnDequeueSingleGR() {
+	buffer := []byte("abc")
+	suite.fifo.SetCloneOnDequeue(cloneBytes)
+	suite.fifo.SetDequeueTransform(func(value interface{}) interface{} {
+		value.([]byte)[0] = 'A'
+		return value
+	})
+	suite.fifo.Enqueue(buffer)
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]byte("Abc"), value, "The dequeue transform must be applied to the copy")
+	suite.Equal([]byte("abc"), buffer, "The stored element must not be modified")
+
+	suite.fifo.SetDequeueTransform(nil)
+	suite.fifo.Enqueue(buffer)
+	value, _ = suite.fifo.Dequeue()
+	value.([]byte)[0] = 'X'
+	suite.Equal([]byte("abc"), buffer, "The dequeued element must be a copy")
+
+	suite.fifo.SetCloneOnDequeue(nil)
+	suite.fifo.Enqueue(buffer)
+	value, _ = suite.fifo.Dequeue()
+	value.([]byte)[0] = 'X'
+	suite.Equal([]byte("Xbc"), buffer, "A nil clone must return the elements as they were stored")
+}
+
+// producers reusing their buffers
+func (suite *FIFOTestSuite) TestSetCloneOnEnqueueMultipleGRs() {
+	suite.fifo.SetCloneOnEnqueue(cloneBytes)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(producer byte) {
+			defer wg.Done()
+			buffer := []byte{producer}
+			for j := 0; j < 50; j++ {
+				suite.fifo.Enqueue(buffer)
+				buffer[0] = 0
+				buffer[0] = producer
+			}
+		}(byte(i + 1))
+	}
+	wg.Wait()
+
+	values, _ := suite.fifo.TakeAndClear()
+	suite.Len(values, 200, "Wrong number of enqueued elements")
+	for _, value := range values {
+		suite.NotEqual(byte(0), value.([]byte)[0], "The stored elements must not be modified by the producers")
+	}
+}
@@ -0,0 +1,188 @@
+package goconcurrentqueue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// SetCodec sets the functions to encode/decode the elements, see Export and Import. A nil enc or dec disables them.
+func (st *FIFO) SetCodec(enc func(interface{}) ([]byte, error), dec func([]byte) (interface{}, error)) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.codecEncode = enc
+	st.codecDecode = dec
+}
+
+// Export writes all the enqueued elements (a snapshot taken at once, from the head to the tail) to w, every element
+// being encoded by the codec (see SetCodec) and prefixed by its length (4 bytes, big endian). The elements are kept
+// at the queue.
+func (st *FIFO) Export(w io.Writer) error {
+	if st.isLocked {
+		return errors.New("The queue is locked")
+	}
+
+	st.rwmutex.RLock()
+	enc := st.codecEncode
+	snapshot := make([]interface{}, len(st.slice))
+	copy(snapshot, st.slice)
+	st.rwmutex.RUnlock()
+
+	if enc == nil {
+		return fmt.Errorf("no codec set")
+	}
+
+	for i, value := range snapshot {
+		data, err := enc(value)
+		if err != nil {
+			return fmt.Errorf("element %v could not be encoded: %v", i, err)
+		}
+
+		var prefix [4]byte
+		binary.BigEndian.PutUint32(prefix[:], uint32(len(data)))
+		if _, err := w.Write(prefix[:]); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import replaces the enqueued elements by the ones read from r (written by Export), decoded by the codec (see
+// SetCodec). The queue is kept untouched if any element could not be read or decoded.
+func (st *FIFO) Import(r io.Reader) error {
+	if st.isLocked {
+		return errors.New("The queue is locked")
+	}
+
+	st.rwmutex.RLock()
+	dec := st.codecDecode
+	st.rwmutex.RUnlock()
+
+	if dec == nil {
+		return fmt.Errorf("no codec set")
+	}
+
+	values := make([]interface{}, 0)
+	for {
+		var prefix [4]byte
+		if _, err := io.ReadFull(r, prefix[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("element %v could not be read: %v", len(values), err)
+		}
+
+		// the buffer grows while the data gets read: a corrupted length does not allocate up to 4GB at once
+		var data bytes.Buffer
+		if _, err := io.CopyN(&data, r, int64(binary.BigEndian.Uint32(prefix[:]))); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("element %v could not be read: %v", len(values), err)
+		}
+
+		value, err := dec(data.Bytes())
+		if err != nil {
+			return fmt.Errorf("element %v could not be decoded: %v", len(values), err)
+		}
+		values = append(values, value)
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for _, meta := range st.meta {
+		meta.release()
+	}
+	st.slice = values
+	st.headChanged()
+	if st.meta != nil {
+		st.meta = make([]elementMeta, len(values))
+		for i := range st.meta {
+			st.meta[i] = st.newElementMeta()
+		}
+	}
+	st.recountKeys()
+	st.signalEnqueue()
+
+	return nil
+}
+
+// queueReader serves a FIFO's elements as the bytes written by Export, see NewQueueReader
+type queueReader struct {
+	queue  *FIFO
+	encode func(interface{}) ([]byte, error)
+	// encoded element (length prefix included) not fully read yet
+	pending []byte
+	element [1]interface{}
+}
+
+// NewQueueReader returns an io.Reader serving q's elements in the Export format (every element being encoded by encode
+// and prefixed by its length), e.g. to io.Copy them into a socket or a file. The elements get dequeued lazily, one at a
+// time, as the bytes are read. Read returns io.EOF once the queue is empty and every dequeued element has been read:
+// later Reads serve the elements enqueued in the meantime. An element that could not be encoded is discarded
+// (DeadLetterReasonValidation, see SetDeadLetterHandler) and Read returns the error.
+func NewQueueReader(q *FIFO, encode func(interface{}) ([]byte, error)) io.Reader {
+	return &queueReader{
+		queue:  q,
+		encode: encode,
+	}
+}
+
+// Read reads the next bytes, dequeuing as many elements as needed to fill p
+func (st *queueReader) Read(p []byte) (int, error) {
+	if st.encode == nil {
+		return 0, fmt.Errorf("no encode function set")
+	}
+
+	total := 0
+	for total < len(p) {
+		if len(st.pending) == 0 {
+			if err := st.next(); err != nil {
+				if err == io.EOF && total > 0 {
+					return total, nil
+				}
+				return total, err
+			}
+		}
+
+		n := copy(p[total:], st.pending)
+		st.pending = st.pending[n:]
+		total += n
+	}
+
+	return total, nil
+}
+
+// next dequeues and encodes the next element, returning io.EOF if the queue is empty
+func (st *queueReader) next() error {
+	n, err := st.queue.DrainInto(st.element[:])
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return io.EOF
+	}
+	value := st.element[0]
+	st.element[0] = nil
+
+	data, err := st.encode(value)
+	if err != nil {
+		st.queue.discard(DeadLetterReasonValidation, value)
+		return fmt.Errorf("element could not be encoded: %v", err)
+	}
+
+	st.pending = make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(st.pending, uint32(len(data)))
+	copy(st.pending[4:], data)
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package goconcurrentqueue
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ***************************************************************************************
+// ** SetCodec / Export / Import
+// ***************************************************************************************
+
+func encodeInt(value interface{}) ([]byte, error) {
+	number, ok := value.(int)
+	if !ok {
+		return nil, fmt.Errorf("not an int: %v", value)
+	}
+
+	return []byte(strconv.Itoa(number)), nil
+}
+
+func decodeInt(data []byte) (interface{}, error) {
+	return strconv.Atoi(string(data))
+}
+
+// exported elements get imported by other queue
+func (suite *FIFOTestSuite) TestExportImportSingleGR() {
+	suite.fifo.SetCodec(encodeInt, decodeInt)
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i * 100)
+	}
+
+	var buffer bytes.Buffer
+	suite.NoError(suite.fifo.Export(&buffer), "Unexpected error")
+	suite.Equal(5, suite.fifo.GetLen(), "The exported elements must be kept at the queue")
+
+	fifo := NewFIFO()
+	fifo.SetCodec(encodeInt, decodeInt)
+	fifo.Enqueue(-1)
+	suite.NoError(fifo.Import(&buffer), "Unexpected error")
+	suite.Equal([]interface{}{0, 100, 200, 300, 400}, dequeueAll(fifo), "Import must replace the elements")
+}
+
+// an empty export
+func (suite *FIFOTestSuite) TestExportImportEmptySingleGR() {
+	suite.fifo.SetCodec(encodeInt, decodeInt)
+
+	var buffer bytes.Buffer
+	suite.NoError(suite.fifo.Export(&buffer), "Unexpected error")
+	suite.Equal(0, buffer.Len(), "Nothing expected to be written")
+
+	suite.fifo.Enqueue(1)
+	suite.NoError(suite.fifo.Import(&buffer), "Unexpected error")
+	suite.Equal(0, suite.fifo.GetLen(), "Import must replace the elements")
+}
+
+// errors
+func (suite *FIFOTestSuite) TestExportImportErrorsSingleGR() {
+	var buffer bytes.Buffer
+	suite.Error(suite.fifo.Export(&buffer), "error expected if no codec was set")
+	suite.Error(suite.fifo.Import(&buffer), "error expected if no codec was set")
+
+	suite.fifo.SetCodec(encodeInt, decodeInt)
+	suite.fifo.Enqueue("not an int")
+	suite.Error(suite.fifo.Export(&buffer), "error expected if an element could not be encoded")
+
+	// truncated stream
+	suite.fifo.Import(bytes.NewReader(nil))
+	suite.fifo.Enqueue(12345)
+	buffer.Reset()
+	suite.fifo.Export(&buffer)
+	suite.fifo.Enqueue(1)
+	truncated := buffer.Bytes()[:buffer.Len()-1]
+	suite.Error(suite.fifo.Import(bytes.NewReader(truncated)), "error expected for a truncated stream")
+	suite.Equal(2, suite.fifo.GetLen(), "The queue must be kept untouched if the import fails")
+
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.Export(&buffer), "The queue is locked")
+	suite.Error(suite.fifo.Import(&buffer), "The queue is locked")
+}
+
+// a corrupted length prefix does not allocate the whole length
+func (suite *FIFOTestSuite) TestImportCorruptedLengthSingleGR() {
+	suite.fifo.SetCodec(encodeInt, decodeInt)
+
+	var before, after runtime.MemStats
+	stream := append([]byte{0xff, 0xff, 0xff, 0xff}, []byte("123")...)
+	runtime.ReadMemStats(&before)
+	suite.Error(suite.fifo.Import(bytes.NewReader(stream)), "error expected for a truncated element")
+	runtime.ReadMemStats(&after)
+	suite.True(after.TotalAlloc-before.TotalAlloc < 1<<20, "The data must be allocated while it gets read")
+	suite.Equal(0, suite.fifo.GetLen(), "The queue must be kept untouched if the import fails")
+}
+
+// export while other GRs enqueue: a consistent snapshot
+func (suite *FIFOTestSuite) TestExportMultipleGRs() {
+	var (
+		totalGRs = 50
+		wg       sync.WaitGroup
+		buffer   bytes.Buffer
+	)
+	suite.fifo.SetCodec(encodeInt, decodeInt)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+	suite.NoError(suite.fifo.Export(&buffer), "Unexpected error")
+	wg.Wait()
+
+	fifo := NewFIFO()
+	fifo.SetCodec(encodeInt, decodeInt)
+	suite.NoError(fifo.Import(&buffer), "Unexpected error")
+	suite.True(fifo.GetLen() <= totalGRs, "Wrong number of exported elements")
+}
+
+// ***************************************************************************************
+// ** NewQueueReader
+// ***************************************************************************************
+
+// the read bytes get imported by other queue
+func (suite *FIFOTestSuite) TestNewQueueReaderSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i * 100)
+	}
+
+	var buffer bytes.Buffer
+	reader := NewQueueReader(suite.fifo, encodeInt)
+	n, err := io.Copy(&buffer, reader)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(int64(5*4+1+3*4), n, "Wrong number of bytes")
+	suite.Equal(0, suite.fifo.GetLen(), "The elements must be dequeued")
+
+	other := NewFIFO()
+	other.SetCodec(encodeInt, decodeInt)
+	suite.NoError(other.Import(&buffer), "Unexpected error")
+	values, _ := other.TakeAndClear()
+	suite.Equal([]interface{}{0, 100, 200, 300, 400}, values, "Wrong imported elements")
+
+	// the elements enqueued after io.EOF
+	suite.fifo.Enqueue(7)
+	data := make([]byte, 2)
+	n2, err := reader.Read(data)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, n2, "The buffer must be filled")
+	rest, err := ioutil.ReadAll(reader)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]byte{0, 0, 0, 1, '7'}, append(data, rest...), "Wrong bytes")
+}
+
+// read errors
+func (suite *FIFOTestSuite) TestNewQueueReaderErrorsSingleGR() {
+	var discarded []interface{}
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) { discarded = append(discarded, value) })
+	suite.fifo.Enqueue("a")
+
+	_, err := NewQueueReader(suite.fifo, nil).Read(make([]byte, 1))
+	suite.Error(err, "No encode function")
+
+	_, err = NewQueueReader(suite.fifo, encodeInt).Read(make([]byte, 1))
+	suite.Error(err, "The element could not be encoded")
+	suite.Equal([]interface{}{"a"}, discarded, "The element must be discarded")
+
+	suite.fifo.Lock()
+	_, err = NewQueueReader(suite.fifo, encodeInt).Read(make([]byte, 1))
+	suite.Error(err, "Locked queue does not allow to read elements")
+}
+
+// concurrent readers: every element gets read once
+func (suite *FIFOTestSuite) TestNewQueueReaderMultipleGRs() {
+	for i := 0; i < 200; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		buffers []*bytes.Buffer
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buffer bytes.Buffer
+			io.Copy(&buffer, NewQueueReader(suite.fifo, encodeInt))
+			mutex.Lock()
+			buffers = append(buffers, &buffer)
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[interface{}]bool)
+	for _, buffer := range buffers {
+		other := NewFIFO()
+		other.SetCodec(encodeInt, decodeInt)
+		suite.NoError(other.Import(buffer), "Every reader must serve whole elements")
+		values, _ := other.TakeAndClear()
+		for _, value := range values {
+			suite.False(seen[value], "An element must not be read twice")
+			seen[value] = true
+		}
+	}
+	suite.Len(seen, 200, "Every element must be read")
+}
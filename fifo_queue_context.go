@@ -0,0 +1,91 @@
+package goconcurrentqueue
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// EnqueueCtx enqueues an element tied to ctx (e.g. the request that originated it), see DequeueCtx. An error is
+// returned if ctx is already done.
+func (st *FIFO) EnqueueCtx(ctx context.Context, value interface{}) error {
+	if ctx == nil {
+		return errors.New("nil context")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if st.isLocked {
+		return st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.ensureMeta()
+	index, err := st.enqueue(value)
+	if err != nil {
+		return err
+	}
+	st.meta[index].ctx = ctx
+
+	return nil
+}
+
+// DequeueCtx dequeues an element plus the context it was enqueued with (see EnqueueCtx), context.Background() for the
+// elements enqueued by the rest of the enqueue methods. The elements whose context is already done are removed and
+// dropped (reason: DeadLetterReasonCanceled, see SetDeadLetterHandler) instead of being returned, so abandoned work is
+// not processed: they are not dequeued (neither the dequeue hook nor the history get them). The rest of the dequeue methods return the elements no matter their context.
+func (st *FIFO) DequeueCtx() (context.Context, interface{}, error) {
+	if st.isLocked {
+		return nil, nil, errors.New("The queue is locked")
+	}
+
+	var (
+		value    interface{}
+		meta     elementMeta
+		err      error
+		canceled []interface{}
+	)
+	st.rwmutex.Lock()
+	for {
+		if err = st.checkSequence(); err != nil {
+			break
+		}
+		index := st.nextIndex()
+		if st.meta != nil && index < len(st.meta) && st.meta[index].ctx != nil && st.meta[index].ctx.Err() != nil {
+			// removed without being dequeued
+			dropped := st.removeAt(index)
+			st.advanceSequence(dropped)
+			canceled = append(canceled, dropped)
+			continue
+		}
+
+		if value, meta, err = st.dequeueIndex(index); err == nil {
+			st.advanceSequence(value)
+		}
+		break
+	}
+	transform := st.dequeueTransform
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+	st.discard(DeadLetterReasonCanceled, canceled...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := meta.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return ctx, applyDequeueTransform(transform, value), nil
+}
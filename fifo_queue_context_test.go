@@ -0,0 +1,116 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey string
+
+// ***************************************************************************************
+// ** EnqueueCtx / DequeueCtx
+// ***************************************************************************************
+
+// single EnqueueCtx / DequeueCtx lock verification
+func (suite *FIFOTestSuite) TestEnqueueCtxLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.EnqueueCtx(context.Background(), 1), "Locked queue does not allow to enqueue elements")
+	_, _, err := suite.fifo.DequeueCtx()
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// the element's context is returned
+func (suite *FIFOTestSuite) TestEnqueueCtxSingleGR() {
+	ctx := context.WithValue(context.Background(), contextKey("request"), "r1")
+	suite.NoError(suite.fifo.EnqueueCtx(ctx, 1), "Unexpected error")
+	suite.fifo.Enqueue(2)
+
+	elementCtx, value, err := suite.fifo.DequeueCtx()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "Wrong element's value")
+	suite.Equal("r1", elementCtx.Value(contextKey("request")), "The element's context must be returned")
+
+	elementCtx, value, err = suite.fifo.DequeueCtx()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, value, "Wrong element's value")
+	suite.Equal(context.Background(), elementCtx, "context.Background() expected for elements enqueued without context")
+
+	_, _, err = suite.fifo.DequeueCtx()
+	suite.Error(err, "Can't dequeue an empty queue")
+}
+
+// elements whose context is done are dropped
+func (suite *FIFOTestSuite) TestDequeueCtxCanceledSingleGR() {
+	var dropped []interface{}
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		suite.Equal(DeadLetterReasonCanceled, reason, "Unexpected reason")
+		dropped = append(dropped, value)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.fifo.EnqueueCtx(ctx, 1)
+	suite.fifo.EnqueueCtx(ctx, 2)
+	suite.fifo.EnqueueCtx(context.Background(), 3)
+	cancel()
+	suite.Error(suite.fifo.EnqueueCtx(ctx, 4), "error expected for a done context")
+
+	_, value, err := suite.fifo.DequeueCtx()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(3, value, "Elements whose context is done must be skipped")
+	suite.Equal([]interface{}{1, 2}, dropped, "The skipped elements must be dropped")
+	suite.Equal(0, suite.fifo.GetLen(), "The skipped elements must be dropped")
+}
+
+// the elements whose context is done are not dequeued
+func (suite *FIFOTestSuite) TestDequeueCtxCanceledNotDequeuedSingleGR() {
+	var hooked []interface{}
+	suite.fifo.SetDequeueHook(func(value interface{}) {
+		hooked = append(hooked, value)
+	})
+	suite.fifo.SetHistorySize(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.fifo.EnqueueCtx(ctx, 1)
+	suite.fifo.EnqueueCtx(context.Background(), 2)
+	cancel()
+
+	_, value, err := suite.fifo.DequeueCtx()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, value, "Elements whose context is done must be skipped")
+	suite.Equal([]interface{}{2}, hooked, "The dequeue hook must not get the dropped elements")
+	suite.Equal([]interface{}{2}, suite.fifo.History(), "The history must not get the dropped elements")
+}
+
+// the contexts are kept once timestamp tracking gets disabled
+func (suite *FIFOTestSuite) TestEnqueueCtxTimestampTrackingSingleGR() {
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.fifo.SetTimestampTracking(true)
+	suite.fifo.EnqueueCtx(ctx, 1)
+	suite.fifo.SetTimestampTracking(false)
+	cancel()
+
+	_, _, err := suite.fifo.DequeueCtx()
+	suite.Error(err, "The element's context must be kept")
+}
+
+// concurrent EnqueueCtx / DequeueCtx
+func (suite *FIFOTestSuite) TestEnqueueCtxMultipleGRs() {
+	var (
+		wg    sync.WaitGroup
+		total = 100
+	)
+	wg.Add(total)
+	for i := 0; i < total; i++ {
+		go func(i int) {
+			defer wg.Done()
+			suite.fifo.EnqueueCtx(context.WithValue(context.Background(), contextKey("id"), i), i)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < total; i++ {
+		ctx, value, err := suite.fifo.DequeueCtx()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(value, ctx.Value(contextKey("id")), "Every element must keep its own context")
+	}
+}
@@ -0,0 +1,80 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Future is the result of an element enqueued by FIFO.EnqueueWithResult, to be set by the consumer (see Complete)
+type Future interface {
+	// Get waits for the result, returning an error if ctx gets done before
+	Get(ctx context.Context) (interface{}, error)
+}
+
+// FutureElement is the element enqueued by FIFO.EnqueueWithResult: consumers dequeue it, process Value and pass Future
+// to Complete.
+type FutureElement struct {
+	Value  interface{}
+	Future Future
+}
+
+// future is the package's Future implementation
+type future struct {
+	// closed once the result is set
+	done   chan struct{}
+	once   sync.Once
+	result interface{}
+}
+
+func newFuture() *future {
+	return &future{
+		done: make(chan struct{}),
+	}
+}
+
+func (st *future) Get(ctx context.Context) (interface{}, error) {
+	select {
+	case <-st.done:
+		return st.result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Complete sets the future's result, waking up the producers waiting for it (see Future.Get). An error is returned if
+// the future was already completed or it was not returned by EnqueueWithResult.
+func Complete(f Future, result interface{}) error {
+	st, ok := f.(*future)
+	if !ok {
+		return errors.New("unknown future")
+	}
+
+	completed := false
+	st.once.Do(func() {
+		st.result = result
+		close(st.done)
+		completed = true
+	})
+	if !completed {
+		return errors.New("the future is already completed")
+	}
+
+	return nil
+}
+
+// EnqueueWithResult enqueues the element wrapped by a *FutureElement, returning its Future: the producer waits for the
+// result (Future.Get) set by the consumer processing the element (Complete).
+func (st *FIFO) EnqueueWithResult(value interface{}) (Future, error) {
+	element := &FutureElement{
+		Value:  value,
+		Future: newFuture(),
+	}
+
+	if err := st.Enqueue(element); err != nil {
+		return nil, err
+	}
+
+	return element.Future, nil
+}
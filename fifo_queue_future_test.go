@@ -0,0 +1,77 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ***************************************************************************************
+// ** EnqueueWithResult / Complete
+// ***************************************************************************************
+
+// single EnqueueWithResult lock verification
+func (suite *FIFOTestSuite) TestEnqueueWithResultLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.EnqueueWithResult(1)
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+}
+
+// the consumer completes the future
+func (suite *FIFOTestSuite) TestEnqueueWithResultSingleGR() {
+	future, err := suite.fifo.EnqueueWithResult(2)
+	suite.NoError(err, "Unexpected error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = future.Get(ctx)
+	suite.Equal(context.DeadlineExceeded, err, "The result is not set yet")
+
+	value, _ := suite.fifo.Dequeue()
+	element, ok := value.(*FutureElement)
+	suite.True(ok, "A *FutureElement is expected")
+	suite.Equal(2, element.Value, "Wrong element's value")
+	suite.NoError(Complete(element.Future, 4), "Unexpected error")
+	suite.Error(Complete(element.Future, 5), "error expected completing a completed future")
+
+	result, err := future.Get(context.Background())
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(4, result, "Wrong result")
+}
+
+// unknown futures
+func (suite *FIFOTestSuite) TestCompleteUnknownFutureSingleGR() {
+	suite.Error(Complete(nil, 1), "error expected completing an unknown future")
+}
+
+// producers wait for the results set by concurrent consumers
+func (suite *FIFOTestSuite) TestEnqueueWithResultMultipleGRs() {
+	var (
+		totalGRs = 10
+		wg       sync.WaitGroup
+	)
+
+	// consumers: one element each
+	wg.Add(2 * totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := suite.fifo.DequeueOrWaitForNextElement()
+			suite.NoError(err, "Unexpected error")
+			element := value.(*FutureElement)
+			Complete(element.Future, element.Value.(int)*2)
+		}()
+	}
+
+	// producers
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			future, _ := suite.fifo.EnqueueWithResult(value)
+			result, err := future.Get(context.Background())
+			suite.NoError(err, "Unexpected error")
+			suite.Equal(value*2, result, "Wrong result")
+		}(i)
+	}
+	wg.Wait()
+}
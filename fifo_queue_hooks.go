@@ -0,0 +1,92 @@
+package goconcurrentqueue
+
+import (
+	"sync/atomic"
+)
+
+// hookCall is a hook execution waiting for the queue's lock to be released
+type hookCall struct {
+	hook  func(interface{})
+	value interface{}
+}
+
+// SetEnqueueHook sets a function to be called for every enqueued element (as it was stored, after the enqueue
+// transform). A nil hook disables it.
+func (st *FIFO) SetEnqueueHook(hook func(value interface{})) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.enqueueHook = hook
+}
+
+// SetDequeueHook sets a function to be called for every dequeued element (as it was stored, before the dequeue
+// transform). A nil hook disables it.
+func (st *FIFO) SetDequeueHook(hook func(value interface{})) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.dequeueHook = hook
+}
+
+// SetHooksSynchronous sets whether the enqueue/dequeue hooks run while the queue's lock is held (true) or once it gets
+// released (false, default).
+//
+// Synchronous hooks see no other operation between the state change and the hook execution (e.g. an audit log
+// follows the exact order of the operations), but they block the queue while running. A synchronous hook must not
+// call the queue (not even GetLen): it would deadlock.
+func (st *FIFO) SetHooksSynchronous(synchronous bool) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.hooksSynchronous = synchronous
+}
+
+// enqueued records the enqueue (see StartRecording) and runs (or schedules) the enqueue hook. It must be called
+// holding st.rwmutex.
+func (st *FIFO) enqueued(value interface{}) {
+	st.operations++
+	st.record(OpEnqueue, value)
+	st.runHook(st.enqueueHook, value)
+}
+
+// dequeued records a dequeued element (history, see StartRecording) and runs (or schedules) the dequeue hook. It must
+// be called holding st.rwmutex.
+func (st *FIFO) dequeued(value interface{}) {
+	st.operations++
+	st.addToHistory(value)
+	st.record(OpDequeue, value)
+	st.runHook(st.dequeueHook, value)
+}
+
+// runHook runs the hook right away if the hooks are synchronous, otherwise it gets scheduled to run once the lock gets
+// released (see runPendingHooks). It must be called holding st.rwmutex.
+func (st *FIFO) runHook(hook func(interface{}), value interface{}) {
+	if hook == nil {
+		return
+	}
+
+	if st.hooksSynchronous {
+		hook(value)
+		return
+	}
+
+	st.pendingHooks = append(st.pendingHooks, hookCall{hook: hook, value: value})
+	atomic.StoreInt32(&st.hooksPending, 1)
+}
+
+// runPendingHooks runs the scheduled hooks. It must be called without holding st.rwmutex.
+func (st *FIFO) runPendingHooks() {
+	if atomic.LoadInt32(&st.hooksPending) == 0 {
+		return
+	}
+
+	st.rwmutex.Lock()
+	pending := st.pendingHooks
+	st.pendingHooks = nil
+	atomic.StoreInt32(&st.hooksPending, 0)
+	st.rwmutex.Unlock()
+
+	for _, call := range pending {
+		call.hook(call.value)
+	}
+}
@@ -0,0 +1,83 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+)
+
+// ***************************************************************************************
+// ** SetEnqueueHook / SetDequeueHook / SetHooksSynchronous
+// ***************************************************************************************
+
+// hooks get called for every enqueued/dequeued element
+func (suite *FIFOTestSuite) TestHooksSingleGR() {
+	var enqueued, dequeued []interface{}
+	suite.fifo.SetEnqueueHook(func(value interface{}) { enqueued = append(enqueued, value) })
+	suite.fifo.SetDequeueHook(func(value interface{}) { dequeued = append(dequeued, value) })
+	suite.fifo.SetDequeueTransform(func(value interface{}) interface{} { return value.(int) * 10 })
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.EnqueueBatch([]interface{}{2, 3})
+	suite.fifo.EnqueueAt(0, 0)
+	suite.fifo.Dequeue()
+	suite.fifo.DequeueWhere(func(value interface{}) bool { return value.(int) == 2 })
+	suite.fifo.Remove(0)
+
+	suite.Equal([]interface{}{1, 2, 3, 0}, enqueued, "Wrong enqueued elements")
+	suite.Equal([]interface{}{0, 2}, dequeued, "The dequeue hook gets the elements as they were stored")
+
+	// disabled hooks
+	suite.fifo.SetEnqueueHook(nil)
+	suite.fifo.SetDequeueHook(nil)
+	suite.fifo.Enqueue(4)
+	suite.fifo.Dequeue()
+	suite.Len(enqueued, 4, "No enqueue hook call expected once disabled")
+	suite.Len(dequeued, 2, "No dequeue hook call expected once disabled")
+}
+
+// asynchronous hooks run once the lock was released, so they could use the queue
+func (suite *FIFOTestSuite) TestHooksAsynchronousSingleGR() {
+	lens := make([]int, 0)
+	suite.fifo.SetEnqueueHook(func(value interface{}) { lens = append(lens, suite.fifo.GetLen()) })
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	suite.Equal([]int{1, 2}, lens, "Wrong queue's length seen by the hook")
+}
+
+// synchronous hooks follow the exact order of the operations
+func (suite *FIFOTestSuite) TestHooksSynchronousMultipleGRs() {
+	var (
+		totalGRs = 100
+		wg       sync.WaitGroup
+		// accessed only by the (synchronous) hooks, while the queue's lock is held
+		events = make([]interface{}, 0)
+	)
+	suite.fifo.SetHooksSynchronous(true)
+	suite.fifo.SetEnqueueHook(func(value interface{}) { events = append(events, value) })
+	suite.fifo.SetDequeueHook(func(value interface{}) { events = append(events, -value.(int)) })
+
+	wg.Add(2 * totalGRs)
+	for i := 1; i <= totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+		go func() {
+			defer wg.Done()
+			suite.fifo.Dequeue()
+		}()
+	}
+	wg.Wait()
+
+	// replaying the events must produce the queue's content
+	replay := make([]interface{}, 0)
+	for _, event := range events {
+		if event.(int) > 0 {
+			replay = append(replay, event)
+			continue
+		}
+		suite.Equal(-event.(int), replay[0], "Dequeue events must follow the enqueue order")
+		replay = replay[1:]
+	}
+	suite.Equal(replay, suite.fifo.slice, "Replaying the events must produce the queue's content")
+}
@@ -0,0 +1,98 @@
+package goconcurrentqueue
+
+import (
+	"container/list"
+	"time"
+)
+
+const (
+	// EnqueueIdempotent's default retention && max remembered keys, see SetIdempotencyRetention
+	defaultIdempotencyRetention = 5 * time.Minute
+	defaultIdempotencyMaxKeys   = 10000
+)
+
+// idempotencyKey is a key accepted by EnqueueIdempotent
+type idempotencyKey struct {
+	key        string
+	acceptedAt time.Time
+}
+
+// SetIdempotencyRetention sets for how long EnqueueIdempotent remembers the accepted keys, and up to how many of them
+// it remembers: once there are maxKeys keys, the least recently accepted one gets forgotten. Values <= 0 restore the
+// defaults (5 minutes, 10000 keys). The already remembered keys are kept.
+func (st *FIFO) SetIdempotencyRetention(retention time.Duration, maxKeys int) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.idempotencyRetention = retention
+	st.idempotencyMaxKeys = maxKeys
+}
+
+// EnqueueIdempotent enqueues an element only if key was not accepted (by EnqueueIdempotent) within the retention
+// window, returning true whether the element was enqueued. Unlike EnqueueUnique, the keys are remembered after the
+// elements get dequeued, so retried submissions are not processed twice. See SetIdempotencyRetention.
+func (st *FIFO) EnqueueIdempotent(value interface{}, key string) (bool, error) {
+	if st.isLocked {
+		return false, st.rejectLocked()
+	}
+
+	value, err := st.transformEnqueued(value)
+	if err != nil {
+		return false, err
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	now := st.now()
+	if st.idempotencyKeys == nil {
+		st.idempotencyKeys = make(map[string]*list.Element)
+		st.idempotencyOrder = list.New()
+	}
+	st.forgetIdempotencyKeys(now)
+
+	if _, ok := st.idempotencyKeys[key]; ok {
+		st.countRejection(rejectionDuplicate)
+		return false, nil
+	}
+
+	if _, err := st.enqueue(value); err != nil {
+		return false, err
+	}
+	st.idempotencyKeys[key] = st.idempotencyOrder.PushBack(idempotencyKey{key: key, acceptedAt: now})
+
+	// room for the next key
+	maxKeys := st.idempotencyMaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultIdempotencyMaxKeys
+	}
+	for st.idempotencyOrder.Len() > maxKeys {
+		st.forgetOldestIdempotencyKey()
+	}
+
+	return true, nil
+}
+
+// forgetIdempotencyKeys forgets the keys accepted before the retention window. It must be called holding st.rwmutex.
+func (st *FIFO) forgetIdempotencyKeys(now time.Time) {
+	retention := st.idempotencyRetention
+	if retention <= 0 {
+		retention = defaultIdempotencyRetention
+	}
+
+	// the keys are sorted by acceptance time
+	for oldest := st.idempotencyOrder.Front(); oldest != nil; oldest = st.idempotencyOrder.Front() {
+		if now.Sub(oldest.Value.(idempotencyKey).acceptedAt) < retention {
+			return
+		}
+		st.forgetOldestIdempotencyKey()
+	}
+}
+
+// forgetOldestIdempotencyKey forgets the least recently accepted key. It must be called holding st.rwmutex.
+func (st *FIFO) forgetOldestIdempotencyKey() {
+	oldest := st.idempotencyOrder.Front()
+	st.idempotencyOrder.Remove(oldest)
+	delete(st.idempotencyKeys, oldest.Value.(idempotencyKey).key)
+}
@@ -0,0 +1,98 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ***************************************************************************************
+// ** EnqueueIdempotent / SetIdempotencyRetention
+// ***************************************************************************************
+
+// single EnqueueIdempotent lock verification
+func (suite *FIFOTestSuite) TestEnqueueIdempotentLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.EnqueueIdempotent(1, "a")
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+}
+
+// duplicated keys are rejected, also once the element was dequeued
+func (suite *FIFOTestSuite) TestEnqueueIdempotentSingleGR() {
+	clock := newFakeClock()
+	suite.fifo.SetClock(clock)
+
+	accepted, err := suite.fifo.EnqueueIdempotent(1, "a")
+	suite.NoError(err, "Unexpected error")
+	suite.True(accepted, "The first element must be accepted")
+	suite.fifo.Dequeue()
+
+	accepted, _ = suite.fifo.EnqueueIdempotent(2, "a")
+	suite.False(accepted, "A duplicated key must be rejected once the element was dequeued")
+	accepted, _ = suite.fifo.EnqueueIdempotent(3, "b")
+	suite.True(accepted, "Other key must be accepted")
+
+	clock.Advance(defaultIdempotencyRetention)
+	accepted, _ = suite.fifo.EnqueueIdempotent(4, "a")
+	suite.True(accepted, "The key must be forgotten after the retention window")
+	suite.Equal([]interface{}{3, 4}, dequeueAll(suite.fifo), "Wrong accepted elements")
+}
+
+// custom retention && max keys
+func (suite *FIFOTestSuite) TestSetIdempotencyRetentionSingleGR() {
+	clock := newFakeClock()
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetIdempotencyRetention(time.Second, 2)
+
+	for _, key := range []string{"a", "b", "c"} {
+		suite.fifo.EnqueueIdempotent(key, key)
+	}
+	accepted, _ := suite.fifo.EnqueueIdempotent("a", "a")
+	suite.True(accepted, "The least recently accepted key must be forgotten once there are too many keys")
+	accepted, _ = suite.fifo.EnqueueIdempotent("c", "c")
+	suite.False(accepted, "The rest of the keys must be remembered")
+	suite.Len(suite.fifo.idempotencyKeys, 2, "No more than max keys must be remembered")
+
+	clock.Advance(time.Second)
+	accepted, _ = suite.fifo.EnqueueIdempotent("c", "c")
+	suite.True(accepted, "The key must be forgotten after the retention window")
+	suite.Len(suite.fifo.idempotencyKeys, 1, "The expired keys must be forgotten")
+}
+
+// a failed enqueue does not remember the key
+func (suite *FIFOTestSuite) TestEnqueueIdempotentErrorSingleGR() {
+	suite.fifo.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		if value == nil {
+			return nil, fmt.Errorf("nil value")
+		}
+		return value, nil
+	})
+
+	_, err := suite.fifo.EnqueueIdempotent(nil, "a")
+	suite.Error(err, "The transform error expected")
+	accepted, _ := suite.fifo.EnqueueIdempotent(1, "a")
+	suite.True(accepted, "The key of a failed enqueue must not be remembered")
+}
+
+// the same key submitted concurrently gets accepted once
+func (suite *FIFOTestSuite) TestEnqueueIdempotentMultipleGRs() {
+	var (
+		wg       sync.WaitGroup
+		accepted int32
+		totalGRs = 50
+	)
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(i int) {
+			defer wg.Done()
+			if ok, _ := suite.fifo.EnqueueIdempotent(i, fmt.Sprintf("key-%v", i%5)); ok {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(int32(5), atomic.LoadInt32(&accepted), "Every key must be accepted once")
+	suite.Equal(5, suite.fifo.GetLen(), "Wrong length")
+}
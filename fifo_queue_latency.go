@@ -0,0 +1,54 @@
+package goconcurrentqueue
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// opLatencyWeight is the weight (1 / opLatencyWeight) of every new sample at the operation latency averages
+const opLatencyWeight = 8
+
+// SetTrackOpLatency enables/disables tracking how long every Enqueue/Dequeue takes (including the time waiting for the
+// queue's lock), see OperationLatency. The tracking uses the real time, no matter the clock (see SetClock). Disabled by
+// default: it costs a single atomic load per operation while disabled.
+func (st *FIFO) SetTrackOpLatency(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&st.trackOpLatency, 1)
+	} else {
+		atomic.StoreInt32(&st.trackOpLatency, 0)
+	}
+}
+
+// OperationLatency returns the exponentially weighted moving averages of the Enqueue/Dequeue durations (see
+// SetTrackOpLatency), 0 if no operation was tracked. The averages are kept if the tracking gets disabled.
+func (st *FIFO) OperationLatency() (enqueueAvg, dequeueAvg time.Duration) {
+	return time.Duration(atomic.LoadInt64(&st.enqueueLatency)), time.Duration(atomic.LoadInt64(&st.dequeueLatency))
+}
+
+// opLatencyStart returns the operation's start time, the zero time if the latency is not tracked
+func (st *FIFO) opLatencyStart() time.Time {
+	if atomic.LoadInt32(&st.trackOpLatency) == 0 {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+// trackOpLatency adds the duration of the operation started at start (see opLatencyStart) to the given average
+func trackOpLatency(average *int64, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+
+	sample := int64(time.Since(start))
+	for {
+		old := atomic.LoadInt64(average)
+		updated := sample
+		if old != 0 {
+			updated = old + (sample-old)/opLatencyWeight
+		}
+		if atomic.CompareAndSwapInt64(average, old, updated) {
+			return
+		}
+	}
+}
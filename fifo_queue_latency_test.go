@@ -0,0 +1,70 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"time"
+)
+
+// ***************************************************************************************
+// ** SetTrackOpLatency / OperationLatency
+// ***************************************************************************************
+
+// no latency gets tracked by default
+func (suite *FIFOTestSuite) TestOperationLatencyDisabledSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Dequeue()
+
+	enqueueAvg, dequeueAvg := suite.fifo.OperationLatency()
+	suite.Equal(time.Duration(0), enqueueAvg, "No enqueue latency expected")
+	suite.Equal(time.Duration(0), dequeueAvg, "No dequeue latency expected")
+}
+
+// the latency includes the time waiting for the lock
+func (suite *FIFOTestSuite) TestOperationLatencySingleGR() {
+	suite.fifo.SetTrackOpLatency(true)
+	suite.fifo.Enqueue(1)
+
+	suite.fifo.rwmutex.Lock()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		suite.fifo.rwmutex.Unlock()
+	}()
+	suite.fifo.Dequeue()
+
+	enqueueAvg, dequeueAvg := suite.fifo.OperationLatency()
+	suite.True(enqueueAvg > 0, "The enqueue latency must be tracked")
+	suite.True(dequeueAvg >= 20*time.Millisecond, "The time waiting for the lock must be tracked")
+
+	// the average
+	suite.fifo.Dequeue()
+	_, average := suite.fifo.OperationLatency()
+	suite.True(average < dequeueAvg && average > dequeueAvg/2, "Wrong average")
+
+	suite.fifo.SetTrackOpLatency(false)
+	suite.fifo.Dequeue()
+	_, disabled := suite.fifo.OperationLatency()
+	suite.Equal(average, disabled, "The average must be kept once the tracking is disabled")
+}
+
+// concurrent tracking
+func (suite *FIFOTestSuite) TestOperationLatencyMultipleGRs() {
+	var wg sync.WaitGroup
+	suite.fifo.SetTrackOpLatency(true)
+
+	wg.Add(20)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			suite.fifo.Dequeue()
+			suite.fifo.OperationLatency()
+		}()
+	}
+	wg.Wait()
+
+	enqueueAvg, _ := suite.fifo.OperationLatency()
+	suite.True(enqueueAvg > 0, "The enqueue latency must be tracked")
+}
@@ -0,0 +1,86 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+)
+
+// SetKeyQuota limits the number of enqueued elements per key: enqueuing an element whose key (keyFn(element)) has
+// already max enqueued elements returns an error. A nil keyFn or max <= 0 disables the quota.
+//
+// The counters are updated on every enqueue/dequeue. It costs one map entry per distinct key having enqueued elements
+// (keys get removed once they have no enqueued elements) plus a keyFn call per enqueued/removed element.
+func (st *FIFO) SetKeyQuota(keyFn func(interface{}) string, max int) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if keyFn == nil || max <= 0 {
+		st.quotaKeyFn = nil
+		st.quotaMax = 0
+		st.quotaCounts = nil
+		return
+	}
+
+	st.quotaKeyFn = keyFn
+	st.quotaMax = max
+	st.recountKeys()
+}
+
+// checkKeyQuota returns an error if the value's key has no quota left. It must be called holding st.rwmutex.
+func (st *FIFO) checkKeyQuota(value interface{}) error {
+	if st.quotaKeyFn == nil {
+		return nil
+	}
+
+	key := st.quotaKeyFn(value)
+	if st.quotaCounts[key] >= st.quotaMax {
+		st.countRejection(rejectionQuota)
+		return fmt.Errorf("quota exceeded for key: %v", key)
+	}
+
+	return nil
+}
+
+// checkKeyQuotaBatch returns an error if the values' keys have no quota left for all of them. It must be called holding
+// st.rwmutex.
+func (st *FIFO) checkKeyQuotaBatch(values []interface{}) error {
+	if st.quotaKeyFn == nil {
+		return nil
+	}
+
+	batchCounts := make(map[string]int)
+	for _, value := range values {
+		key := st.quotaKeyFn(value)
+		batchCounts[key]++
+		if st.quotaCounts[key]+batchCounts[key] > st.quotaMax {
+			st.countRejection(rejectionQuota)
+			return fmt.Errorf("quota exceeded for key: %v", key)
+		}
+	}
+
+	return nil
+}
+
+// trackKey adds delta to the value's key counter. It must be called holding st.rwmutex.
+func (st *FIFO) trackKey(value interface{}, delta int) {
+	if st.quotaKeyFn == nil {
+		return
+	}
+
+	key := st.quotaKeyFn(value)
+	st.quotaCounts[key] += delta
+	if st.quotaCounts[key] <= 0 {
+		delete(st.quotaCounts, key)
+	}
+}
+
+// recountKeys rebuilds the key counters from the enqueued elements. It must be called holding st.rwmutex.
+func (st *FIFO) recountKeys() {
+	if st.quotaKeyFn == nil {
+		return
+	}
+
+	st.quotaCounts = make(map[string]int)
+	for _, value := range st.slice {
+		st.trackKey(value, 1)
+	}
+}
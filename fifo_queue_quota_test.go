@@ -0,0 +1,64 @@
+package goconcurrentqueue
+
+import (
+	"strings"
+)
+
+// ***************************************************************************************
+// ** SetKeyQuota
+// ***************************************************************************************
+
+// tenant is the text before ":"
+func tenantKey(value interface{}) string {
+	return strings.SplitN(value.(string), ":", 2)[0]
+}
+
+// enqueue up to max elements per key
+func (suite *FIFOTestSuite) TestKeyQuotaSingleGR() {
+	suite.fifo.SetKeyQuota(tenantKey, 2)
+
+	suite.NoError(suite.fifo.Enqueue("a:1"), "Unexpected error")
+	suite.NoError(suite.fifo.Enqueue("a:2"), "Unexpected error")
+	suite.NoError(suite.fifo.Enqueue("b:1"), "Unexpected error")
+	suite.Error(suite.fifo.Enqueue("a:3"), "error expected once the key's quota is exceeded")
+
+	enqueued, err := suite.fifo.EnqueueUnique("a:4")
+	suite.Error(err, "error expected once the key's quota is exceeded")
+	suite.False(enqueued, "The element must not be enqueued")
+
+	// dequeue releases quota
+	suite.fifo.Dequeue()
+	suite.NoError(suite.fifo.Enqueue("a:3"), "Unexpected error")
+	suite.Equal(map[string]int{"a": 2, "b": 1}, suite.fifo.quotaCounts, "Unexpected counters")
+}
+
+// counters are updated by every removal
+func (suite *FIFOTestSuite) TestKeyQuotaRemovalsSingleGR() {
+	suite.fifo.SetKeyQuota(tenantKey, 10)
+	for _, value := range []string{"a:1", "b:1", "a:2", "c:1", "c:2"} {
+		suite.fifo.Enqueue(value)
+	}
+
+	suite.fifo.Remove(0)
+	suite.fifo.DequeueWhere(func(v interface{}) bool { return v == "b:1" })
+	suite.fifo.DrainTail(1)
+	suite.Equal(map[string]int{"a": 1, "c": 1}, suite.fifo.quotaCounts, "Unexpected counters")
+
+	other := NewFIFO()
+	other.Enqueue("d:1")
+	Swap(suite.fifo, other)
+	suite.Equal(map[string]int{"d": 1}, suite.fifo.quotaCounts, "Unexpected counters after Swap")
+}
+
+// setting the quota counts the already enqueued elements, disabling it removes the counters
+func (suite *FIFOTestSuite) TestKeyQuotaSetSingleGR() {
+	suite.fifo.Enqueue("a:1")
+	suite.fifo.Enqueue("a:2")
+
+	suite.fifo.SetKeyQuota(tenantKey, 2)
+	suite.Error(suite.fifo.Enqueue("a:3"), "The already enqueued elements must be counted")
+
+	suite.fifo.SetKeyQuota(nil, 0)
+	suite.Nil(suite.fifo.quotaCounts, "No counters expected once the quota is disabled")
+	suite.NoError(suite.fifo.Enqueue("a:3"), "Unexpected error")
+}
@@ -0,0 +1,86 @@
+package goconcurrentqueue
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Recorded operations, see OpRecord
+const (
+	OpEnqueue = "enqueue"
+	OpDequeue = "dequeue"
+)
+
+// OpRecord is an operation recorded by FIFO.StartRecording
+type OpRecord struct {
+	// OpEnqueue or OpDequeue
+	Op    string
+	Value interface{}
+	Time  time.Time
+	// goroutine that executed the operation
+	GoroutineID uint64
+}
+
+// StartRecording starts recording (in memory) every enqueue/dequeue operation, in the exact order they take place (see
+// OperationLog). Previous records are discarded. The records are kept until the next StartRecording, so the memory grows
+// while recording. Not recording costs a bool check per operation.
+func (st *FIFO) StartRecording() {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.recording = true
+	st.operationLog = make([]OpRecord, 0)
+}
+
+// StopRecording stops recording the operations, the records are kept (see OperationLog)
+func (st *FIFO) StopRecording() {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.recording = false
+}
+
+// OperationLog returns the recorded operations, from the oldest to the newest (see StartRecording)
+func (st *FIFO) OperationLog() []OpRecord {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	ret := make([]OpRecord, len(st.operationLog))
+	copy(ret, st.operationLog)
+
+	return ret
+}
+
+// record records an operation (if recording). It must be called holding st.rwmutex.
+func (st *FIFO) record(op string, value interface{}) {
+	if !st.recording {
+		return
+	}
+
+	st.operationLog = append(st.operationLog, OpRecord{
+		Op:          op,
+		Value:       value,
+		Time:        st.now(),
+		GoroutineID: goroutineID(),
+	})
+}
+
+// goroutineID returns the current goroutine's id, parsed from the stack trace header ("goroutine N [...").
+// It returns 0 if the id could not be parsed.
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i > 0 {
+		header = header[:i]
+	}
+
+	id, err := strconv.ParseUint(string(header), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
@@ -0,0 +1,69 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+)
+
+// ***************************************************************************************
+// ** StartRecording / OperationLog / StopRecording
+// ***************************************************************************************
+
+// operations get recorded while recording
+func (suite *FIFOTestSuite) TestRecordingSingleGR() {
+	clock := newFakeClock()
+	suite.fifo.SetClock(clock)
+	suite.fifo.Enqueue(0)
+
+	suite.fifo.StartRecording()
+	suite.fifo.Enqueue(1)
+	suite.fifo.Dequeue()
+	suite.fifo.StopRecording()
+	suite.fifo.Enqueue(2)
+
+	log := suite.fifo.OperationLog()
+	suite.Len(log, 2, "Only the operations while recording must be recorded")
+	suite.Equal(OpEnqueue, log[0].Op, "Wrong operation")
+	suite.Equal(1, log[0].Value, "Wrong element's value")
+	suite.Equal(OpDequeue, log[1].Op, "Wrong operation")
+	suite.Equal(0, log[1].Value, "Wrong element's value")
+	suite.Equal(clock.Now(), log[1].Time, "The time must be taken from the clock")
+	suite.Equal(goroutineID(), log[0].GoroutineID, "Wrong goroutine id")
+	suite.NotEqual(uint64(0), log[0].GoroutineID, "The goroutine id must be parsed")
+
+	// a new recording discards the previous records
+	suite.fifo.StartRecording()
+	suite.Len(suite.fifo.OperationLog(), 0, "No records expected")
+}
+
+// the goroutine ids of concurrent operations
+func (suite *FIFOTestSuite) TestRecordingMultipleGRs() {
+	var (
+		totalGRs = 20
+		wg       sync.WaitGroup
+		ids      = make(chan uint64, totalGRs)
+	)
+	suite.fifo.StartRecording()
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			ids <- goroutineID()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	expected := make(map[uint64]struct{})
+	for id := range ids {
+		expected[id] = struct{}{}
+	}
+
+	log := suite.fifo.OperationLog()
+	suite.Len(log, totalGRs, "Every operation must be recorded")
+	for _, record := range log {
+		_, ok := expected[record.GoroutineID]
+		suite.True(ok, "Unexpected goroutine id")
+	}
+}
@@ -0,0 +1,74 @@
+package goconcurrentqueue
+
+import (
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Reasons to reject an enqueue, see FIFO.RejectionStats and FixedFIFO.RejectionStats
+const (
+	RejectionReasonFull       = "full"
+	RejectionReasonLocked     = "locked"
+	RejectionReasonClosed     = "closed"
+	RejectionReasonTooLarge   = "too_large"
+	RejectionReasonValidation = "validation"
+	RejectionReasonDuplicate  = "duplicate"
+	RejectionReasonQuota      = "quota"
+)
+
+// rejection reasons' indexes at FIFO.rejections
+const (
+	rejectionLocked = iota
+	rejectionTooLarge
+	rejectionValidation
+	rejectionDuplicate
+	rejectionQuota
+	rejectionReasonCount
+)
+
+// rejectionReasons holds FIFO's RejectionReason... constants by index
+var rejectionReasons = [rejectionReasonCount]string{
+	RejectionReasonLocked,
+	RejectionReasonTooLarge,
+	RejectionReasonValidation,
+	RejectionReasonDuplicate,
+	RejectionReasonQuota,
+}
+
+// RejectionStats returns the number of rejected enqueues by reason (every reason listed below is included, even if
+// 0), e.g. to tell the quota pressure from the invalid elements:
+//   - RejectionReasonLocked: the queue was locked, see Lock
+//   - RejectionReasonTooLarge: the element was too large, see SetMaxElementSize
+//   - RejectionReasonValidation: the enqueue transform failed, see SetEnqueueTransform
+//   - RejectionReasonDuplicate: the element was not enqueued by EnqueueUnique, EnqueueIdempotent, EnqueueDebounced or
+//     EnqueueIfTailDiffers
+//   - RejectionReasonQuota: the element's key had no quota left, see SetKeyQuota
+//
+// The counters are updated atomically, so the stats are not a snapshot taken at once.
+func (st *FIFO) RejectionStats() map[string]uint64 {
+	ret := make(map[string]uint64, rejectionReasonCount)
+	for i, reason := range rejectionReasons {
+		ret[reason] = atomic.LoadUint64(&st.rejections[i])
+	}
+
+	return ret
+}
+
+// ResetRejectionStats resets the rejected enqueues counters, see RejectionStats
+func (st *FIFO) ResetRejectionStats() {
+	for i := range st.rejections {
+		atomic.StoreUint64(&st.rejections[i], 0)
+	}
+}
+
+// countRejection keeps track of a rejected enqueue, see RejectionStats
+func (st *FIFO) countRejection(reason int) {
+	atomic.AddUint64(&st.rejections[reason], 1)
+}
+
+// rejectLocked keeps track of an enqueue rejected because the queue is locked, returning its error
+func (st *FIFO) rejectLocked() error {
+	st.countRejection(rejectionLocked)
+	return errors.New("The queue is locked")
+}
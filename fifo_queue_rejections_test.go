@@ -0,0 +1,80 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ***************************************************************************************
+// ** RejectionStats / ResetRejectionStats
+// ***************************************************************************************
+
+// every rejected enqueue gets counted by reason
+func (suite *FIFOTestSuite) TestRejectionStatsSingleGR() {
+	suite.Equal(map[string]uint64{
+		RejectionReasonLocked:     0,
+		RejectionReasonTooLarge:   0,
+		RejectionReasonValidation: 0,
+		RejectionReasonDuplicate:  0,
+		RejectionReasonQuota:      0,
+	}, suite.fifo.RejectionStats(), "Every reason must be included")
+
+	suite.fifo.SetKeyQuota(func(value interface{}) string { return "key" }, 1)
+	suite.fifo.Enqueue("a")
+	suite.fifo.Enqueue("b")
+	suite.fifo.EnqueueBatch([]interface{}{"c"})
+	suite.fifo.SetKeyQuota(nil, 0)
+
+	suite.fifo.SetMaxElementSize(1, func(value interface{}) int { return len(value.(string)) })
+	suite.fifo.Enqueue("long")
+	suite.fifo.SetMaxElementSize(0, nil)
+
+	suite.fifo.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		if value == "invalid" {
+			return nil, fmt.Errorf("invalid")
+		}
+		return value, nil
+	})
+	suite.fifo.Enqueue("invalid")
+
+	suite.fifo.EnqueueUnique("a")
+	suite.fifo.EnqueueIfTailDiffers("a")
+	suite.fifo.EnqueueIdempotent("d", "d")
+	suite.fifo.EnqueueIdempotent("d", "d")
+
+	suite.fifo.Lock()
+	suite.fifo.Enqueue("e")
+	suite.fifo.EnqueueAt(0, "e")
+
+	suite.Equal(map[string]uint64{
+		RejectionReasonLocked:     2,
+		RejectionReasonTooLarge:   1,
+		RejectionReasonValidation: 1,
+		RejectionReasonDuplicate:  3,
+		RejectionReasonQuota:      2,
+	}, suite.fifo.RejectionStats(), "Wrong rejection stats")
+
+	suite.fifo.ResetRejectionStats()
+	for reason, count := range suite.fifo.RejectionStats() {
+		suite.Equal(uint64(0), count, "The %v counter must be reset", reason)
+	}
+}
+
+// concurrent rejections
+func (suite *FIFOTestSuite) TestRejectionStatsMultipleGRs() {
+	suite.fifo.Lock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				suite.fifo.Enqueue(j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(uint64(200), suite.fifo.RejectionStats()[RejectionReasonLocked], "Every rejection must be counted")
+}
@@ -0,0 +1,89 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+)
+
+// SequenceGapError is returned by FIFO.Dequeue when the head element's sequence is not the expected one, see
+// FIFO.SetExpectedSequence. Its code is QueueErrorCodeSequenceGap.
+type SequenceGapError struct {
+	*QueueError
+	// expected sequence && head element's sequence
+	Expected uint64
+	Actual   uint64
+}
+
+func newSequenceGapError(expected, actual uint64) *SequenceGapError {
+	return &SequenceGapError{
+		QueueError: NewQueueError(QueueErrorCodeSequenceGap, fmt.Sprintf("sequence gap: expected %v, got %v", expected, actual)),
+		Expected:   expected,
+		Actual:     actual,
+	}
+}
+
+// SetExpectedSequence makes Dequeue verify the sequence of the elements (seqOf(element)): the head element gets
+// dequeued only if its sequence is next, a *SequenceGapError is returned otherwise (and the element is kept at the
+// queue). Every dequeued element makes its sequence + 1 the next expected one. Only Dequeue checks the sequence and it
+// must not be combined with the dequeue shuffle. A nil seqOf disables the verification (default).
+func (st *FIFO) SetExpectedSequence(next uint64, seqOf func(interface{}) uint64) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.expectedSeq = next
+	st.seqOf = seqOf
+}
+
+// checkSequence returns a *SequenceGapError if the head element's sequence is not the expected one. It must be called
+// holding st.rwmutex.
+func (st *FIFO) checkSequence() error {
+	if st.seqOf == nil || len(st.slice) == 0 {
+		return nil
+	}
+
+	if seq := st.seqOf(st.slice[0]); seq != st.expectedSeq {
+		return newSequenceGapError(st.expectedSeq, seq)
+	}
+
+	return nil
+}
+
+// advanceSequence makes the next sequence after the dequeued element's one the expected one. It must be called
+// holding st.rwmutex.
+func (st *FIFO) advanceSequence(dequeued interface{}) {
+	if st.seqOf != nil {
+		st.expectedSeq = st.seqOf(dequeued) + 1
+	}
+}
+
+// EnqueueBatchSeq enqueues all the given elements under a single lock (none of them gets enqueued if any of them fails:
+// enqueue transform, key quota, ...), returning the sequence number assigned to the first one, the rest of them get
+// the consecutive ones. Every element enqueued into the queue (by any enqueue method) takes the next sequence number,
+// starting at 0. An empty batch returns the sequence number to be assigned to the next element.
+func (st *FIFO) EnqueueBatchSeq(values []interface{}) (startSeq uint64, err error) {
+	if st.isLocked {
+		return 0, st.rejectLocked()
+	}
+
+	transformed := make([]interface{}, len(values))
+	for i, value := range values {
+		if transformed[i], err = st.transformEnqueued(value); err != nil {
+			return 0, fmt.Errorf("element %v could not be enqueued: %v", i, err)
+		}
+	}
+
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if err := st.checkKeyQuotaBatch(transformed); err != nil {
+		return 0, err
+	}
+
+	startSeq = st.enqueueSeq
+	for _, value := range transformed {
+		// the quota was already checked for the whole batch
+		st.enqueue(value)
+	}
+
+	return startSeq, nil
+}
@@ -0,0 +1,176 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"sync"
+)
+
+// ***************************************************************************************
+// ** SetExpectedSequence
+// ***************************************************************************************
+
+type sequenced struct {
+	seq uint64
+}
+
+func seqOf(value interface{}) uint64 {
+	return value.(sequenced).seq
+}
+
+// elements in sequence get dequeued, a gap returns an error keeping the element
+func (suite *FIFOTestSuite) TestExpectedSequenceSingleGR() {
+	suite.fifo.SetExpectedSequence(1, seqOf)
+	for _, seq := range []uint64{1, 2, 4} {
+		suite.fifo.Enqueue(sequenced{seq})
+	}
+
+	for _, expected := range []uint64{1, 2} {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(sequenced{expected}, value, "Wrong element's value")
+	}
+
+	_, err := suite.fifo.Dequeue()
+	gapError, ok := err.(*SequenceGapError)
+	suite.True(ok, "A *SequenceGapError is expected")
+	if ok {
+		suite.Equal(QueueErrorCodeSequenceGap, gapError.Code(), "Wrong error's code")
+		suite.Equal(uint64(3), gapError.Expected, "Wrong expected sequence")
+		suite.Equal(uint64(4), gapError.Actual, "Wrong actual sequence")
+	}
+	suite.Equal(1, suite.fifo.GetLen(), "The element must be kept at the queue")
+
+	// the consumer handles the gap
+	suite.fifo.SetExpectedSequence(4, seqOf)
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(sequenced{4}, value, "Wrong element's value")
+}
+
+// disabled verification
+func (suite *FIFOTestSuite) TestExpectedSequenceDisabledSingleGR() {
+	suite.fifo.SetExpectedSequence(1, seqOf)
+	suite.fifo.Enqueue(sequenced{5})
+	suite.fifo.SetExpectedSequence(0, nil)
+
+	_, err := suite.fifo.Dequeue()
+	suite.NoError(err, "No verification expected once disabled")
+}
+
+// concurrent consumers dequeue every element in sequence
+func (suite *FIFOTestSuite) TestExpectedSequenceMultipleGRs() {
+	var (
+		totalGRs   = 10
+		totalItems = 100
+		wg         sync.WaitGroup
+	)
+	suite.fifo.SetExpectedSequence(0, seqOf)
+	for i := 0; i < totalItems; i++ {
+		suite.fifo.Enqueue(sequenced{uint64(i)})
+	}
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := suite.fifo.Dequeue(); err != nil {
+					_, isGap := err.(*SequenceGapError)
+					suite.False(isGap, "No gap expected")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(0, suite.fifo.GetLen(), "Every element must be dequeued")
+}
+
+// ***************************************************************************************
+// ** EnqueueBatchSeq
+// ***************************************************************************************
+
+// single EnqueueBatchSeq lock verification
+func (suite *FIFOTestSuite) TestEnqueueBatchSeqLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.EnqueueBatchSeq([]interface{}{1})
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+}
+
+// consecutive sequences
+func (suite *FIFOTestSuite) TestEnqueueBatchSeqSingleGR() {
+	startSeq, err := suite.fifo.EnqueueBatchSeq([]interface{}{0, 1, 2})
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(uint64(0), startSeq, "Wrong first sequence")
+
+	suite.fifo.Enqueue(3)
+	startSeq, err = suite.fifo.EnqueueBatchSeq([]interface{}{4, 5})
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(uint64(4), startSeq, "Every enqueued element takes a sequence")
+
+	startSeq, _ = suite.fifo.EnqueueBatchSeq(nil)
+	suite.Equal(uint64(6), startSeq, "An empty batch returns the next sequence")
+	for i := 0; i < 6; i++ {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(i, value, "Wrong element's value")
+	}
+}
+
+// all or nothing
+func (suite *FIFOTestSuite) TestEnqueueBatchSeqAllOrNothingSingleGR() {
+	suite.fifo.SetKeyQuota(func(value interface{}) string { return "key" }, 2)
+	suite.fifo.Enqueue(0)
+
+	_, err := suite.fifo.EnqueueBatchSeq([]interface{}{1, 2})
+	suite.Error(err, "error expected exceeding the key quota")
+	suite.Equal(1, suite.fifo.GetLen(), "No element of the batch must be enqueued")
+
+	suite.fifo.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		if value == nil {
+			return nil, errors.New("invalid element")
+		}
+		return value, nil
+	})
+	_, err = suite.fifo.EnqueueBatchSeq([]interface{}{nil})
+	suite.Error(err, "error expected from the enqueue transform")
+
+	startSeq, err := suite.fifo.EnqueueBatchSeq([]interface{}{1})
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(uint64(1), startSeq, "The failed batches must not take sequences")
+}
+
+// concurrent batches get contiguous ranges
+func (suite *FIFOTestSuite) TestEnqueueBatchSeqMultipleGRs() {
+	var (
+		totalGRs  = 10
+		batchSize = 5
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		starts    = make(map[uint64]int)
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(gr int) {
+			defer wg.Done()
+			batch := make([]interface{}, batchSize)
+			for c := range batch {
+				batch[c] = gr
+			}
+			startSeq, _ := suite.fifo.EnqueueBatchSeq(batch)
+			mutex.Lock()
+			starts[startSeq] = gr
+			mutex.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	// the elements of every batch are at [startSeq, startSeq+batchSize)
+	for startSeq, gr := range starts {
+		for c := 0; c < batchSize; c++ {
+			value, _ := suite.fifo.Get(int(startSeq) + c)
+			suite.Equal(gr, value, "The batch's elements must be contiguous")
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package goconcurrentqueue
+
+import (
+	"time"
+)
+
+// SetStarvationThreshold starts a monitor calling onStarve(age) whenever the head element has been at the queue for
+// longer than d, so the consumers falling behind get noticed. It fires at most once every d (measured by the queue's
+// clock, see SetClock) while the head element stays too old; once the head gets younger than d (or the queue gets
+// empty) the next starvation fires as soon as it gets detected. onStarve runs at the monitor's goroutine.
+//
+// It requires timestamp tracking (see SetTimestampTracking), no starvation is detected without it. The monitor is a
+// goroutine waking up every d/2 (real time) to read the head element's enqueue time, holding the queue's read lock for
+// that. Calling it again replaces the previous monitor; d <= 0 or a nil onStarve stops it.
+func (st *FIFO) SetStarvationThreshold(d time.Duration, onStarve func(age time.Duration)) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.starvationStop != nil {
+		close(st.starvationStop)
+		st.starvationStop = nil
+	}
+
+	if d <= 0 || onStarve == nil {
+		return
+	}
+
+	st.starvationStop = make(chan struct{})
+	go st.monitorStarvation(d, onStarve, st.starvationStop)
+}
+
+// monitorStarvation calls onStarve whenever the head element is older than d (see SetStarvationThreshold), until stop
+// gets closed
+func (st *FIFO) monitorStarvation(d time.Duration, onStarve func(age time.Duration), stop chan struct{}) {
+	interval := d / 2
+	if interval <= 0 {
+		interval = d
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// last time onStarve was called (zero == since the head got younger than d)
+	var lastFired time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		age, now, running, ok := st.headAge(stop)
+		if !running {
+			return
+		}
+		if !ok || age <= d {
+			lastFired = time.Time{}
+			continue
+		}
+
+		if lastFired.IsZero() || now.Sub(lastFired) >= d {
+			lastFired = now
+			onStarve(age)
+		}
+	}
+}
+
+// headAge returns the time the head element has been at the queue and the current time, ok == false if the queue is
+// empty or its enqueue time is unknown. running == false if the monitor identified by stop was replaced or stopped
+// (see SetStarvationThreshold).
+func (st *FIFO) headAge(stop chan struct{}) (age time.Duration, now time.Time, running, ok bool) {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if st.starvationStop != stop {
+		return 0, time.Time{}, false, false
+	}
+
+	if len(st.meta) == 0 || st.meta[0].enqueuedAt.IsZero() {
+		return 0, time.Time{}, true, false
+	}
+
+	now = st.now()
+	return now.Sub(st.meta[0].enqueuedAt), now, true, true
+}
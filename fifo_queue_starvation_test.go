@@ -0,0 +1,73 @@
+package goconcurrentqueue
+
+import (
+	"time"
+)
+
+// ***************************************************************************************
+// ** SetStarvationThreshold
+// ***************************************************************************************
+
+// waitForStarvation returns the next age reported to alerts, or -1 if nothing gets reported within wait
+func waitForStarvation(alerts chan time.Duration, wait time.Duration) time.Duration {
+	select {
+	case age := <-alerts:
+		return age
+	case <-time.After(wait):
+		return -1
+	}
+}
+
+// alerts fire once per threshold period while the head is too old
+func (suite *FIFOTestSuite) TestStarvationThresholdSingleGR() {
+	var (
+		clock     = newFakeClock()
+		alerts    = make(chan time.Duration, 100)
+		threshold = 10 * time.Millisecond
+	)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetTimestampTracking(true)
+	suite.fifo.SetStarvationThreshold(threshold, func(age time.Duration) { alerts <- age })
+	defer suite.fifo.SetStarvationThreshold(0, nil)
+
+	suite.fifo.Enqueue(1)
+	suite.Equal(time.Duration(-1), waitForStarvation(alerts, 5*threshold), "No alert expected for a young head")
+
+	clock.Advance(time.Minute)
+	suite.Equal(time.Minute, waitForStarvation(alerts, time.Second), "An alert is expected for an old head")
+	// the clock does not move: throttled
+	suite.Equal(time.Duration(-1), waitForStarvation(alerts, 5*threshold), "Alerts must be throttled")
+
+	clock.Advance(threshold)
+	suite.Equal(time.Minute+threshold, waitForStarvation(alerts, time.Second), "An alert is expected once per period")
+
+	// drained
+	suite.fifo.Dequeue()
+	clock.Advance(time.Minute)
+	suite.Equal(time.Duration(-1), waitForStarvation(alerts, 5*threshold), "No alert expected for an empty queue")
+
+	// a new old head fires at once
+	suite.fifo.Enqueue(2)
+	clock.Advance(time.Minute)
+	suite.Equal(time.Minute, waitForStarvation(alerts, time.Second), "An alert is expected for an old head")
+}
+
+// no alerts without timestamp tracking or once stopped
+func (suite *FIFOTestSuite) TestStarvationThresholdDisabledSingleGR() {
+	var (
+		clock     = newFakeClock()
+		alerts    = make(chan time.Duration, 100)
+		threshold = 10 * time.Millisecond
+	)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetStarvationThreshold(threshold, func(age time.Duration) { alerts <- age })
+
+	suite.fifo.Enqueue(1)
+	clock.Advance(time.Minute)
+	suite.Equal(time.Duration(-1), waitForStarvation(alerts, 5*threshold), "No alert expected without timestamp tracking")
+
+	suite.fifo.SetStarvationThreshold(0, nil)
+	suite.fifo.SetTimestampTracking(true)
+	clock.Advance(time.Minute)
+	suite.Equal(time.Duration(-1), waitForStarvation(alerts, 5*threshold), "No alert expected once stopped")
+}
@@ -1,8 +1,13 @@
 package goconcurrentqueue
 
 import (
+	"context"
+	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -397,6 +402,2638 @@ func (suite *FIFOTestSuite) TestIsLockedSingleGR() {
 	suite.True(suite.fifo.isLocked == suite.fifo.IsLocked(), "fifo.IsLocked() has to be equal to fifo.isLocked")
 }
 
+// ***************************************************************************************
+// ** DequeueWhere
+// ***************************************************************************************
+
+// single DequeueWhere lock verification
+func (suite *FIFOTestSuite) TestDequeueWhereLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := suite.fifo.DequeueWhere(func(interface{}) bool { return true })
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// dequeue the first matching element, keeping the order of the rest
+func (suite *FIFOTestSuite) TestDequeueWhereSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	val, err := suite.fifo.DequeueWhere(func(v interface{}) bool { return v.(int)%2 == 1 })
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, val, "Wrong element's value")
+	suite.Equal(4, suite.fifo.GetLen(), "Incorrect number of queue elements")
+
+	for _, expected := range []int{0, 2, 3, 4} {
+		val, err = suite.fifo.Dequeue()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(expected, val, "The order of the remaining elements must be preserved")
+	}
+}
+
+// no element matches the predicate
+func (suite *FIFOTestSuite) TestDequeueWhereNotFoundSingleGR() {
+	suite.fifo.Enqueue(1)
+
+	val, err := suite.fifo.DequeueWhere(func(v interface{}) bool { return v.(int) > 1 })
+	suite.Error(err, "An error is expected if no element matches the predicate")
+	suite.Nil(val, "nil value expected if no element matches the predicate")
+	suite.Equal(1, suite.fifo.GetLen(), "No element should be removed")
+}
+
+// ***************************************************************************************
+// ** SetHistorySize / History
+// ***************************************************************************************
+
+// history is disabled by default
+func (suite *FIFOTestSuite) TestHistoryDisabledSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Dequeue()
+	suite.Len(suite.fifo.History(), 0, "History must be disabled by default")
+}
+
+// only the latest n dequeued elements are kept
+func (suite *FIFOTestSuite) TestHistorySingleGR() {
+	suite.fifo.SetHistorySize(3)
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	for i := 0; i < 4; i++ {
+		suite.fifo.Dequeue()
+	}
+	suite.fifo.DequeueWhere(func(interface{}) bool { return true })
+
+	suite.Equal([]interface{}{2, 3, 4}, suite.fifo.History(), "Unexpected history")
+	suite.Equal(0, suite.fifo.GetLen(), "History must not affect the queue")
+}
+
+// resizing the history keeps the latest recorded elements
+func (suite *FIFOTestSuite) TestHistoryResizeSingleGR() {
+	suite.fifo.SetHistorySize(3)
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+		suite.fifo.Dequeue()
+	}
+
+	suite.fifo.SetHistorySize(2)
+	suite.Equal([]interface{}{1, 2}, suite.fifo.History(), "Unexpected history after resize")
+
+	suite.fifo.SetHistorySize(0)
+	suite.Len(suite.fifo.History(), 0, "History must be disabled after SetHistorySize(0)")
+}
+
+// ***************************************************************************************
+// ** DrainTail
+// ***************************************************************************************
+
+// single DrainTail lock verification
+func (suite *FIFOTestSuite) TestDrainTailLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := suite.fifo.DrainTail(1)
+	suite.Error(err, "Locked queue does not allow to drain elements")
+}
+
+// drain the most recent elements
+func (suite *FIFOTestSuite) TestDrainTailSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	elements, err := suite.fifo.DrainTail(2)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]interface{}{3, 4}, elements, "The most recent elements are expected, in enqueue order")
+	suite.Equal(3, suite.fifo.GetLen(), "The older elements must be kept at the queue")
+
+	// new elements must not overwrite the drained ones
+	suite.fifo.Enqueue(10)
+	suite.Equal([]interface{}{3, 4}, elements, "Drained elements must not be modified by later enqueues")
+
+	val, _ := suite.fifo.Dequeue()
+	suite.Equal(0, val, "Wrong element's value")
+}
+
+// drain more elements than the enqueued ones
+func (suite *FIFOTestSuite) TestDrainTailMoreThanLenSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	elements, err := suite.fifo.DrainTail(10)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]interface{}{1, 2}, elements, "All elements are expected")
+	suite.Equal(0, suite.fifo.GetLen(), "The queue must be empty")
+
+	_, err = suite.fifo.DrainTail(-1)
+	suite.Error(err, "A negative number of elements is not allowed")
+}
+
+// ***************************************************************************************
+// ** Compact
+// ***************************************************************************************
+
+// sums adjacent elements having the same sign
+func sameSignMerge(a, b interface{}) (interface{}, bool) {
+	x, y := a.(int), b.(int)
+	if (x < 0) == (y < 0) {
+		return x + y, true
+	}
+	return nil, false
+}
+
+// single Compact lock verification
+func (suite *FIFOTestSuite) TestCompactLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.Compact(sameSignMerge), "Locked queue does not allow to compact elements")
+}
+
+// merge adjacent elements
+func (suite *FIFOTestSuite) TestCompactSingleGR() {
+	for _, v := range []int{1, 2, -1, -2, -3, 4} {
+		suite.fifo.Enqueue(v)
+	}
+
+	suite.NoError(suite.fifo.Compact(sameSignMerge), "Unexpected error")
+	suite.Equal(3, suite.fifo.GetLen(), "Unexpected number of elements after Compact")
+	for _, expected := range []int{3, -6, 4} {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(expected, val, "Wrong element's value")
+	}
+}
+
+// merging is repeated until no more merges could be done
+func (suite *FIFOTestSuite) TestCompactCascadeSingleGR() {
+	for _, v := range []int{5, 1, 4} {
+		suite.fifo.Enqueue(v)
+	}
+
+	// merges a, b if a <= b
+	suite.NoError(suite.fifo.Compact(func(a, b interface{}) (interface{}, bool) {
+		x, y := a.(int), b.(int)
+		if x <= y {
+			return x + y, true
+		}
+		return nil, false
+	}), "Unexpected error")
+
+	// 5,1 => no merge ; 1,4 => 5 ; 5,5 => 10
+	suite.Equal(1, suite.fifo.GetLen(), "Unexpected number of elements after Compact")
+	val, _ := suite.fifo.Dequeue()
+	suite.Equal(10, val, "Wrong element's value")
+}
+
+// ***************************************************************************************
+// ** SetTimestampTracking / DequeueWithMeta
+// ***************************************************************************************
+
+// single DequeueWithMeta lock verification
+func (suite *FIFOTestSuite) TestDequeueWithMetaLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, _, err := suite.fifo.DequeueWithMeta()
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// dequeue an empty queue
+func (suite *FIFOTestSuite) TestDequeueWithMetaEmptyQueueSingleGR() {
+	val, meta, err := suite.fifo.DequeueWithMeta()
+	suite.Error(err, "Can't dequeue an empty queue")
+	suite.Nil(val, "Can't get a value different than nil from an empty queue")
+	suite.Equal(DequeueMeta{}, meta, "Zero meta expected from an empty queue")
+}
+
+// time in queue is 0 while timestamp tracking is disabled
+func (suite *FIFOTestSuite) TestDequeueWithMetaNoTimestampsSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	val, meta, err := suite.fifo.DequeueWithMeta()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, val, "Wrong element's value")
+	suite.Equal(1, meta.Len, "Unexpected length after dequeue")
+	suite.Equal(time.Duration(0), meta.TimeInQueue, "No time in queue expected while timestamp tracking is disabled")
+}
+
+// time in queue while timestamp tracking is enabled
+func (suite *FIFOTestSuite) TestDequeueWithMetaTimestampsSingleGR() {
+	// enqueued before the tracking gets enabled
+	suite.fifo.Enqueue(1)
+	suite.fifo.SetTimestampTracking(true)
+	suite.fifo.Enqueue(2)
+	suite.fifo.Enqueue(3)
+	suite.fifo.Remove(1)
+	time.Sleep(10 * time.Millisecond)
+
+	for _, expected := range []int{1, 3} {
+		val, meta, err := suite.fifo.DequeueWithMeta()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(expected, val, "Wrong element's value")
+		suite.True(meta.TimeInQueue >= 10*time.Millisecond, "Unexpected time in queue: %v", meta.TimeInQueue)
+	}
+	suite.Len(suite.fifo.meta, 0, "Metadata must be removed along with the elements")
+
+	suite.fifo.SetTimestampTracking(false)
+	suite.Nil(suite.fifo.meta, "No metadata expected once timestamp tracking gets disabled")
+}
+
+// metadata is kept in sync with the elements
+func (suite *FIFOTestSuite) TestTimestampTrackingMetaSyncSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for _, v := range []int{1, 2, -1, 5, 6, 7} {
+		suite.fifo.Enqueue(v)
+	}
+
+	suite.fifo.DrainTail(2)
+	suite.fifo.Compact(sameSignMerge)
+	suite.fifo.DequeueWhere(func(v interface{}) bool { return v.(int) < 0 })
+	suite.Equal(suite.fifo.GetLen(), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+}
+
+// ***************************************************************************************
+// ** Swap
+// ***************************************************************************************
+
+// single Swap lock verification
+func (suite *FIFOTestSuite) TestSwapLockSingleGR() {
+	other := NewFIFO()
+	suite.fifo.Lock()
+	suite.Error(Swap(suite.fifo, other), "Locked queue does not allow to swap elements")
+	suite.Error(Swap(other, suite.fifo), "Locked queue does not allow to swap elements")
+}
+
+// swap elements
+func (suite *FIFOTestSuite) TestSwapSingleGR() {
+	other := NewFIFO()
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	other.Enqueue(3)
+
+	suite.NoError(Swap(suite.fifo, other), "Unexpected error")
+	suite.Equal(1, suite.fifo.GetLen(), "Unexpected length after Swap")
+	suite.Equal(2, other.GetLen(), "Unexpected length after Swap")
+
+	val, _ := suite.fifo.Dequeue()
+	suite.Equal(3, val, "Wrong element's value")
+	val, _ = other.Dequeue()
+	suite.Equal(1, val, "Wrong element's value")
+
+	// swapping a queue with itself does nothing
+	suite.NoError(Swap(other, other), "Unexpected error")
+	suite.Equal(1, other.GetLen(), "Unexpected length after Swap")
+}
+
+// concurrent swaps in both directions must not deadlock
+func (suite *FIFOTestSuite) TestSwapMultipleGRs() {
+	var (
+		wg    sync.WaitGroup
+		other = NewFIFO()
+	)
+	suite.fifo.Enqueue(1)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Swap(suite.fifo, other)
+		}()
+		go func() {
+			defer wg.Done()
+			Swap(other, suite.fifo)
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(1, suite.fifo.GetLen()+other.GetLen(), "No element could be lost while swapping")
+}
+
+// ***************************************************************************************
+// ** SetDeadLetterHandler
+// ***************************************************************************************
+
+// the dead letter handler gets the discarded elements
+func (suite *FIFOTestSuite) TestDeadLetterHandlerSingleGR() {
+	var (
+		values  []interface{}
+		reasons []string
+	)
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		// the handler runs outside the lock
+		suite.fifo.GetLen()
+		values = append(values, value)
+		reasons = append(reasons, reason)
+	})
+
+	suite.fifo.discard(DeadLetterReasonExpired, 1, 2)
+	suite.Equal([]interface{}{1, 2}, values, "Unexpected discarded values")
+	suite.Equal([]string{DeadLetterReasonExpired, DeadLetterReasonExpired}, reasons, "Unexpected reasons")
+
+	// nil handler
+	suite.fifo.SetDeadLetterHandler(nil)
+	suite.fifo.discard(DeadLetterReasonExpired, 3)
+	suite.Len(values, 2, "A nil handler must not be called")
+}
+
+// ***************************************************************************************
+// ** EnqueueDebounced
+// ***************************************************************************************
+
+// single EnqueueDebounced lock verification
+func (suite *FIFOTestSuite) TestEnqueueDebouncedLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.EnqueueDebounced(1, "key", time.Second)
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+}
+
+// duplicated keys are rejected within the window
+func (suite *FIFOTestSuite) TestEnqueueDebouncedSingleGR() {
+	window := 20 * time.Millisecond
+
+	accepted, err := suite.fifo.EnqueueDebounced(1, "a", window)
+	suite.NoError(err, "Unexpected error")
+	suite.True(accepted, "The first element must be accepted")
+
+	accepted, _ = suite.fifo.EnqueueDebounced(2, "a", window)
+	suite.False(accepted, "An element having the same key must be rejected within the window")
+
+	accepted, _ = suite.fifo.EnqueueDebounced(3, "b", window)
+	suite.True(accepted, "An element having a different key must be accepted")
+
+	time.Sleep(window)
+	accepted, _ = suite.fifo.EnqueueDebounced(4, "a", window)
+	suite.True(accepted, "An element having the same key must be accepted after the window")
+
+	suite.Equal(3, suite.fifo.GetLen(), "Unexpected length")
+}
+
+// old keys are removed
+func (suite *FIFOTestSuite) TestEnqueueDebouncedCleanupSingleGR() {
+	window := 10 * time.Millisecond
+	for _, key := range []string{"a", "b", "c"} {
+		suite.fifo.EnqueueDebounced(1, key, window)
+	}
+
+	time.Sleep(window)
+	suite.fifo.EnqueueDebounced(1, "d", window)
+	suite.Len(suite.fifo.debounceLastSeen, 1, "Keys not seen within the window must be removed")
+}
+
+// ***************************************************************************************
+// ** Contains / EnqueueUnique
+// ***************************************************************************************
+
+// Contains uses == for regular values and Equals for Equaler values
+func (suite *FIFOTestSuite) TestContainsSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(caseInsensitive("Hello"))
+	suite.fifo.Enqueue([]int{1})
+
+	suite.True(suite.fifo.Contains(1), "The queue contains the element")
+	suite.False(suite.fifo.Contains(2), "The queue does not contain the element")
+	suite.True(suite.fifo.Contains(caseInsensitive("HELLO")), "Equals must be used for Equaler elements")
+	suite.False(suite.fifo.Contains([]int{1}), "Uncomparable elements are never equal")
+}
+
+// single EnqueueUnique lock verification
+func (suite *FIFOTestSuite) TestEnqueueUniqueLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.EnqueueUnique(1)
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+}
+
+// duplicated elements are not enqueued
+func (suite *FIFOTestSuite) TestEnqueueUniqueSingleGR() {
+	enqueued, err := suite.fifo.EnqueueUnique(caseInsensitive("Hello"))
+	suite.NoError(err, "Unexpected error")
+	suite.True(enqueued, "The element must be enqueued")
+
+	enqueued, _ = suite.fifo.EnqueueUnique(caseInsensitive("hello"))
+	suite.False(enqueued, "An equal element must not be enqueued")
+
+	enqueued, _ = suite.fifo.EnqueueUnique(caseInsensitive("world"))
+	suite.True(enqueued, "A different element must be enqueued")
+
+	suite.Equal(2, suite.fifo.GetLen(), "Unexpected length")
+}
+
+// ***************************************************************************************
+// ** SetDequeueTransform
+// ***************************************************************************************
+
+// dequeued elements get transformed
+func (suite *FIFOTestSuite) TestDequeueTransformSingleGR() {
+	suite.fifo.SetDequeueTransform(func(value interface{}) interface{} {
+		// the transformation runs outside the lock
+		suite.fifo.GetLen()
+		return value.(int) * 10
+	})
+	for i := 1; i <= 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	val, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(10, val, "The dequeued element must be transformed")
+
+	val, _, err = suite.fifo.DequeueWithMeta()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(20, val, "The dequeued element must be transformed")
+
+	// the predicate gets the stored element
+	val, err = suite.fifo.DequeueWhere(func(v interface{}) bool { return v.(int) == 3 })
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(30, val, "The dequeued element must be transformed")
+
+	// no transformation for an empty queue
+	_, err = suite.fifo.Dequeue()
+	suite.Error(err, "Can't dequeue an empty queue")
+}
+
+// a nil transform keeps the elements as they were enqueued
+func (suite *FIFOTestSuite) TestDequeueTransformNilSingleGR() {
+	suite.fifo.SetDequeueTransform(func(value interface{}) interface{} { return nil })
+	suite.fifo.SetDequeueTransform(nil)
+	suite.fifo.Enqueue(1)
+
+	val, _ := suite.fifo.Dequeue()
+	suite.Equal(1, val, "The dequeued element must not be transformed")
+}
+
+// ***************************************************************************************
+// ** SetEnqueueTransform
+// ***************************************************************************************
+
+// enqueued elements get transformed
+func (suite *FIFOTestSuite) TestEnqueueTransformSingleGR() {
+	var discarded []interface{}
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		suite.Equal(DeadLetterReasonValidation, reason, "Unexpected reason")
+		discarded = append(discarded, value)
+	})
+	// only positive numbers are accepted, stored multiplied by 10
+	suite.fifo.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		// the transformation runs outside the lock
+		suite.fifo.GetLen()
+		if value.(int) <= 0 {
+			return nil, fmt.Errorf("invalid value: %v", value)
+		}
+		return value.(int) * 10, nil
+	})
+
+	suite.NoError(suite.fifo.Enqueue(1), "Unexpected error")
+	suite.Error(suite.fifo.Enqueue(-1), "The transform error must be returned")
+	_, err := suite.fifo.EnqueueUnique(-2)
+	suite.Error(err, "The transform error must be returned")
+	_, err = suite.fifo.EnqueueDebounced(-3, "key", time.Second)
+	suite.Error(err, "The transform error must be returned")
+	suite.Equal([]interface{}{-1, -2, -3}, discarded, "Rejected elements must be discarded")
+
+	// uniqueness is checked against the transformed element
+	enqueued, err := suite.fifo.EnqueueUnique(1)
+	suite.NoError(err, "Unexpected error")
+	suite.False(enqueued, "An equal (transformed) element is already enqueued")
+
+	suite.Equal(1, suite.fifo.GetLen(), "Unexpected length")
+	val, _ := suite.fifo.Dequeue()
+	suite.Equal(10, val, "The transformed element must be stored")
+
+	// nil transform
+	suite.fifo.SetEnqueueTransform(nil)
+	suite.NoError(suite.fifo.Enqueue(-1), "Unexpected error")
+	val, _ = suite.fifo.Dequeue()
+	suite.Equal(-1, val, "The element must be stored as it is")
+}
+
+// ***************************************************************************************
+// ** WaitForValue
+// ***************************************************************************************
+
+// single WaitForValue lock verification
+func (suite *FIFOTestSuite) TestWaitForValueLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.WaitForValue(context.Background(), func(interface{}) bool { return true }, true)
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// unmatched elements are dropped
+func (suite *FIFOTestSuite) TestWaitForValueDropUnmatchedSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	val, err := suite.fifo.WaitForValue(context.Background(), func(v interface{}) bool { return v.(int) == 2 }, false)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, val, "Wrong element's value")
+	suite.Equal(2, suite.fifo.GetLen(), "Unmatched elements before the matching one must be dropped")
+
+	val, _ = suite.fifo.Dequeue()
+	suite.Equal(3, val, "Wrong element's value")
+}
+
+// dropped unmatched elements go to the dead letter handler
+func (suite *FIFOTestSuite) TestWaitForValueDeadLetterSingleGR() {
+	var dropped []interface{}
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		suite.Equal(DeadLetterReasonUnmatched, reason, "Unexpected reason")
+		dropped = append(dropped, value)
+	})
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	val, err := suite.fifo.WaitForValue(context.Background(), func(v interface{}) bool { return v.(int) == 2 }, false)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, val, "Wrong element's value")
+	suite.Equal([]interface{}{0, 1}, dropped, "The dropped elements must be discarded")
+}
+
+// unmatched elements are kept
+func (suite *FIFOTestSuite) TestWaitForValueKeepUnmatchedSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	val, err := suite.fifo.WaitForValue(context.Background(), func(v interface{}) bool { return v.(int) == 2 }, true)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, val, "Wrong element's value")
+	suite.Equal(4, suite.fifo.GetLen(), "Unmatched elements must be kept")
+}
+
+// context gets done while waiting
+func (suite *FIFOTestSuite) TestWaitForValueContextSingleGR() {
+	suite.fifo.Enqueue(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	val, err := suite.fifo.WaitForValue(ctx, func(v interface{}) bool { return v.(int) == 2 }, true)
+	suite.Error(err, "error expected once the context is done")
+	suite.Nil(val, "nil value expected once the context is done")
+	suite.Equal(1, suite.fifo.GetLen(), "Unmatched elements must be kept")
+}
+
+// wait for an element enqueued by other GR
+func (suite *FIFOTestSuite) TestWaitForValueMultipleGRs() {
+	go func() {
+		for i := 0; i < 5; i++ {
+			time.Sleep(time.Millisecond)
+			suite.fifo.Enqueue(i)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	val, err := suite.fifo.WaitForValue(ctx, func(v interface{}) bool { return v.(int) == 4 }, false)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(4, val, "Wrong element's value")
+	suite.Equal(0, suite.fifo.GetLen(), "Unmatched elements must be dropped")
+}
+
+// ***************************************************************************************
+// ** Promote
+// ***************************************************************************************
+
+// single Promote lock verification
+func (suite *FIFOTestSuite) TestPromoteLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Equal(0, suite.fifo.Promote(func(interface{}) bool { return true }), "Locked queue does not allow to promote elements")
+}
+
+// promote elements keeping their relative order
+func (suite *FIFOTestSuite) TestPromoteSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for i := 0; i < 6; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	total := suite.fifo.Promote(func(v interface{}) bool { return v.(int)%2 == 1 })
+	suite.Equal(3, total, "Unexpected number of promoted elements")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+
+	for _, expected := range []int{1, 3, 5, 0, 2, 4} {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(expected, val, "Wrong element's value")
+	}
+}
+
+// ***************************************************************************************
+// ** SetElementPool / SetElementReset / RecycleDequeued
+// ***************************************************************************************
+
+type pooledElement struct {
+	data []byte
+}
+
+// nothing is done without a pool
+func (suite *FIFOTestSuite) TestRecycleDequeuedNoPoolSingleGR() {
+	suite.NotPanics(func() { suite.fifo.RecycleDequeued(&pooledElement{}) }, "RecycleDequeued without a pool must do nothing")
+}
+
+// processed elements get reset and returned to the pool
+func (suite *FIFOTestSuite) TestRecycleDequeuedSingleGR() {
+	var (
+		reused = make(chan *pooledElement, 1)
+		pool   = &sync.Pool{}
+	)
+	suite.fifo.SetElementPool(pool)
+	suite.fifo.SetElementReset(func(value interface{}) {
+		element := value.(*pooledElement)
+		element.data = element.data[:0]
+		reused <- element
+	})
+
+	suite.fifo.Enqueue(&pooledElement{data: []byte("payload")})
+	val, _ := suite.fifo.Dequeue()
+	suite.fifo.RecycleDequeued(val)
+
+	element := <-reused
+	suite.True(element == val, "The dequeued element must be reset")
+	suite.Len(element.data, 0, "The element must be reset before being returned to the pool")
+}
+
+// ***************************************************************************************
+// ** PeekOrWaitForNextElement
+// ***************************************************************************************
+
+// single PeekOrWaitForNextElement lock verification
+func (suite *FIFOTestSuite) TestPeekOrWaitForNextElementLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.PeekOrWaitForNextElement(context.Background())
+	suite.Error(err, "Locked queue does not allow to get elements")
+}
+
+// peek keeps the element at the queue
+func (suite *FIFOTestSuite) TestPeekOrWaitForNextElementSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	val, err := suite.fifo.PeekOrWaitForNextElement(context.Background())
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, val, "Wrong element's value")
+	suite.Equal(2, suite.fifo.GetLen(), "The element must be kept at the queue")
+
+	val, _ = suite.fifo.Dequeue()
+	suite.Equal(1, val, "Dequeue must return the peeked element")
+}
+
+// context gets done while waiting
+func (suite *FIFOTestSuite) TestPeekOrWaitForNextElementContextSingleGR() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	val, err := suite.fifo.PeekOrWaitForNextElement(ctx)
+	suite.Error(err, "error expected once the context is done")
+	suite.Nil(val, "nil value expected once the context is done")
+}
+
+// wait for an element enqueued by other GR
+func (suite *FIFOTestSuite) TestPeekOrWaitForNextElementMultipleGRs() {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Enqueue(testValue)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	val, err := suite.fifo.PeekOrWaitForNextElement(ctx)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(testValue, val, "Wrong element's value")
+	suite.Equal(1, suite.fifo.GetLen(), "The element must be kept at the queue")
+}
+
+// ***************************************************************************************
+// ** PeekOrWaitForNextElementTimeout
+// ***************************************************************************************
+
+// no element gets enqueued before the timeout
+func (suite *FIFOTestSuite) TestPeekOrWaitForNextElementTimeoutSingleGR() {
+	val, err := suite.fifo.PeekOrWaitForNextElementTimeout(10 * time.Millisecond)
+	suite.Error(err, "timeout error expected")
+	suite.Nil(val, "nil value expected after the timeout")
+
+	suite.fifo.Enqueue(testValue)
+	val, err = suite.fifo.PeekOrWaitForNextElementTimeout(0)
+	suite.NoError(err, "An already enqueued element must be returned right away")
+	suite.Equal(testValue, val, "Wrong element's value")
+
+	suite.fifo.Lock()
+	_, err = suite.fifo.PeekOrWaitForNextElementTimeout(time.Second)
+	suite.Error(err, "The queue is locked")
+}
+
+// wait for an element enqueued by other GR
+func (suite *FIFOTestSuite) TestPeekOrWaitForNextElementTimeoutMultipleGRs() {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Enqueue(testValue)
+	}()
+
+	val, err := suite.fifo.PeekOrWaitForNextElementTimeout(time.Second)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(testValue, val, "Wrong element's value")
+	suite.Equal(1, suite.fifo.GetLen(), "The element must be kept at the queue")
+}
+
+// ***************************************************************************************
+// ** EnqueueBatch
+// ***************************************************************************************
+
+// single EnqueueBatch lock verification
+func (suite *FIFOTestSuite) TestEnqueueBatchLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.EnqueueBatch([]interface{}{1}), "Locked queue does not allow to enqueue elements")
+}
+
+// enqueue multiple elements
+func (suite *FIFOTestSuite) TestEnqueueBatchSingleGR() {
+	suite.NoError(suite.fifo.EnqueueBatch([]interface{}{1, 2, 3}), "Unexpected error")
+	for i := 1; i <= 3; i++ {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(i, val, "Wrong element's value")
+	}
+}
+
+// partial failures are reported by index
+func (suite *FIFOTestSuite) TestEnqueueBatchPartialFailureSingleGR() {
+	suite.fifo.SetKeyQuota(func(value interface{}) string { return "key" }, 3)
+	suite.fifo.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		if value.(int) < 0 {
+			return nil, fmt.Errorf("invalid value: %v", value)
+		}
+		return value, nil
+	})
+
+	err := suite.fifo.EnqueueBatch([]interface{}{1, -1, 2, 3, 4})
+	suite.Error(err, "error expected for partial failures")
+	batchError, ok := err.(*BatchError)
+	suite.True(ok, "*BatchError expected")
+	failed := batchError.Errors()
+	suite.Len(failed, 2, "Unexpected number of failed elements")
+	suite.Contains(failed, 1, "The element rejected by the transform must be reported")
+	suite.Contains(failed, 4, "The element rejected by the quota must be reported")
+
+	suite.Equal(3, suite.fifo.GetLen(), "The valid elements must be enqueued")
+}
+
+// ***************************************************************************************
+// ** SetDequeueShuffle
+// ***************************************************************************************
+
+// dequeue all the elements, returning them in dequeue order
+func dequeueAll(fifo *FIFO) []interface{} {
+	ret := make([]interface{}, 0)
+	for {
+		val, err := fifo.Dequeue()
+		if err != nil {
+			return ret
+		}
+		ret = append(ret, val)
+	}
+}
+
+// the same seed produces the same order
+func (suite *FIFOTestSuite) TestDequeueShuffleSingleGR() {
+	var orders [2][]interface{}
+	for i := range orders {
+		fifo := NewFIFO()
+		fifo.SetTimestampTracking(true)
+		fifo.SetDequeueShuffle(7)
+		for v := 0; v < 20; v++ {
+			fifo.Enqueue(v)
+		}
+
+		orders[i] = dequeueAll(fifo)
+		suite.Len(orders[i], 20, "Every element must be dequeued")
+		suite.Len(fifo.meta, 0, "Metadata must be kept in sync with the elements")
+	}
+
+	suite.Equal(orders[0], orders[1], "The same seed must produce the same order")
+	suite.NotEqual(orders[0], []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, "Elements must be dequeued out of order")
+}
+
+// disabling the shuffle restores the FIFO order
+func (suite *FIFOTestSuite) TestDisableDequeueShuffleSingleGR() {
+	suite.fifo.SetDequeueShuffle(7)
+	suite.fifo.DisableDequeueShuffle()
+	for v := 0; v < 5; v++ {
+		suite.fifo.Enqueue(v)
+	}
+
+	suite.Equal([]interface{}{0, 1, 2, 3, 4}, dequeueAll(suite.fifo), "Elements must be dequeued in FIFO order")
+}
+
+// ***************************************************************************************
+// ** Split
+// ***************************************************************************************
+
+// single Split lock verification
+func (suite *FIFOTestSuite) TestSplitLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.Split(2)
+	suite.Error(err, "Locked queue does not allow to split elements")
+}
+
+// split into n queues, round-robin
+func (suite *FIFOTestSuite) TestSplitSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for i := 0; i < 7; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	queues, err := suite.fifo.Split(3)
+	suite.NoError(err, "Unexpected error")
+	suite.Len(queues, 3, "Unexpected number of queues")
+	suite.Equal(0, suite.fifo.GetLen(), "The source queue must be empty")
+
+	for i, expected := range [][]interface{}{{0, 3, 6}, {1, 4}, {2, 5}} {
+		suite.Equal(len(expected), len(queues[i].meta), "Metadata must be moved along with the elements")
+		suite.Equal(expected, dequeueAll(queues[i]), "Unexpected elements at queue %v", i)
+	}
+
+	_, err = suite.fifo.Split(0)
+	suite.Error(err, "error expected for an invalid number of queues")
+}
+
+// ***************************************************************************************
+// ** SetClock
+// ***************************************************************************************
+
+// time based features use the given clock
+func (suite *FIFOTestSuite) TestSetClockSingleGR() {
+	clock := newFakeClock()
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetTimestampTracking(true)
+
+	suite.fifo.Enqueue(1)
+	clock.Advance(time.Hour)
+	_, meta, _ := suite.fifo.DequeueWithMeta()
+	suite.Equal(time.Hour, meta.TimeInQueue, "The time in queue must be measured using the clock")
+
+	accepted, _ := suite.fifo.EnqueueDebounced(1, "key", time.Minute)
+	suite.True(accepted, "The first element must be accepted")
+	clock.Advance(time.Minute)
+	accepted, _ = suite.fifo.EnqueueDebounced(1, "key", time.Minute)
+	suite.True(accepted, "The window must be measured using the clock")
+
+	// real time
+	suite.fifo.SetClock(nil)
+	suite.fifo.Enqueue(1)
+	suite.True(time.Since(suite.fifo.meta[len(suite.fifo.meta)-1].enqueuedAt) < time.Minute, "The real time must be used")
+}
+
+// ***************************************************************************************
+// ** EnqueueWithDone
+// ***************************************************************************************
+
+// the done channel gets closed once the element is dequeued
+func (suite *FIFOTestSuite) TestEnqueueWithDoneSingleGR() {
+	suite.fifo.Enqueue(0)
+	done, err := suite.fifo.EnqueueWithDone(1)
+	suite.NoError(err, "Unexpected error")
+	suite.fifo.Enqueue(2)
+
+	suite.fifo.Dequeue()
+	select {
+	case <-done:
+		suite.Fail("The done channel must be open until the element gets dequeued")
+	default:
+	}
+
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(1, value, "Wrong element's value")
+	select {
+	case <-done:
+	default:
+		suite.Fail("The done channel must be closed once the element gets dequeued")
+	}
+
+	suite.Nil(suite.fifo.meta[0].done, "Only the elements enqueued by EnqueueWithDone have a done channel")
+}
+
+// the done channel gets closed once the element is dropped
+func (suite *FIFOTestSuite) TestEnqueueWithDoneDroppedSingleGR() {
+	removed, _ := suite.fifo.EnqueueWithDone(1)
+	drained, _ := suite.fifo.EnqueueWithDone(2)
+	suite.fifo.Remove(0)
+	suite.fifo.DrainTail(1)
+
+	merged, _ := suite.fifo.EnqueueWithDone(3)
+	kept, _ := suite.fifo.EnqueueWithDone(4)
+	suite.fifo.Compact(func(a, b interface{}) (interface{}, bool) { return a.(int) + b.(int), true })
+
+	for _, done := range []<-chan struct{}{removed, drained, kept} {
+		select {
+		case <-done:
+		default:
+			suite.Fail("The done channel must be closed once the element gets dropped")
+		}
+	}
+
+	// the merged element keeps the channel of the older one
+	select {
+	case <-merged:
+		suite.Fail("The done channel of the merged element must be open")
+	default:
+	}
+	suite.fifo.Dequeue()
+	<-merged
+}
+
+// the done channel is kept once timestamp tracking gets disabled
+func (suite *FIFOTestSuite) TestEnqueueWithDoneTimestampTrackingSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	done, _ := suite.fifo.EnqueueWithDone(1)
+	suite.fifo.SetTimestampTracking(false)
+
+	_, meta, err := suite.fifo.DequeueWithMeta()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(time.Duration(0), meta.TimeInQueue, "No time in queue expected once timestamp tracking gets disabled")
+	<-done
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestEnqueueWithDoneLockSingleGR() {
+	suite.fifo.Lock()
+	done, err := suite.fifo.EnqueueWithDone(1)
+	suite.Error(err, "The queue is locked")
+	suite.Nil(done, "No done channel expected if the element was not enqueued")
+}
+
+// multiple producers waiting for their own elements
+func (suite *FIFOTestSuite) TestEnqueueWithDoneMultipleGRs() {
+	var (
+		totalGRs = 50
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		done, err := suite.fifo.EnqueueWithDone(i)
+		suite.NoError(err, "Unexpected error")
+		go func() {
+			defer wg.Done()
+			<-done
+		}()
+	}
+
+	for i := 0; i < totalGRs; i++ {
+		go suite.fifo.Dequeue()
+	}
+
+	wg.Wait()
+	suite.Equal(0, suite.fifo.GetLen(), "Empty queue expected")
+}
+
+// ***************************************************************************************
+// ** Clear
+// ***************************************************************************************
+
+// all the elements get removed
+func (suite *FIFOTestSuite) TestClearSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	done, _ := suite.fifo.EnqueueWithDone(10)
+
+	suite.NoError(suite.fifo.Clear(), "Unexpected error")
+	suite.Equal(0, suite.fifo.GetLen(), "Empty queue expected")
+	suite.Len(suite.fifo.meta, 0, "Metadata must be removed along with the elements")
+	<-done
+
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "No elements expected")
+
+	suite.fifo.Enqueue(1)
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(1, value, "Wrong element's value")
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestClearLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.Clear(), "The queue is locked")
+	suite.Equal(1, suite.fifo.GetLen(), "The elements must be kept if the queue is locked")
+}
+
+// GetLen always returns a valid count while the queue is being enqueued/dequeued/cleared
+func (suite *FIFOTestSuite) TestClearGetLenMultipleGRs() {
+	var (
+		totalGRs = 5
+		totalOps = 500
+		wg       sync.WaitGroup
+		invalid  int32
+	)
+
+	wg.Add(4 * totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			for c := 0; c < totalOps; c++ {
+				if len := suite.fifo.GetLen(); len < 0 || len > totalGRs*totalOps {
+					atomic.AddInt32(&invalid, 1)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for c := 0; c < totalOps; c++ {
+				suite.fifo.Enqueue(c)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for c := 0; c < totalOps; c++ {
+				suite.fifo.Dequeue()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for c := 0; c < totalOps/10; c++ {
+				suite.fifo.Clear()
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(int32(0), atomic.LoadInt32(&invalid), "GetLen must always return a valid count")
+
+	// the length matches the number of elements to be dequeued
+	len := suite.fifo.GetLen()
+	for i := 0; i < len; i++ {
+		_, err := suite.fifo.Dequeue()
+		suite.NoError(err, "Unexpected error")
+	}
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "No elements expected")
+}
+
+// ***************************************************************************************
+// ** EnqueueAt
+// ***************************************************************************************
+
+// elements get inserted at the given positions
+func (suite *FIFOTestSuite) TestEnqueueAtSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(3)
+
+	suite.NoError(suite.fifo.EnqueueAt(1, 2), "Unexpected error")
+	suite.NoError(suite.fifo.EnqueueAt(0, 0), "Unexpected error")
+	suite.NoError(suite.fifo.EnqueueAt(4, 4), "Unexpected error")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+
+	suite.Equal([]interface{}{0, 1, 2, 3, 4}, dequeueAll(suite.fifo), "Wrong elements' order")
+}
+
+// out of range index
+func (suite *FIFOTestSuite) TestEnqueueAtOutOfRangeSingleGR() {
+	suite.fifo.Enqueue(1)
+
+	suite.Error(suite.fifo.EnqueueAt(-1, 0), "index out of bounds error expected")
+	suite.Error(suite.fifo.EnqueueAt(2, 0), "index out of bounds error expected")
+	suite.Equal(1, suite.fifo.GetLen(), "No element must be enqueued")
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestEnqueueAtLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.EnqueueAt(0, 1), "The queue is locked")
+}
+
+// concurrent insertions at the head
+func (suite *FIFOTestSuite) TestEnqueueAtMultipleGRs() {
+	var (
+		totalGRs = 100
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.NoError(suite.fifo.EnqueueAt(0, value), "Unexpected error")
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(totalGRs, suite.fifo.GetLen(), "Wrong queue's length")
+}
+
+// ***************************************************************************************
+// ** RemoveValue
+// ***************************************************************************************
+
+// the first equal element gets removed
+func (suite *FIFOTestSuite) TestRemoveValueSingleGR() {
+	for _, v := range []interface{}{"a", "b", caseInsensitive("C"), "b"} {
+		suite.fifo.Enqueue(v)
+	}
+
+	suite.True(suite.fifo.RemoveValue("b"), "The element must be removed")
+	suite.True(suite.fifo.RemoveValue(caseInsensitive("c")), "The element must be removed using Equaler")
+	suite.False(suite.fifo.RemoveValue("z"), "No element expected to be removed")
+
+	suite.Equal([]interface{}{"a", "b"}, dequeueAll(suite.fifo), "Wrong remaining elements")
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestRemoveValueLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.False(suite.fifo.RemoveValue(1), "No element must be removed from a locked queue")
+}
+
+// concurrent removals, every element gets removed once
+func (suite *FIFOTestSuite) TestRemoveValueMultipleGRs() {
+	var (
+		totalGRs = 100
+		removed  int32
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < totalGRs/2; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			if suite.fifo.RemoveValue(value % (totalGRs / 2)) {
+				atomic.AddInt32(&removed, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(int32(totalGRs/2), removed, "Every element must be removed once")
+	suite.Equal(0, suite.fifo.GetLen(), "Empty queue expected")
+}
+
+// ***************************************************************************************
+// ** Peek
+// ***************************************************************************************
+
+// the first element is kept at the queue
+func (suite *FIFOTestSuite) TestPeekSingleGR() {
+	_, err := suite.fifo.Peek()
+	suite.Error(err, "Empty queue error expected")
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	value, err := suite.fifo.Peek()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "Wrong element's value")
+	suite.Equal(2, suite.fifo.GetLen(), "The element must be kept at the queue")
+
+	suite.fifo.Lock()
+	_, err = suite.fifo.Peek()
+	suite.Error(err, "The queue is locked")
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// an already enqueued element gets dequeued right away
+func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementSingleGR() {
+	suite.fifo.Enqueue(1)
+
+	value, err := suite.fifo.DequeueOrWaitForNextElement()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "Wrong element's value")
+
+	suite.fifo.Lock()
+	_, err = suite.fifo.DequeueOrWaitForNextElement()
+	suite.Error(err, "The queue is locked")
+}
+
+// waits for the next element
+func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementMultipleGRs() {
+	var (
+		totalGRs = 20
+		wg       sync.WaitGroup
+		values   = make(chan interface{}, totalGRs)
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := suite.fifo.DequeueOrWaitForNextElement()
+			suite.NoError(err, "Unexpected error")
+			values <- value
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < totalGRs; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	wg.Wait()
+
+	suite.Len(values, totalGRs, "Every waiting consumer must get an element")
+	suite.Equal(0, suite.fifo.GetLen(), "Empty queue expected")
+}
+
+// ***************************************************************************************
+// ** Reader / Writer
+// ***************************************************************************************
+
+// both views share the same queue
+func (suite *FIFOTestSuite) TestReaderWriterSingleGR() {
+	var (
+		reader = suite.fifo.Reader()
+		writer = suite.fifo.Writer()
+	)
+
+	_, isWriter := reader.(QueueWriter)
+	suite.False(isWriter, "The reader must not allow to enqueue")
+	_, isFIFO := writer.(*FIFO)
+	suite.False(isFIFO, "The writer must not be converted back into the queue")
+
+	suite.NoError(writer.Enqueue(1), "Unexpected error")
+	suite.NoError(writer.Enqueue(2), "Unexpected error")
+	suite.NoError(writer.Enqueue(3), "Unexpected error")
+	suite.Equal(3, reader.GetLen(), "Wrong queue's length")
+
+	value, _ := reader.Peek()
+	suite.Equal(1, value, "Wrong element's value")
+	value, _ = reader.Dequeue()
+	suite.Equal(1, value, "Wrong element's value")
+	value, _ = reader.DequeueOrWaitForNextElement()
+	suite.Equal(2, value, "Wrong element's value")
+	suite.Equal(1, suite.fifo.GetLen(), "The views must share the queue")
+}
+
+// ***************************************************************************************
+// ** EnqueueOrUpdate
+// ***************************************************************************************
+
+func createCounter() interface{} {
+	return 0
+}
+
+func addToCounter(existing interface{}, delta int) interface{} {
+	return existing.(int) + delta
+}
+
+// updates for the same key get coalesced while the element waits at the queue
+func (suite *FIFOTestSuite) TestEnqueueOrUpdateSingleGR() {
+	suite.NoError(suite.fifo.EnqueueOrUpdate("a", 1, createCounter, addToCounter), "Unexpected error")
+	suite.NoError(suite.fifo.EnqueueOrUpdate("b", 10, createCounter, addToCounter), "Unexpected error")
+	suite.fifo.Enqueue(100)
+	suite.NoError(suite.fifo.EnqueueOrUpdate("a", 2, createCounter, addToCounter), "Unexpected error")
+
+	suite.Equal(3, suite.fifo.GetLen(), "The updates must be coalesced")
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(3, value, "The updated element must keep its position")
+
+	// the dequeued key gets enqueued again
+	suite.fifo.EnqueueOrUpdate("a", 5, createCounter, addToCounter)
+	suite.Equal([]interface{}{10, 100, 5}, dequeueAll(suite.fifo), "Wrong elements")
+}
+
+// the metadata gets kept once timestamp tracking gets disabled
+func (suite *FIFOTestSuite) TestEnqueueOrUpdateTimestampTrackingSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	suite.fifo.EnqueueOrUpdate("a", 1, createCounter, addToCounter)
+	suite.fifo.SetTimestampTracking(false)
+	suite.fifo.EnqueueOrUpdate("a", 1, createCounter, addToCounter)
+
+	suite.Equal(1, suite.fifo.GetLen(), "The updates must be coalesced")
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestEnqueueOrUpdateLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.EnqueueOrUpdate("a", 1, createCounter, addToCounter), "The queue is locked")
+}
+
+// concurrent updates, no delta gets lost
+func (suite *FIFOTestSuite) TestEnqueueOrUpdateMultipleGRs() {
+	var (
+		totalGRs = 100
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(i int) {
+			defer wg.Done()
+			suite.fifo.EnqueueOrUpdate(fmt.Sprintf("key-%v", i%2), 1, createCounter, addToCounter)
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal([]interface{}{totalGRs / 2, totalGRs / 2}, dequeueAll(suite.fifo), "No update must be lost")
+}
+
+// ***************************************************************************************
+// ** EnqueueCancelable
+// ***************************************************************************************
+
+// the element gets removed if it was not dequeued yet
+func (suite *FIFOTestSuite) TestEnqueueCancelableSingleGR() {
+	suite.fifo.Enqueue(1)
+	cancel, err := suite.fifo.EnqueueCancelable(2)
+	suite.NoError(err, "Unexpected error")
+	suite.fifo.Enqueue(3)
+
+	suite.True(cancel(), "The element must be cancelled")
+	suite.False(cancel(), "The element was already cancelled")
+	suite.Equal([]interface{}{1, 3}, dequeueAll(suite.fifo), "Wrong remaining elements")
+
+	// already dequeued
+	cancel, _ = suite.fifo.EnqueueCancelable(4)
+	suite.fifo.Dequeue()
+	suite.False(cancel(), "A dequeued element could not be cancelled")
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestEnqueueCancelableLockSingleGR() {
+	suite.fifo.Lock()
+	cancel, err := suite.fifo.EnqueueCancelable(1)
+	suite.Error(err, "The queue is locked")
+	suite.Nil(cancel, "No cancel function expected if the element was not enqueued")
+}
+
+// the cancelled element goes to the dead letter handler
+func (suite *FIFOTestSuite) TestEnqueueCancelableDeadLetterSingleGR() {
+	var discarded []interface{}
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		suite.Equal(DeadLetterReasonCanceled, reason, "Unexpected reason")
+		discarded = append(discarded, value)
+	})
+	cancel, _ := suite.fifo.EnqueueCancelable(1)
+
+	suite.True(cancel(), "The element must be cancelled")
+	suite.False(cancel(), "The element was already cancelled")
+	suite.Equal([]interface{}{1}, discarded, "The cancelled element must be discarded once")
+}
+
+// cancel && dequeue at once: exactly one of them gets the element
+func (suite *FIFOTestSuite) TestEnqueueCancelableMultipleGRs() {
+	var (
+		totalElements = 200
+		cancelled     int32
+		dequeued      int32
+		wg            sync.WaitGroup
+	)
+
+	cancels := make([]CancelFunc, totalElements)
+	for i := range cancels {
+		cancels[i], _ = suite.fifo.EnqueueCancelable(i)
+	}
+
+	wg.Add(2 * totalElements)
+	for i := 0; i < totalElements; i++ {
+		go func(cancel CancelFunc) {
+			defer wg.Done()
+			if cancel() {
+				atomic.AddInt32(&cancelled, 1)
+			}
+		}(cancels[i])
+		go func() {
+			defer wg.Done()
+			if _, err := suite.fifo.Dequeue(); err == nil {
+				atomic.AddInt32(&dequeued, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(int32(totalElements), cancelled+dequeued+int32(suite.fifo.GetLen()), "Every element must be either cancelled, dequeued or kept")
+}
+
+// ***************************************************************************************
+// ** SetEnqueueRedirect
+// ***************************************************************************************
+
+// enqueued elements go to the redirect queue
+func (suite *FIFOTestSuite) TestSetEnqueueRedirectSingleGR() {
+	dst := NewFIFO()
+	suite.fifo.Enqueue(1)
+	suite.fifo.SetEnqueueRedirect(dst)
+
+	suite.NoError(suite.fifo.Enqueue(2), "Unexpected error")
+	suite.NoError(suite.fifo.EnqueueBatch([]interface{}{3, 4}), "Unexpected error")
+	suite.Equal([]interface{}{1}, dequeueAll(suite.fifo), "The original queue must be drained")
+	suite.Equal([]interface{}{2, 3, 4}, dequeueAll(dst), "The elements must be redirected")
+
+	suite.fifo.SetEnqueueRedirect(nil)
+	suite.fifo.Enqueue(5)
+	suite.Equal(1, suite.fifo.GetLen(), "The redirect must be cleared")
+
+	suite.fifo.SetEnqueueRedirect(suite.fifo)
+	suite.fifo.Enqueue(6)
+	suite.Equal(2, suite.fifo.GetLen(), "Redirecting to the same queue means no redirect")
+}
+
+// the redirect is not followed transitively && dst's lock applies
+func (suite *FIFOTestSuite) TestSetEnqueueRedirectChainSingleGR() {
+	dst := NewFIFO()
+	suite.fifo.SetEnqueueRedirect(dst)
+	dst.SetEnqueueRedirect(suite.fifo)
+
+	suite.NoError(suite.fifo.Enqueue(1), "Unexpected error")
+	suite.Equal(1, dst.GetLen(), "The redirect must not be followed transitively")
+
+	dst.Lock()
+	suite.Error(suite.fifo.Enqueue(2), "The redirect queue is locked")
+}
+
+// concurrent enqueues while the redirect gets set: no element gets lost
+func (suite *FIFOTestSuite) TestSetEnqueueRedirectMultipleGRs() {
+	var (
+		totalGRs = 100
+		dst      = NewFIFO()
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+	suite.fifo.SetEnqueueRedirect(dst)
+	wg.Wait()
+
+	suite.Equal(totalGRs, suite.fifo.GetLen()+dst.GetLen(), "No element must be lost")
+}
+
+// ***************************************************************************************
+// ** DequeueSince
+// ***************************************************************************************
+
+// the elements enqueued at or after t get dequeued
+func (suite *FIFOTestSuite) TestDequeueSinceSingleGR() {
+	clock := newFakeClock()
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetTimestampTracking(true)
+
+	suite.fifo.Enqueue(1)
+	clock.Advance(time.Second)
+	checkpoint := clock.Now()
+	suite.fifo.Enqueue(2)
+	clock.Advance(time.Second)
+	suite.fifo.Enqueue(3)
+	// older than the checkpoint
+	suite.fifo.SetClock(newFakeClock())
+	suite.fifo.Enqueue(0)
+
+	values, err := suite.fifo.DequeueSince(checkpoint)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]interface{}{2, 3}, values, "Wrong dequeued elements")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+	suite.Equal([]interface{}{1, 0}, dequeueAll(suite.fifo), "Wrong remaining elements")
+}
+
+// timestamp tracking is required
+func (suite *FIFOTestSuite) TestDequeueSinceNoTimestampsSingleGR() {
+	suite.fifo.Enqueue(1)
+	_, err := suite.fifo.DequeueSince(time.Time{})
+	suite.Error(err, "error expected while timestamp tracking is disabled")
+
+	suite.fifo.Lock()
+	_, err = suite.fifo.DequeueSince(time.Time{})
+	suite.Error(err, "The queue is locked")
+}
+
+// concurrent enqueues while dequeuing since a checkpoint, no element gets lost
+func (suite *FIFOTestSuite) TestDequeueSinceMultipleGRs() {
+	var (
+		totalGRs = 100
+		wg       sync.WaitGroup
+		dequeued []interface{}
+	)
+	suite.fifo.SetTimestampTracking(true)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+	dequeued, _ = suite.fifo.DequeueSince(time.Time{})
+	wg.Wait()
+
+	suite.Equal(totalGRs, len(dequeued)+suite.fifo.GetLen(), "No element must be lost")
+}
+
+// ***************************************************************************************
+// ** DequeueOrEnqueue
+// ***************************************************************************************
+
+// dequeue if there are elements, enqueue the fallback otherwise
+func (suite *FIFOTestSuite) TestDequeueOrEnqueueSingleGR() {
+	value, didDequeue, err := suite.fifo.DequeueOrEnqueue("fallback")
+	suite.NoError(err, "Unexpected error")
+	suite.False(didDequeue, "The fallback must be enqueued into an empty queue")
+	suite.Equal("fallback", value, "The fallback must be returned")
+	suite.Equal(1, suite.fifo.GetLen(), "The fallback must be enqueued")
+
+	value, didDequeue, err = suite.fifo.DequeueOrEnqueue("other")
+	suite.NoError(err, "Unexpected error")
+	suite.True(didDequeue, "An element must be dequeued")
+	suite.Equal("fallback", value, "Wrong element's value")
+	suite.Equal(0, suite.fifo.GetLen(), "Empty queue expected")
+
+	suite.fifo.Lock()
+	_, _, err = suite.fifo.DequeueOrEnqueue("fallback")
+	suite.Error(err, "The queue is locked")
+}
+
+// rendezvous: every enqueued fallback gets dequeued by other GR
+func (suite *FIFOTestSuite) TestDequeueOrEnqueueMultipleGRs() {
+	var (
+		totalGRs = 100
+		dequeued int32
+		enqueued int32
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			if _, didDequeue, _ := suite.fifo.DequeueOrEnqueue(value); didDequeue {
+				atomic.AddInt32(&dequeued, 1)
+			} else {
+				atomic.AddInt32(&enqueued, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(int(enqueued-dequeued), suite.fifo.GetLen(), "Every dequeue must take an enqueued fallback")
+	suite.True(suite.fifo.GetLen() <= 1, "At most one fallback could be left")
+}
+
+// ***************************************************************************************
+// ** SetOrdering
+// ***************************************************************************************
+
+type orderedElement struct {
+	priority int
+	name     string
+}
+
+func byPriority(a, b interface{}) bool {
+	return a.(orderedElement).priority < b.(orderedElement).priority
+}
+
+// elements get dequeued sorted (stable)
+func (suite *FIFOTestSuite) TestSetOrderingSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	suite.fifo.Enqueue(orderedElement{3, "a"})
+	suite.fifo.Enqueue(orderedElement{1, "b"})
+	suite.fifo.SetOrdering(byPriority)
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+
+	suite.fifo.EnqueueBatch([]interface{}{orderedElement{2, "c"}, orderedElement{1, "d"}, orderedElement{3, "e"}})
+	suite.Equal([]interface{}{
+		orderedElement{1, "b"},
+		orderedElement{1, "d"},
+		orderedElement{2, "c"},
+		orderedElement{3, "a"},
+		orderedElement{3, "e"},
+	}, dequeueAll(suite.fifo), "Elements must be dequeued sorted (stable)")
+
+	// FIFO order
+	suite.fifo.SetOrdering(nil)
+	suite.fifo.Enqueue(orderedElement{3, "f"})
+	suite.fifo.Enqueue(orderedElement{1, "g"})
+	suite.Equal([]interface{}{orderedElement{3, "f"}, orderedElement{1, "g"}}, dequeueAll(suite.fifo), "FIFO order expected")
+}
+
+// concurrent enqueues keep the queue sorted
+func (suite *FIFOTestSuite) TestSetOrderingMultipleGRs() {
+	var (
+		totalGRs = 100
+		wg       sync.WaitGroup
+	)
+	suite.fifo.SetOrdering(func(a, b interface{}) bool { return a.(int) < b.(int) })
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(totalGRs - i)
+	}
+	wg.Wait()
+
+	for i := 1; i <= totalGRs; i++ {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(i, value, "Elements must be dequeued sorted")
+	}
+}
+
+// ***************************************************************************************
+// ** MapTo
+// ***************************************************************************************
+
+// single MapTo lock verification
+func (suite *FIFOTestSuite) TestMapToLockSingleGR() {
+	other := NewFIFO()
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := suite.fifo.MapTo(other, func(value interface{}) interface{} { return value })
+	suite.Error(err, "Locked queue does not allow to map its elements")
+
+	suite.fifo.Unlock()
+	other.Lock()
+	_, err = suite.fifo.MapTo(other, func(value interface{}) interface{} { return value })
+	suite.Error(err, "Locked destination does not accept the mapped elements")
+	suite.Equal(1, suite.fifo.GetLen(), "No element could be drained if the destination is locked")
+
+	_, err = suite.fifo.MapTo(suite.fifo, func(value interface{}) interface{} { return value })
+	suite.Error(err, "error expected mapping a queue into itself")
+}
+
+// transformed elements get enqueued into the destination in order
+func (suite *FIFOTestSuite) TestMapToSingleGR() {
+	other := NewFIFO()
+	other.Enqueue(0)
+	for i := 1; i <= 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	total, err := suite.fifo.MapTo(other, func(value interface{}) interface{} { return value.(int) * 10 })
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(3, total, "Wrong number of mapped elements")
+	suite.Equal(0, suite.fifo.GetLen(), "The source queue must be drained")
+
+	for _, expected := range []int{0, 10, 20, 30} {
+		value, err := other.Dequeue()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(expected, value, "Wrong element's value")
+	}
+}
+
+// the results rejected by the destination are reported
+func (suite *FIFOTestSuite) TestMapToRejectedSingleGR() {
+	other := NewFIFO()
+	other.SetKeyQuota(func(value interface{}) string { return fmt.Sprintf("%v", value) }, 1)
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	total, err := suite.fifo.MapTo(other, func(value interface{}) interface{} { return "same" })
+	suite.Equal(1, total, "Wrong number of mapped elements")
+	suite.IsType(&BatchError{}, err, "A *BatchError is expected")
+	suite.Equal(1, other.GetLen(), "Wrong destination's length")
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(2, value, "The rejected element must be put back as it was")
+}
+
+// no element gets lost if the destination gets locked while mapping
+func (suite *FIFOTestSuite) TestMapToDestinationLockedSingleGR() {
+	var dequeued []interface{}
+	other := NewFIFO()
+	other.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		other.Lock()
+		return value, nil
+	})
+	suite.fifo.SetDequeueHook(func(value interface{}) { dequeued = append(dequeued, value) })
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	total, err := suite.fifo.MapTo(other, func(value interface{}) interface{} { return value.(int) * 10 })
+	suite.Error(err, "Locked destination does not accept the mapped elements")
+	suite.Equal(0, total, "Wrong number of mapped elements")
+	suite.Equal([]interface{}{1, 2}, dequeued, "The drained elements must be dequeued")
+	values, _ := suite.fifo.TakeAndClear()
+	suite.Equal([]interface{}{1, 2}, values, "The elements must be put back as they were, in order")
+	other.Unlock()
+	suite.Equal(0, other.GetLen(), "No element must be enqueued into the locked destination")
+}
+
+// concurrent MapTo in both directions must not deadlock nor lose elements
+func (suite *FIFOTestSuite) TestMapToMultipleGRs() {
+	var (
+		wg       sync.WaitGroup
+		other    = NewFIFO()
+		identity = func(value interface{}) interface{} { return value }
+	)
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			suite.fifo.MapTo(other, identity)
+		}()
+		go func() {
+			defer wg.Done()
+			other.MapTo(suite.fifo, identity)
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(10, suite.fifo.GetLen()+other.GetLen(), "No element could be lost while mapping")
+}
+
+// ***************************************************************************************
+// ** ReplaceWhere
+// ***************************************************************************************
+
+// single ReplaceWhere lock verification
+func (suite *FIFOTestSuite) TestReplaceWhereLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Equal(0, suite.fifo.ReplaceWhere(func(interface{}) bool { return true }, func(v interface{}) interface{} { return v }),
+		"Locked queue does not allow to replace elements")
+}
+
+// replace elements in place keeping the order
+func (suite *FIFOTestSuite) TestReplaceWhereSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for i := 0; i < 6; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	total := suite.fifo.ReplaceWhere(func(v interface{}) bool { return v.(int)%2 == 1 }, func(v interface{}) interface{} { return v.(int) * 10 })
+	suite.Equal(3, total, "Unexpected number of replaced elements")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+
+	for _, expected := range []int{0, 10, 2, 30, 4, 50} {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(expected, val, "Wrong element's value")
+	}
+}
+
+// the key quota counters follow the replaced elements
+func (suite *FIFOTestSuite) TestReplaceWhereKeyQuotaSingleGR() {
+	suite.fifo.SetKeyQuota(func(v interface{}) string { return fmt.Sprintf("%v", v) }, 1)
+	suite.fifo.Enqueue(1)
+
+	suite.Equal(1, suite.fifo.ReplaceWhere(func(v interface{}) bool { return v == 1 }, func(interface{}) interface{} { return 2 }))
+	suite.NoError(suite.fifo.Enqueue(1), "The replaced element's key must be released")
+	suite.Error(suite.fifo.Enqueue(2), "The replacement's key must be counted")
+}
+
+// ***************************************************************************************
+// ** SetEmptyValue / ClearEmptyValue
+// ***************************************************************************************
+
+// Dequeue returns the empty value instead of an error
+func (suite *FIFOTestSuite) TestEmptyValueSingleGR() {
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "error expected by default")
+
+	suite.fifo.SetEmptyValue(-1)
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "No error expected once the empty value is set")
+	suite.Equal(-1, value, "The empty value is expected")
+
+	// the enqueued elements are dequeued as usual
+	suite.fifo.Enqueue(1)
+	value, err = suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "Wrong element's value")
+
+	// nil is a valid empty value
+	suite.fifo.SetEmptyValue(nil)
+	value, err = suite.fifo.Dequeue()
+	suite.NoError(err, "No error expected once the empty value is set")
+	suite.Nil(value, "The empty value is expected")
+
+	suite.fifo.ClearEmptyValue()
+	_, err = suite.fifo.Dequeue()
+	suite.Error(err, "error expected once the empty value is cleared")
+}
+
+// a locked queue keeps returning an error
+func (suite *FIFOTestSuite) TestEmptyValueLockSingleGR() {
+	suite.fifo.SetEmptyValue(-1)
+	suite.fifo.Lock()
+
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// ***************************************************************************************
+// ** GetFromTail
+// ***************************************************************************************
+
+// single GetFromTail lock verification
+func (suite *FIFOTestSuite) TestGetFromTailLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := suite.fifo.GetFromTail(0)
+	suite.Error(err, "Locked queue does not allow to get elements")
+}
+
+// get elements counting from the tail
+func (suite *FIFOTestSuite) TestGetFromTailSingleGR() {
+	for i := 0; i < 4; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	for n, expected := range []int{3, 2, 1, 0} {
+		value, err := suite.fifo.GetFromTail(n)
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(expected, value, "Wrong element's value")
+	}
+	suite.Equal(4, suite.fifo.GetLen(), "The elements must be kept at the queue")
+}
+
+// out of range positions
+func (suite *FIFOTestSuite) TestGetFromTailInvalidElementSingleGR() {
+	_, err := suite.fifo.GetFromTail(0)
+	suite.Error(err, "error expected getting from an empty queue")
+
+	suite.fifo.Enqueue(1)
+	_, err = suite.fifo.GetFromTail(1)
+	suite.Error(err, "error expected for an out of range position")
+	_, err = suite.fifo.GetFromTail(-1)
+	suite.Error(err, "error expected for a negative position")
+}
+
+// ***************************************************************************************
+// ** Equal
+// ***************************************************************************************
+
+// same elements in the same order
+func (suite *FIFOTestSuite) TestEqualSingleGR() {
+	other := NewFIFO()
+	suite.True(suite.fifo.Equal(other), "Empty queues must be equal")
+
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+		other.Enqueue(i)
+	}
+	suite.True(suite.fifo.Equal(other), "Queues having the same elements must be equal")
+	suite.True(other.Equal(suite.fifo), "Equal must be symmetric")
+	suite.True(suite.fifo.Equal(suite.fifo), "A queue must be equal to itself")
+	suite.Equal(3, suite.fifo.GetLen(), "The elements must be kept at the queue")
+
+	other.Enqueue(3)
+	suite.False(suite.fifo.Equal(other), "Queues having different lengths must not be equal")
+
+	reversed := NewFIFO()
+	for i := 2; i >= 0; i-- {
+		reversed.Enqueue(i)
+	}
+	suite.False(suite.fifo.Equal(reversed), "Queues having different orders must not be equal")
+}
+
+// Equaler elements
+func (suite *FIFOTestSuite) TestEqualEqualerSingleGR() {
+	other := NewFIFO()
+	suite.fifo.Enqueue(caseInsensitive("A"))
+	other.Enqueue(caseInsensitive("a"))
+
+	suite.True(suite.fifo.Equal(other), "The elements' Equals must be used")
+}
+
+// concurrent comparisons in both directions must not deadlock
+func (suite *FIFOTestSuite) TestEqualMultipleGRs() {
+	var (
+		wg    sync.WaitGroup
+		other = NewFIFO()
+	)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			suite.fifo.Equal(other)
+		}()
+		go func() {
+			defer wg.Done()
+			other.Equal(suite.fifo)
+		}()
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+	wg.Wait()
+
+	suite.False(suite.fifo.Equal(other), "Queues having different lengths must not be equal")
+}
+
+// ***************************************************************************************
+// ** DequeueOrDefault
+// ***************************************************************************************
+
+// the default is returned if no element could be dequeued
+func (suite *FIFOTestSuite) TestDequeueOrDefaultSingleGR() {
+	suite.Equal(-1, suite.fifo.DequeueOrDefault(-1), "The default is expected for an empty queue")
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Equal(-1, suite.fifo.DequeueOrDefault(-1), "The default is expected for a locked queue")
+
+	suite.fifo.Unlock()
+	suite.Equal(1, suite.fifo.DequeueOrDefault(-1), "The enqueued element is expected")
+	suite.Equal(0, suite.fifo.GetLen(), "The element must be dequeued")
+}
+
+// polling an empty queue does not allocate
+func (suite *FIFOTestSuite) TestDequeueOrDefaultAllocsSingleGR() {
+	var def interface{} = -1
+	allocs := testing.AllocsPerRun(100, func() {
+		suite.fifo.DequeueOrDefault(def)
+	})
+	suite.Equal(float64(0), allocs, "No allocations expected for an empty queue")
+}
+
+// ***************************************************************************************
+// ** SetOrdering: per-element metadata
+// ***************************************************************************************
+
+// the done channel belongs to the element enqueued at its sorted position
+func (suite *FIFOTestSuite) TestOrderingEnqueueWithDoneSingleGR() {
+	suite.fifo.SetOrdering(func(a, b interface{}) bool { return a.(int) < b.(int) })
+	suite.fifo.Enqueue(5)
+	done, _ := suite.fifo.EnqueueWithDone(1)
+
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(1, value, "The minimum is expected")
+	select {
+	case <-done:
+	default:
+		suite.Fail("The dequeued element's done channel must be closed")
+	}
+}
+
+// the key belongs to the element enqueued at its sorted position
+func (suite *FIFOTestSuite) TestOrderingEnqueueOrUpdateSingleGR() {
+	var (
+		create = func() interface{} { return 0 }
+		update = func(existing interface{}, delta int) interface{} { return existing.(int) + delta }
+	)
+	suite.fifo.SetOrdering(func(a, b interface{}) bool { return a.(int) < b.(int) })
+	suite.fifo.Enqueue(50)
+	suite.NoError(suite.fifo.EnqueueOrUpdate("key", 1, create, update), "Unexpected error")
+	suite.NoError(suite.fifo.EnqueueOrUpdate("key", 2, create, update), "Unexpected error")
+
+	for _, expected := range []int{3, 50} {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(expected, value, "Wrong element's value")
+	}
+}
+
+// ***************************************************************************************
+// ** Swap: timestamp tracking
+// ***************************************************************************************
+
+// a tracked queue keeps tracking the elements swapped from an untracked one
+func (suite *FIFOTestSuite) TestSwapTimestampTrackingSingleGR() {
+	other := NewFIFO()
+	other.Enqueue(1)
+	suite.fifo.SetTimestampTracking(true)
+
+	suite.NoError(Swap(suite.fifo, other), "Unexpected error")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+
+	values, err := suite.fifo.DequeueSince(time.Time{})
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(0, len(values), "The swapped elements have an unknown enqueue time")
+
+	suite.fifo.Enqueue(2)
+	values, _ = suite.fifo.DequeueSince(time.Time{})
+	suite.Equal([]interface{}{2}, values, "The new elements must be tracked")
+}
+
+// ***************************************************************************************
+// ** Walk
+// ***************************************************************************************
+
+// single Walk lock verification
+func (suite *FIFOTestSuite) TestWalkLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Equal(0, suite.fifo.Walk(func(interface{}) WalkAction { return WalkRemove }), "Locked queue does not allow to walk")
+}
+
+// remove elements in a single pass keeping the order
+func (suite *FIFOTestSuite) TestWalkSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for i := 0; i < 6; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	visited := make([]interface{}, 0)
+	removed := suite.fifo.Walk(func(v interface{}) WalkAction {
+		visited = append(visited, v)
+		if v.(int)%2 == 1 {
+			return WalkRemove
+		}
+		return WalkKeep
+	})
+	suite.Equal(3, removed, "Unexpected number of removed elements")
+	suite.Equal([]interface{}{0, 1, 2, 3, 4, 5}, visited, "Every element must be visited from the head")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+	suite.Equal([]interface{}{0, 2, 4}, dequeueAll(suite.fifo), "Wrong kept elements")
+}
+
+// WalkStop keeps the rest of the elements
+func (suite *FIFOTestSuite) TestWalkStopSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for i := 0; i < 6; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	visited := 0
+	removed := suite.fifo.Walk(func(v interface{}) WalkAction {
+		visited++
+		switch v.(int) {
+		case 0, 1:
+			return WalkRemove
+		case 3:
+			return WalkStop
+		}
+		return WalkKeep
+	})
+	suite.Equal(2, removed, "Unexpected number of removed elements")
+	suite.Equal(4, visited, "No element must be visited after WalkStop")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+	suite.Equal([]interface{}{2, 3, 4, 5}, dequeueAll(suite.fifo), "Wrong kept elements")
+}
+
+// the key quota counters follow the removed elements
+func (suite *FIFOTestSuite) TestWalkKeyQuotaSingleGR() {
+	suite.fifo.SetKeyQuota(func(v interface{}) string { return fmt.Sprintf("%v", v) }, 1)
+	suite.fifo.Enqueue(1)
+
+	suite.Equal(1, suite.fifo.Walk(func(interface{}) WalkAction { return WalkRemove }), "Unexpected number of removed elements")
+	suite.NoError(suite.fifo.Enqueue(1), "The removed element's key must be released")
+}
+
+// walk while other GRs enqueue
+func (suite *FIFOTestSuite) TestWalkMultipleGRs() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+
+	removed := 0
+	for i := 0; i < 10; i++ {
+		removed += suite.fifo.Walk(func(v interface{}) WalkAction {
+			if v.(int)%2 == 0 {
+				return WalkRemove
+			}
+			return WalkKeep
+		})
+	}
+	wg.Wait()
+	removed += suite.fifo.Walk(func(v interface{}) WalkAction {
+		if v.(int)%2 == 0 {
+			return WalkRemove
+		}
+		return WalkKeep
+	})
+
+	suite.Equal(50, removed, "Every even element must be removed once")
+	suite.Equal(50, suite.fifo.GetLen(), "The odd elements must be kept")
+}
+
+// ***************************************************************************************
+// ** TypeHistogram
+// ***************************************************************************************
+
+// elements counted by type
+func (suite *FIFOTestSuite) TestTypeHistogramSingleGR() {
+	suite.Len(suite.fifo.TypeHistogram(), 0, "Empty histogram expected for an empty queue")
+
+	for _, value := range []interface{}{1, 2, "a", nil, []int{1}, &FutureElement{}} {
+		suite.fifo.Enqueue(value)
+	}
+	suite.fifo.Lock()
+
+	suite.Equal(map[string]int{"int": 2, "string": 1, "<nil>": 1, "[]int": 1, "*goconcurrentqueue.FutureElement": 1},
+		suite.fifo.TypeHistogram(), "Wrong histogram")
+	suite.Equal(6, suite.fifo.GetLen(), "The queue must be kept untouched")
+}
+
+// histogram while other GRs enqueue
+func (suite *FIFOTestSuite) TestTypeHistogramMultipleGRs() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		suite.True(suite.fifo.TypeHistogram()["int"] <= 100, "Unexpected count")
+	}
+	wg.Wait()
+	suite.Equal(100, suite.fifo.TypeHistogram()["int"], "Every element must be counted")
+}
+
+// ***************************************************************************************
+// ** WaitUntilQuiescent
+// ***************************************************************************************
+
+// an idle queue is quiescent
+func (suite *FIFOTestSuite) TestWaitUntilQuiescentSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.NoError(suite.fifo.WaitUntilQuiescent(context.Background(), 5*time.Millisecond), "Unexpected error")
+	suite.NoError(suite.fifo.WaitUntilQuiescent(context.Background(), 0), "No wait expected for quietFor <= 0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	suite.Equal(context.Canceled, suite.fifo.WaitUntilQuiescent(ctx, time.Hour), "ctx's error expected")
+}
+
+// it waits for the operations to stop
+func (suite *FIFOTestSuite) TestWaitUntilQuiescentMultipleGRs() {
+	var stopped int32
+	go func() {
+		for i := 0; i < 20; i++ {
+			suite.fifo.Enqueue(i)
+			suite.fifo.Dequeue()
+			time.Sleep(time.Millisecond)
+		}
+		atomic.StoreInt32(&stopped, 1)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	suite.NoError(suite.fifo.WaitUntilQuiescent(ctx, 50*time.Millisecond), "Unexpected error")
+	suite.Equal(int32(1), atomic.LoadInt32(&stopped), "The operations must be over")
+}
+
+// ***************************************************************************************
+// ** DrainInto
+// ***************************************************************************************
+
+// single DrainInto lock verification
+func (suite *FIFOTestSuite) TestDrainIntoLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := suite.fifo.DrainInto(make([]interface{}, 1))
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// the head elements are drained into the given slice
+func (suite *FIFOTestSuite) TestDrainIntoSingleGR() {
+	suite.fifo.SetDequeueTransform(func(v interface{}) interface{} { return v.(int) * 10 })
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	buffer := make([]interface{}, 3)
+	total, err := suite.fifo.DrainInto(buffer)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(3, total, "Wrong number of drained elements")
+	suite.Equal([]interface{}{0, 10, 20}, buffer, "Wrong drained elements")
+
+	total, _ = suite.fifo.DrainInto(buffer)
+	suite.Equal(2, total, "Wrong number of drained elements")
+	suite.Equal([]interface{}{30, 40}, buffer[:total], "Wrong drained elements")
+
+	total, err = suite.fifo.DrainInto(buffer)
+	suite.NoError(err, "No error expected for an empty queue")
+	suite.Equal(0, total, "No element expected")
+}
+
+// draining does not allocate
+func (suite *FIFOTestSuite) TestDrainIntoAllocationsSingleGR() {
+	buffer := make([]interface{}, 10)
+	allocs := testing.AllocsPerRun(10, func() {
+		suite.fifo.DrainInto(buffer)
+	})
+	suite.Equal(float64(0), allocs, "No allocation expected")
+}
+
+// drain while other GRs enqueue
+func (suite *FIFOTestSuite) TestDrainIntoMultipleGRs() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+
+	var (
+		buffer   = make([]interface{}, 7)
+		drained  = 0
+		previous = -1
+	)
+	for drained < 100 {
+		total, _ := suite.fifo.DrainInto(buffer)
+		for _, value := range buffer[:total] {
+			suite.Equal(previous+1, value, "The order must be preserved")
+			previous = value.(int)
+		}
+		drained += total
+		runtime.Gosched()
+	}
+	wg.Wait()
+}
+
+// ***************************************************************************************
+// ** EnqueueIfTailDiffers
+// ***************************************************************************************
+
+// single EnqueueIfTailDiffers lock verification
+func (suite *FIFOTestSuite) TestEnqueueIfTailDiffersLockSingleGR() {
+	suite.fifo.Lock()
+	_, err := suite.fifo.EnqueueIfTailDiffers(1)
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+}
+
+// runs of equal elements are suppressed
+func (suite *FIFOTestSuite) TestEnqueueIfTailDiffersSingleGR() {
+	for _, value := range []interface{}{"up", "up", "down", "down", "down", "up", []int{1}, []int{1}} {
+		suite.fifo.EnqueueIfTailDiffers(value)
+	}
+	suite.Equal([]interface{}{"up", "down", "up", []int{1}, []int{1}}, dequeueAll(suite.fifo),
+		"Only the elements different from the tail must be enqueued (uncomparable elements are never equal)")
+
+	enqueued, err := suite.fifo.EnqueueIfTailDiffers("up")
+	suite.NoError(err, "Unexpected error")
+	suite.True(enqueued, "An empty queue always enqueues")
+}
+
+// concurrent EnqueueIfTailDiffers never enqueues two equal consecutive elements
+func (suite *FIFOTestSuite) TestEnqueueIfTailDiffersMultipleGRs() {
+	var wg sync.WaitGroup
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				suite.fifo.EnqueueIfTailDiffers((i + j) % 2)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	values := dequeueAll(suite.fifo)
+	for i := 1; i < len(values); i++ {
+		suite.NotEqual(values[i-1], values[i], "No equal consecutive elements expected")
+	}
+}
+
+// ***************************************************************************************
+// ** TakeAndClear
+// ***************************************************************************************
+
+// single TakeAndClear lock verification
+func (suite *FIFOTestSuite) TestTakeAndClearLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := suite.fifo.TakeAndClear()
+	suite.Error(err, "Locked queue does not allow to take the elements")
+}
+
+// all the elements are taken at once
+func (suite *FIFOTestSuite) TestTakeAndClearSingleGR() {
+	done, _ := suite.fifo.EnqueueWithDone(0)
+	for i := 1; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	values, err := suite.fifo.TakeAndClear()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]interface{}{0, 1, 2}, values, "Wrong taken elements")
+	suite.Equal(0, suite.fifo.GetLen(), "The queue must be empty")
+	<-done
+
+	suite.fifo.Enqueue(3)
+	suite.Equal([]interface{}{0, 1, 2}, values, "The taken elements must not be modified by the queue")
+	values, _ = suite.fifo.TakeAndClear()
+	suite.Equal([]interface{}{3}, values, "Wrong taken elements")
+}
+
+// every element is taken once while other GRs enqueue
+func (suite *FIFOTestSuite) TestTakeAndClearMultipleGRs() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+
+	taken := make([]interface{}, 0)
+	for len(taken) < 100 {
+		values, _ := suite.fifo.TakeAndClear()
+		taken = append(taken, values...)
+		runtime.Gosched()
+	}
+	wg.Wait()
+
+	for i, value := range taken {
+		suite.Equal(i, value, "Every element must be taken once, in order")
+	}
+}
+
+// ***************************************************************************************
+// ** MergeSortedDequeue
+// ***************************************************************************************
+
+func lessInt(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+// sorted queues get merged into a sorted stream
+func (suite *FIFOTestSuite) TestMergeSortedDequeueSingleGR() {
+	a, b, c := NewFIFO(), NewFIFO(), NewFIFO()
+	a.EnqueueBatch([]interface{}{1, 4, 7})
+	b.EnqueueBatch([]interface{}{2, 5, 8, 9})
+	c.EnqueueBatch([]interface{}{0, 3})
+
+	merged := make([]interface{}, 0)
+	for {
+		value, err := MergeSortedDequeue(lessInt, a, b, c)
+		if err != nil {
+			break
+		}
+		merged = append(merged, value)
+	}
+	suite.Equal([]interface{}{0, 1, 2, 3, 4, 5, 7, 8, 9}, merged, "Wrong merged stream")
+	suite.Equal(0, a.GetLen()+b.GetLen()+c.GetLen(), "All the elements must be dequeued")
+}
+
+// the first queue wins the ties, a repeated queue gets locked once
+func (suite *FIFOTestSuite) TestMergeSortedDequeueTiesSingleGR() {
+	a, b := NewFIFO(), NewFIFO()
+	a.Enqueue(1)
+	b.Enqueue(1)
+	b.Enqueue(2)
+
+	MergeSortedDequeue(lessInt, b, a, b)
+	suite.Equal(1, a.GetLen(), "The first queue must win the ties")
+	suite.Equal(1, b.GetLen(), "Wrong queue dequeued")
+}
+
+// locked or empty queues
+func (suite *FIFOTestSuite) TestMergeSortedDequeueErrorsSingleGR() {
+	_, err := MergeSortedDequeue(lessInt, suite.fifo)
+	suite.Error(err, "All the queues are empty")
+
+	other := NewFIFO()
+	other.Enqueue(1)
+	suite.fifo.Lock()
+	_, err = MergeSortedDequeue(lessInt, other, suite.fifo)
+	suite.Error(err, "A locked queue does not allow to merge")
+	suite.Equal(1, other.GetLen(), "No element must be dequeued")
+}
+
+// concurrent merges over the same queues: every element gets dequeued once
+func (suite *FIFOTestSuite) TestMergeSortedDequeueMultipleGRs() {
+	a, b := NewFIFO(), NewFIFO()
+	for i := 0; i < 100; i++ {
+		a.Enqueue(2 * i)
+		b.Enqueue(2*i + 1)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		seen  = make(map[interface{}]bool)
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				value, err := MergeSortedDequeue(lessInt, b, a)
+				if err != nil {
+					return
+				}
+				mutex.Lock()
+				suite.False(seen[value], "An element must not be dequeued twice")
+				seen[value] = true
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Len(seen, 200, "Every element must be dequeued once")
+}
+
+// ***************************************************************************************
+// ** TransferOne
+// ***************************************************************************************
+
+// single TransferOne lock verification
+func (suite *FIFOTestSuite) TestTransferOneLockSingleGR() {
+	dst := NewFIFO()
+	suite.fifo.Enqueue(1)
+	dst.Lock()
+	_, err := suite.fifo.TransferOne(dst)
+	suite.Error(err, "Locked destination does not allow to transfer elements")
+	suite.Equal(1, suite.fifo.GetLen(), "The element must not be dequeued")
+
+	_, err = suite.fifo.TransferOne(suite.fifo)
+	suite.Error(err, "The destination must be a different queue")
+}
+
+// the first element gets moved to dst's tail
+func (suite *FIFOTestSuite) TestTransferOneSingleGR() {
+	dst := NewFIFO()
+	dst.Enqueue(0)
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	value, err := suite.fifo.TransferOne(dst)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "The first element must be transferred")
+	suite.Equal(1, suite.fifo.GetLen(), "The element must be dequeued")
+	values, _ := dst.TakeAndClear()
+	suite.Equal([]interface{}{0, 1}, values, "The element must be enqueued at dst's tail")
+
+	suite.fifo.Dequeue()
+	_, err = suite.fifo.TransferOne(dst)
+	suite.Error(err, "Can't transfer from an empty queue")
+}
+
+// the element stays if dst rejects it
+func (suite *FIFOTestSuite) TestTransferOneQuotaSingleGR() {
+	dst := NewFIFO()
+	dst.SetKeyQuota(func(value interface{}) string { return "key" }, 1)
+	dst.Enqueue(0)
+	suite.fifo.Enqueue(1)
+
+	_, err := suite.fifo.TransferOne(dst)
+	suite.Error(err, "The key quota must be honored")
+	suite.Equal(1, suite.fifo.GetLen(), "The rejected element must stay")
+	suite.Equal(1, dst.GetLen(), "The rejected element must not be enqueued")
+}
+
+// concurrent transfers in both directions: no element gets lost or duplicated
+func (suite *FIFOTestSuite) TestTransferOneMultipleGRs() {
+	dst := NewFIFO()
+	for i := 0; i < 100; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				suite.fifo.TransferOne(dst)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dst.TransferOne(suite.fifo)
+			}
+		}()
+	}
+	wg.Wait()
+
+	values, _ := suite.fifo.TakeAndClear()
+	dstValues, _ := dst.TakeAndClear()
+	seen := make(map[interface{}]bool)
+	for _, value := range append(values, dstValues...) {
+		suite.False(seen[value], "An element must not be duplicated")
+		seen[value] = true
+	}
+	suite.Len(seen, 100, "No element must be lost")
+}
+
+// ***************************************************************************************
+// ** SetMaxElementSize
+// ***************************************************************************************
+
+func byteSize(value interface{}) int {
+	return len(value.([]byte))
+}
+
+// oversized elements get rejected
+func (suite *FIFOTestSuite) TestSetMaxElementSizeSingleGR() {
+	var discarded []string
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) { discarded = append(discarded, reason) })
+	suite.fifo.SetMaxElementSize(3, byteSize)
+
+	suite.NoError(suite.fifo.Enqueue([]byte("abc")), "Unexpected error")
+	err := suite.fifo.Enqueue([]byte("abcd"))
+	suite.Error(err, "Oversized elements must be rejected")
+	queueError, ok := err.(*QueueError)
+	suite.True(ok, "QueueError expected")
+	suite.Equal(QueueErrorCodeElementTooLarge, queueError.Code(), "Wrong error code")
+	suite.Equal([]string{DeadLetterReasonValidation}, discarded, "The rejected element must be discarded")
+
+	batchErr := suite.fifo.EnqueueBatch([]interface{}{[]byte("a"), []byte("abcde")})
+	suite.Error(batchErr, "Oversized elements must be rejected")
+	suite.Equal(2, suite.fifo.GetLen(), "The rest of the batch must be enqueued")
+
+	suite.fifo.SetMaxElementSize(3, nil)
+	suite.NoError(suite.fifo.Enqueue([]byte("abcd")), "A nil sizer disables the check")
+}
+
+// concurrent producers: only the oversized elements get rejected
+func (suite *FIFOTestSuite) TestSetMaxElementSizeMultipleGRs() {
+	suite.fifo.SetMaxElementSize(5, byteSize)
+
+	var (
+		wg       sync.WaitGroup
+		rejected int64
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if suite.fifo.Enqueue(make([]byte, j)) != nil {
+					atomic.AddInt64(&rejected, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(int64(16), rejected, "Only the oversized elements must be rejected")
+	suite.Equal(24, suite.fifo.GetLen(), "The rest of the elements must be enqueued")
+}
+
+// ***************************************************************************************
+// ** EnqueueBatchFront
+// ***************************************************************************************
+
+// single EnqueueBatchFront lock verification
+func (suite *FIFOTestSuite) TestEnqueueBatchFrontLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.EnqueueBatchFront([]interface{}{1}), "Locked queue does not allow to enqueue elements")
+}
+
+// the elements get inserted at the head keeping their order
+func (suite *FIFOTestSuite) TestEnqueueBatchFrontSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	suite.fifo.EnqueueBatch([]interface{}{1, 2, 3, 4})
+	first, _ := suite.fifo.Dequeue()
+	second, _ := suite.fifo.Dequeue()
+	batch := []interface{}{first, second}
+
+	suite.NoError(suite.fifo.EnqueueBatchFront(batch), "Unexpected error")
+	suite.NoError(suite.fifo.EnqueueBatchFront(nil), "Unexpected error")
+	values := make([]interface{}, 0)
+	for suite.fifo.GetLen() > 0 {
+		value, _, err := suite.fifo.DequeueWithMeta()
+		suite.NoError(err, "Unexpected error")
+		values = append(values, value)
+	}
+	suite.Equal([]interface{}{1, 2, 3, 4}, values, "The original order must be restored")
+}
+
+// none of them gets enqueued if any of them fails
+func (suite *FIFOTestSuite) TestEnqueueBatchFrontRejectedSingleGR() {
+	suite.fifo.Enqueue(0)
+	suite.fifo.SetKeyQuota(func(value interface{}) string { return "key" }, 2)
+	suite.Error(suite.fifo.EnqueueBatchFront([]interface{}{1, 2}), "The key quota must be honored")
+	suite.Equal(1, suite.fifo.GetLen(), "No element must be enqueued")
+
+	suite.fifo.SetKeyQuota(nil, 0)
+	suite.fifo.SetEnqueueTransform(func(value interface{}) (interface{}, error) {
+		if value == 2 {
+			return nil, fmt.Errorf("rejected")
+		}
+		return value, nil
+	})
+	suite.Error(suite.fifo.EnqueueBatchFront([]interface{}{1, 2}), "The enqueue transform must be honored")
+	suite.Equal(1, suite.fifo.GetLen(), "No element must be enqueued")
+}
+
+// every batch gets inserted at once
+func (suite *FIFOTestSuite) TestEnqueueBatchFrontMultipleGRs() {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				suite.fifo.EnqueueBatchFront([]interface{}{i, i, i})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	values, _ := suite.fifo.TakeAndClear()
+	suite.Len(values, 300, "Wrong number of elements")
+	for i := 0; i < len(values); i += 3 {
+		suite.Equal([]interface{}{values[i], values[i], values[i]}, values[i:i+3], "The batches must not be interleaved")
+	}
+}
+
 // ***************************************************************************************
 // ** Run suite
 // ***************************************************************************************
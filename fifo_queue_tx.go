@@ -0,0 +1,183 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Tx holds elements dequeued by FIFO.DequeueTx until they get committed (removed for good) or rolled back (returned to
+// the queue's head)
+type Tx interface {
+	// Elements returns the transaction's elements, in dequeue order
+	Elements() []interface{}
+	// Commit removes the elements for good
+	Commit() error
+	// Rollback returns the elements to the queue's head, in their original order
+	Rollback() error
+}
+
+// fifoTx is the FIFO's Tx implementation
+type fifoTx struct {
+	fifo *FIFO
+	// elements as they were stored (to be rolled back) && their metadata (nil == no metadata was tracked)
+	values []interface{}
+	meta   []elementMeta
+	// elements as returned by Elements (dequeue transform applied)
+	elements []interface{}
+	mutex    sync.Mutex
+	finished bool
+}
+
+// DequeueTx removes up to max elements from the head and returns them in a transaction: they are no longer visible to
+// the rest of the operations until the transaction gets committed (see Tx.Commit) or rolled back (see Tx.Rollback).
+// The elements leave the queue (done channels, dequeue hooks, history, ...) once the transaction gets committed.
+// Rollback ignores the lock and the key quota. An error is returned if the queue is empty.
+func (st *FIFO) DequeueTx(max int) (Tx, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	if max <= 0 {
+		return nil, fmt.Errorf("invalid number of elements: %v", max)
+	}
+
+	st.rwmutex.Lock()
+	if len(st.slice) == 0 {
+		st.rwmutex.Unlock()
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	if max > len(st.slice) {
+		max = len(st.slice)
+	}
+	tx := &fifoTx{
+		fifo:   st,
+		values: make([]interface{}, max),
+	}
+	copy(tx.values, st.slice[:max])
+	st.slice = st.slice[max:]
+	if max > 0 {
+		st.headChanged()
+	}
+	for _, value := range tx.values {
+		st.trackKey(value, -1)
+	}
+	if st.meta != nil {
+		tx.meta = make([]elementMeta, max)
+		copy(tx.meta, st.meta[:max])
+		st.meta = st.meta[max:]
+	}
+	transform := st.dequeueTransform
+	st.rwmutex.Unlock()
+
+	tx.elements = make([]interface{}, max)
+	for i, value := range tx.values {
+		tx.elements[i] = applyDequeueTransform(transform, value)
+	}
+
+	return tx, nil
+}
+
+func (st *fifoTx) Elements() []interface{} {
+	ret := make([]interface{}, len(st.elements))
+	copy(ret, st.elements)
+
+	return ret
+}
+
+func (st *fifoTx) Commit() error {
+	if err := st.finish(); err != nil {
+		return err
+	}
+
+	st.fifo.rwmutex.Lock()
+	st.commit()
+	st.fifo.rwmutex.Unlock()
+	st.fifo.runPendingHooks()
+
+	return nil
+}
+
+// commit makes the elements leave the queue. It must be called holding st.fifo.rwmutex.
+func (st *fifoTx) commit() {
+	for i, value := range st.values {
+		if st.meta != nil {
+			st.meta[i].release()
+		}
+		st.fifo.dequeued(value)
+	}
+}
+
+func (st *fifoTx) Rollback() error {
+	if err := st.finish(); err != nil {
+		return err
+	}
+
+	fifo := st.fifo
+	fifo.rwmutex.Lock()
+	defer fifo.rwmutex.Unlock()
+
+	if st.meta != nil {
+		fifo.ensureMeta()
+	}
+	fifo.slice = append(st.values, fifo.slice...)
+	fifo.headChanged()
+	for _, value := range st.values {
+		fifo.trackKey(value, 1)
+	}
+	if fifo.meta != nil {
+		meta := st.meta
+		if meta == nil {
+			meta = make([]elementMeta, len(st.values))
+		}
+		fifo.meta = append(meta, fifo.meta...)
+	}
+	fifo.signalEnqueue()
+
+	return nil
+}
+
+// CommitN commits the given transactions (see Tx.Commit) under a single lock: no other operation takes place until all
+// of them are committed. The transactions that could not be committed (already finished, or not dequeued from this
+// queue) are reported by a *BatchError, by their index in txs; the rest of them get committed anyway.
+func (st *FIFO) CommitN(txs []Tx) error {
+	batchErr := newBatchError()
+
+	st.rwmutex.Lock()
+	for i, tx := range txs {
+		fifoTx, ok := tx.(*fifoTx)
+		if !ok || fifoTx.fifo != st {
+			batchErr.add(i, errors.New("the transaction does not belong to the queue"))
+			continue
+		}
+		if err := fifoTx.finish(); err != nil {
+			batchErr.add(i, err)
+			continue
+		}
+
+		fifoTx.commit()
+	}
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	if batchErr.len() > 0 {
+		return batchErr
+	}
+
+	return nil
+}
+
+// finish marks the transaction as finished, returning an error if it already was
+func (st *fifoTx) finish() error {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.finished {
+		return errors.New("the transaction is already finished")
+	}
+	st.finished = true
+
+	return nil
+}
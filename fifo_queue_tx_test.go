@@ -0,0 +1,206 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+)
+
+// ***************************************************************************************
+// ** DequeueTx
+// ***************************************************************************************
+
+// single DequeueTx lock verification
+func (suite *FIFOTestSuite) TestDequeueTxLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err := suite.fifo.DequeueTx(1)
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// invalid max && empty queue
+func (suite *FIFOTestSuite) TestDequeueTxErrorsSingleGR() {
+	_, err := suite.fifo.DequeueTx(1)
+	suite.Error(err, "error expected dequeuing from an empty queue")
+
+	suite.fifo.Enqueue(1)
+	_, err = suite.fifo.DequeueTx(0)
+	suite.Error(err, "error expected for max <= 0")
+}
+
+// committed elements leave the queue for good
+func (suite *FIFOTestSuite) TestDequeueTxCommitSingleGR() {
+	var dequeued []interface{}
+	suite.fifo.SetHooksSynchronous(true)
+	suite.fifo.SetDequeueHook(func(value interface{}) { dequeued = append(dequeued, value) })
+	done, _ := suite.fifo.EnqueueWithDone(1)
+	for i := 2; i <= 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	tx, err := suite.fifo.DequeueTx(2)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal([]interface{}{1, 2}, tx.Elements(), "Wrong transaction's elements")
+	suite.Equal(1, suite.fifo.GetLen(), "The transaction's elements must not be visible")
+	select {
+	case <-done:
+		suite.Fail("The element must not be done before the commit")
+	default:
+	}
+	suite.Equal(0, len(dequeued), "The dequeue hook must run once committed")
+
+	suite.NoError(tx.Commit(), "Unexpected error")
+	<-done
+	suite.Equal([]interface{}{1, 2}, dequeued, "The dequeue hook must run once committed")
+	suite.Equal(1, suite.fifo.GetLen(), "Wrong length after commit")
+
+	suite.Error(tx.Commit(), "error expected committing a finished transaction")
+	suite.Error(tx.Rollback(), "error expected rolling back a finished transaction")
+}
+
+// rolled back elements return to the head
+func (suite *FIFOTestSuite) TestDequeueTxRollbackSingleGR() {
+	suite.fifo.SetTimestampTracking(true)
+	for i := 1; i <= 4; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	tx, err := suite.fifo.DequeueTx(10)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(4, len(tx.Elements()), "max must be capped by the queue's length")
+	suite.fifo.Enqueue(5)
+
+	suite.NoError(tx.Rollback(), "Unexpected error")
+	suite.Equal(len(suite.fifo.slice), len(suite.fifo.meta), "Metadata must be kept in sync with the elements")
+	for i := 1; i <= 5; i++ {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(i, value, "Wrong element's value")
+	}
+}
+
+// the dequeue transform only affects the returned elements
+func (suite *FIFOTestSuite) TestDequeueTxTransformSingleGR() {
+	suite.fifo.SetDequeueTransform(func(value interface{}) interface{} { return value.(int) * 10 })
+	suite.fifo.Enqueue(1)
+
+	tx, _ := suite.fifo.DequeueTx(1)
+	suite.Equal([]interface{}{10}, tx.Elements(), "The dequeue transform must be applied")
+	tx.Rollback()
+	suite.Equal([]interface{}{1}, suite.fifo.slice, "The stored element must be rolled back")
+}
+
+// concurrent transactions neither lose nor duplicate elements
+func (suite *FIFOTestSuite) TestDequeueTxMultipleGRs() {
+	var (
+		totalGRs   = 10
+		totalItems = 100
+		wg         sync.WaitGroup
+		mutex      sync.Mutex
+		committed  = make(map[interface{}]int)
+	)
+	for i := 0; i < totalItems; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(gr int) {
+			defer wg.Done()
+			for round := 0; ; round++ {
+				tx, err := suite.fifo.DequeueTx(3)
+				if err != nil {
+					return
+				}
+				if (gr+round)%2 == 0 {
+					tx.Rollback()
+					continue
+				}
+				tx.Commit()
+				mutex.Lock()
+				for _, value := range tx.Elements() {
+					committed[value]++
+				}
+				mutex.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(totalItems, len(committed), "Every element must be committed")
+	for value, times := range committed {
+		suite.Equal(1, times, "Element %v committed more than once", value)
+	}
+}
+
+// ***************************************************************************************
+// ** CommitN
+// ***************************************************************************************
+
+// several transactions get committed at once
+func (suite *FIFOTestSuite) TestCommitNSingleGR() {
+	var dequeued []interface{}
+	suite.fifo.SetHooksSynchronous(true)
+	suite.fifo.SetDequeueHook(func(value interface{}) { dequeued = append(dequeued, value) })
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	tx1, _ := suite.fifo.DequeueTx(2)
+	tx2, _ := suite.fifo.DequeueTx(2)
+	suite.NoError(suite.fifo.CommitN([]Tx{tx1, tx2}), "Unexpected error")
+	suite.Equal([]interface{}{0, 1, 2, 3}, dequeued, "The dequeue hook must run once committed")
+	suite.Error(tx1.Rollback(), "error expected rolling back a committed transaction")
+	suite.NoError(suite.fifo.CommitN(nil), "No error expected for no transactions")
+}
+
+// the transactions that could not be committed are reported
+func (suite *FIFOTestSuite) TestCommitNErrorsSingleGR() {
+	other := NewFIFO()
+	other.Enqueue(1)
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	finished, _ := suite.fifo.DequeueTx(1)
+	finished.Rollback()
+	finished, _ = suite.fifo.DequeueTx(1)
+	finished.Commit()
+	foreign, _ := other.DequeueTx(1)
+	pending, _ := suite.fifo.DequeueTx(1)
+
+	err := suite.fifo.CommitN([]Tx{finished, foreign, pending})
+	batchErr, ok := err.(*BatchError)
+	suite.True(ok, "*BatchError expected")
+	errs := batchErr.Errors()
+	suite.Len(errs, 2, "Unexpected number of failed transactions")
+	suite.Error(errs[0], "error expected for a finished transaction")
+	suite.Error(errs[1], "error expected for a transaction of other queue")
+	suite.Error(pending.Commit(), "The rest of the transactions must be committed anyway")
+	suite.NoError(foreign.Rollback(), "The transaction of other queue must be kept untouched")
+}
+
+// CommitN while other GRs dequeue
+func (suite *FIFOTestSuite) TestCommitNMultipleGRs() {
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		txs   []Tx
+	)
+	for i := 0; i < 100; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer wg.Done()
+			if tx, err := suite.fifo.DequeueTx(10); err == nil {
+				mutex.Lock()
+				txs = append(txs, tx)
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.NoError(suite.fifo.CommitN(txs), "Unexpected error")
+	suite.Equal(0, suite.fifo.GetLen(), "Every element must be committed")
+}
@@ -0,0 +1,59 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// PeekVersioned returns the first element's value (as Peek does) along with the head's version, which changes every
+// time the first element changes (dequeued, removed, replaced, another element enqueued in front of it, ...). The
+// version is returned even if the queue is empty. See DequeueIfVersion.
+func (st *FIFO) PeekVersioned() (value interface{}, version uint64, err error) {
+	if st.isLocked {
+		return nil, 0, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if len(st.slice) == 0 {
+		return nil, st.headVersion, fmt.Errorf("queue is empty")
+	}
+
+	return st.slice[0], st.headVersion, nil
+}
+
+// DequeueIfVersion dequeues the first element only if the head's version still matches version (see PeekVersioned),
+// so a consumer could peek an element, process it and dequeue it only if no other consumer got it in the meantime. A
+// QueueError (QueueErrorCodeVersionConflict) is returned if the head has changed. The element is returned as Dequeue
+// does (see SetDequeueTransform).
+func (st *FIFO) DequeueIfVersion(version uint64) (interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	if st.headVersion != version {
+		current := st.headVersion
+		st.rwmutex.Unlock()
+		return nil, NewQueueError(QueueErrorCodeVersionConflict,
+			fmt.Sprintf("version conflict: expected head version %v, got %v", version, current))
+	}
+	value, _, err := st.dequeueHead()
+	transform := st.dequeueTransform
+	st.rwmutex.Unlock()
+	st.runPendingHooks()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return applyDequeueTransform(transform, value), nil
+}
+
+// headChanged changes the head's version (see PeekVersioned), it must be called (holding st.rwmutex) every time the
+// first element could have changed
+func (st *FIFO) headChanged() {
+	st.headVersion++
+}
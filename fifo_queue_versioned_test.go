@@ -0,0 +1,109 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ***************************************************************************************
+// ** PeekVersioned / DequeueIfVersion
+// ***************************************************************************************
+
+// single PeekVersioned / DequeueIfVersion lock verification
+func (suite *FIFOTestSuite) TestPeekVersionedLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, _, err := suite.fifo.PeekVersioned()
+	suite.Error(err, "Locked queue does not allow to peek elements")
+	_, err = suite.fifo.DequeueIfVersion(0)
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+}
+
+// the element gets dequeued if the head has not changed
+func (suite *FIFOTestSuite) TestDequeueIfVersionSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	value, version, err := suite.fifo.PeekVersioned()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "Wrong head")
+
+	// enqueuing at the tail does not change the head
+	suite.fifo.Enqueue(3)
+	value, err = suite.fifo.DequeueIfVersion(version)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "The peeked element must be dequeued")
+
+	_, err = suite.fifo.DequeueIfVersion(version)
+	suite.Error(err, "The head has changed")
+	queueError, ok := err.(*QueueError)
+	suite.True(ok, "QueueError expected")
+	suite.Equal(QueueErrorCodeVersionConflict, queueError.Code(), "Wrong error code")
+	suite.Equal(2, suite.fifo.GetLen(), "No element must be dequeued on conflict")
+}
+
+// every way of changing the head changes the version
+func (suite *FIFOTestSuite) TestPeekVersionedHeadChangesSingleGR() {
+	changes := []func(){
+		func() { suite.fifo.EnqueueAt(0, 0) },
+		func() { suite.fifo.Remove(0) },
+		func() { suite.fifo.DrainTail(suite.fifo.GetLen()) },
+		func() { suite.fifo.Clear() },
+		func() { suite.fifo.Walk(func(interface{}) WalkAction { return WalkRemove }) },
+		func() {
+			suite.fifo.ReplaceWhere(func(interface{}) bool { return true }, func(v interface{}) interface{} { return v })
+		},
+	}
+
+	for i, change := range changes {
+		suite.fifo.Clear()
+		suite.fifo.Enqueue(1)
+		suite.fifo.Enqueue(2)
+		_, version, _ := suite.fifo.PeekVersioned()
+		change()
+		_, current, _ := suite.fifo.PeekVersioned()
+		suite.NotEqual(version, current, "The version must change (change %v)", i)
+	}
+
+	// the element enqueued into an empty queue becomes the head
+	suite.fifo.Clear()
+	_, version, err := suite.fifo.PeekVersioned()
+	suite.Error(err, "Can't peek an empty queue")
+	suite.fifo.Enqueue(1)
+	_, err = suite.fifo.DequeueIfVersion(version)
+	suite.Error(err, "The head has changed")
+}
+
+// optimistic consumers: every element gets dequeued once
+func (suite *FIFOTestSuite) TestDequeueIfVersionMultipleGRs() {
+	const total = 200
+	for i := 0; i < total; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		dequeued int64
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				peeked, version, err := suite.fifo.PeekVersioned()
+				if err != nil {
+					return
+				}
+				value, err := suite.fifo.DequeueIfVersion(version)
+				if err != nil {
+					continue
+				}
+				suite.Equal(peeked, value, "The peeked element must be dequeued")
+				atomic.AddInt64(&dequeued, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(int64(total), dequeued, "Every element must be dequeued once")
+}
@@ -0,0 +1,41 @@
+package goconcurrentqueue
+
+// fifoReader is the consumer view of a FIFO, it hides the rest of the FIFO's methods
+type fifoReader struct {
+	fifo *FIFO
+}
+
+func (st fifoReader) Dequeue() (interface{}, error) {
+	return st.fifo.Dequeue()
+}
+
+func (st fifoReader) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.fifo.DequeueOrWaitForNextElement()
+}
+
+func (st fifoReader) Peek() (interface{}, error) {
+	return st.fifo.Peek()
+}
+
+func (st fifoReader) GetLen() int {
+	return st.fifo.GetLen()
+}
+
+// fifoWriter is the producer view of a FIFO, it hides the rest of the FIFO's methods
+type fifoWriter struct {
+	fifo *FIFO
+}
+
+func (st fifoWriter) Enqueue(value interface{}) error {
+	return st.fifo.Enqueue(value)
+}
+
+// Reader returns a consumer only view of the queue. The view can't be converted back into the *FIFO.
+func (st *FIFO) Reader() QueueReader {
+	return fifoReader{fifo: st}
+}
+
+// Writer returns a producer only view of the queue. The view can't be converted back into the *FIFO.
+func (st *FIFO) Writer() QueueWriter {
+	return fifoWriter{fifo: st}
+}
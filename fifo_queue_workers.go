@@ -0,0 +1,82 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// wait between dequeue attempts while the queue is locked, see StartWorkersWithRetry
+	workerLockedRetryWait = 100 * time.Millisecond
+)
+
+// RequeuePolicy defines where a failed element is re-enqueued (see FIFO.StartWorkersWithRetry)
+type RequeuePolicy int
+
+const (
+	// RequeueBack re-enqueues the failed elements at the tail of the queue
+	RequeueBack RequeuePolicy = iota
+	// RequeueFront re-enqueues the failed elements at the head of the queue, they will be the next to be processed
+	RequeueFront
+)
+
+// StartWorkersWithRetry starts n goroutines processing the enqueued elements using handler. Every element for which
+// handler returns a non nil error gets re-enqueued (at the head or the tail of the queue, see RequeuePolicy) to be
+// retried. Re-enqueued elements skip the lock and the key quota checks (they were already accepted by the queue).
+//
+// The returned function stops the workers, waiting for the running handlers to finish.
+func (st *FIFO) StartWorkersWithRetry(n int, handler func(interface{}) error, requeuePolicy RequeuePolicy) (stop func()) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		wg          sync.WaitGroup
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st.work(ctx, handler, requeuePolicy)
+		}()
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// work processes elements until ctx gets done
+func (st *FIFO) work(ctx context.Context, handler func(interface{}) error, requeuePolicy RequeuePolicy) {
+	matchAll := func(interface{}) bool { return true }
+
+	for {
+		value, transform, err := st.waitForValue(ctx, matchAll, true)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(workerLockedRetryWait):
+				// locked queue
+				continue
+			}
+		}
+
+		if err := handler(applyDequeueTransform(transform, value)); err != nil {
+			st.requeue(value, requeuePolicy == RequeueFront)
+		}
+	}
+}
+
+// requeue re-enqueues an element (exactly as it was stored) at the head or the tail of the queue
+func (st *FIFO) requeue(value interface{}, front bool) {
+	defer st.runPendingHooks()
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if front {
+		st.insertFront([]interface{}{value})
+	} else {
+		st.insertAt(len(st.slice), value)
+	}
+}
@@ -0,0 +1,108 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ***************************************************************************************
+// ** StartWorkersWithRetry
+// ***************************************************************************************
+
+// every element gets processed
+func (suite *FIFOTestSuite) TestStartWorkersWithRetryMultipleGRs() {
+	var (
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		processed = make(map[int]bool)
+		total     = 50
+	)
+
+	wg.Add(total)
+	stop := suite.fifo.StartWorkersWithRetry(5, func(value interface{}) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		processed[value.(int)] = true
+		wg.Done()
+		return nil
+	}, RequeueBack)
+	defer stop()
+
+	for i := 0; i < total; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	wg.Wait()
+
+	suite.Len(processed, total, "Every element must be processed")
+}
+
+// failed elements get retried
+func (suite *FIFOTestSuite) TestStartWorkersWithRetryFailuresMultipleGRs() {
+	for _, policy := range []RequeuePolicy{RequeueBack, RequeueFront} {
+		var (
+			attempts = make(chan interface{}, 10)
+			fifo     = NewFIFO()
+			total    int
+		)
+
+		fifo.Enqueue(testValue)
+		// single worker: no synchronization needed for total
+		stop := fifo.StartWorkersWithRetry(1, func(value interface{}) error {
+			total++
+			attempts <- value
+			if total < 3 {
+				return errors.New("transient error")
+			}
+			return nil
+		}, policy)
+
+		for i := 0; i < 3; i++ {
+			select {
+			case val := <-attempts:
+				suite.Equal(testValue, val, "Wrong element's value")
+			case <-time.After(time.Second):
+				suite.Fail("The failed element must be retried")
+			}
+		}
+		stop()
+		suite.Equal(0, fifo.GetLen(), "The element must not be re-enqueued once processed")
+	}
+}
+
+// requeue at the head or the tail
+func (suite *FIFOTestSuite) TestRequeueSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.requeue(0, true)
+	suite.fifo.requeue(2, false)
+
+	for i := 0; i < 3; i++ {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(i, val, "Wrong element's value")
+	}
+}
+
+// requeued elements are enqueued again (hooks, sequence numbers, ...)
+func (suite *FIFOTestSuite) TestRequeueEnqueueHookSingleGR() {
+	var hooked []interface{}
+	suite.fifo.SetEnqueueHook(func(value interface{}) {
+		hooked = append(hooked, value)
+	})
+	suite.fifo.requeue(1, true)
+	suite.fifo.requeue(2, false)
+
+	suite.Equal([]interface{}{1, 2}, hooked, "The enqueue hook must get the requeued elements")
+	startSeq, _ := suite.fifo.EnqueueBatchSeq(nil)
+	suite.Equal(uint64(2), startSeq, "The requeued elements must take sequence numbers")
+}
+
+// stop the workers
+func (suite *FIFOTestSuite) TestStartWorkersWithRetryStopSingleGR() {
+	stop := suite.fifo.StartWorkersWithRetry(3, func(interface{}) error { return nil }, RequeueBack)
+	stop()
+
+	suite.fifo.Enqueue(1)
+	time.Sleep(10 * time.Millisecond)
+	suite.Equal(1, suite.fifo.GetLen(), "No element must be processed once the workers are stopped")
+}
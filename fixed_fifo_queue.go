@@ -1,14 +1,151 @@
 package goconcurrentqueue
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
+const (
+	// initial wait between EnqueueOrWaitForSlotWithBackoff retries
+	enqueueBackoffMin = time.Millisecond
+	// maximum wait between EnqueueOrWaitForSlotWithBackoff retries
+	enqueueBackoffMax = 100 * time.Millisecond
+)
+
+// DequeuePauseMode defines how Dequeue behaves while dequeuing is paused (see FixedFIFO.PauseDequeue)
+type DequeuePauseMode int
+
+const (
+	// DequeuePauseModeBlock blocks Dequeue until dequeuing is resumed
+	DequeuePauseModeBlock DequeuePauseMode = iota
+	// DequeuePauseModeError makes Dequeue return an error while dequeuing is paused
+	DequeuePauseModeError
+)
+
+// FairnessMode defines how FixedFIFO balances enqueuers and dequeuers under contention (see FixedFIFO.SetFairnessMode)
+type FairnessMode int32
+
+const (
+	// FairnessNone does not balance enqueuers and dequeuers (default)
+	FairnessNone FairnessMode = iota
+	// FairnessBalanced alternates the preference between enqueuers and dequeuers: an operation of the same kind as the
+	// previous one yields the processor before taking place, giving the other kind a chance to run. It keeps the
+	// latency symmetric for bidirectional workloads at the cost of throughput: every run of consecutive operations of
+	// the same kind (e.g. a burst of enqueues) pays an extra runtime.Gosched() per operation.
+	FairnessBalanced
+)
+
+// OverflowPolicy defines what FixedFIFO.Enqueue does once the queue is at full capacity (see FixedFIFO.SetOverflowPolicy)
+type OverflowPolicy int32
+
+const (
+	// OverflowReject rejects the new element returning an error (default)
+	OverflowReject OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest enqueued element to make room for the new one
+	OverflowDropOldest
+	// OverflowDropNewest drops the new element (no error is returned)
+	OverflowDropNewest
+)
+
+const (
+	fixedFIFOOperationEnqueue int32 = iota + 1
+	fixedFIFOOperationDequeue
+)
+
+// errFixedFIFOEmpty is returned if there is no element to be dequeued. It is preallocated: polling an empty queue (see
+// DequeueOrDefault) does not allocate.
+var errFixedFIFOEmpty = errors.New("queue is empty")
+
 // Fixed capacity FIFO (First In First Out) concurrent queue
 type FixedFIFO struct {
+	// enqueue/dequeue rates (lock-free, 64-bit aligned), see Throughput
+	enqueueRate rateCounter
+	dequeueRate rateCounter
+	// 64-bit counters (accessed atomically) are kept first to guarantee their alignment on 32-bit platforms
+	enqueuedTotal  uint64
+	dequeuedTotal  uint64
+	enqueueWaiters int64
+	dequeueWaiters int64
+	// max concurrent dequeue waiters (<= 0 == unlimited), see SetMaxWaiters
+	maxWaiters int64
+	// slots reserved for future enqueues (modified holding enqueueRWMutex (write), accessed atomically), see ReserveSlot
+	reservedSlots int64
+	// elements dropped by the overflow policy
+	droppedTotal uint64
+	// rejected enqueues by reason, see RejectionStats
+	rejections [fixedRejectionReasonCount]uint64
+	// enqueued elements, updated once every element gets into/out of the channel (a receive could be counted before
+	// its send), see GetLen
+	length int64
+	// auto lock threshold (time.Duration, <= 0 == disabled) && time the queue got full (UnixNano, 0 == not full),
+	// see SetAutoLockOnFull
+	autoLockOnFull int64
+	fullSince      int64
+	// max age (time.Duration, <= 0 == disabled), see SetMaxAge
+	maxAge int64
+	// OverflowPolicy, accessed atomically
+	overflowPolicy int32
+	// FairnessMode && last operation (fixedFIFOOperation...), accessed atomically
+	fairnessMode  int32
+	lastOperation int32
+	// 1 == closed (accessed atomically), see Close
+	closed int32
+
 	queue    chan interface{}
 	lockChan chan struct{}
+	// held (read) by single element enqueues, held (write) by batch enqueues to avoid interleaving
+	enqueueRWMutex sync.RWMutex
+	// dequeue pause/resume
+	pauseRWMutex sync.RWMutex
+	// closed while dequeuing is not paused
+	resumeChan chan struct{}
+	pauseMode  DequeuePauseMode
+	// metrics, see WriteMetrics
+	metricsRWMutex sync.RWMutex
+	metricPrefix   string
+	// length samples, see StartLengthSampling
+	lengthSampler lengthSampler
+	// time provider (clockHolder), see SetClock
+	clock atomic.Value
+	// max age: expired elements callback && sweeper, see SetMaxAge
+	maxAgeMutex   sync.Mutex
+	onExpire      func(interface{})
+	sweepInterval time.Duration
+	sweepStop     chan struct{}
+	// held (read) by dequeues, held (write) by the sweeper, see lockSweep
+	sweepRWMutex sync.RWMutex
+	// closed to wake up the consumers waiting while holding sweepRWMutex (read), see lockSweep
+	sweepWakeMutex sync.Mutex
+	sweepWake      chan struct{}
+	// function to be called for every discarded element, see SetDeadLetterHandler
+	deadLetterRWMutex sync.RWMutex
+	deadLetterHandler func(value interface{}, reason string)
+	// 1 == the waiting operations get tracked (accessed atomically) && the waiting ones by id, see SetTrackWaiters
+	trackWaiters int32
+	waitersMutex sync.Mutex
+	waiters      map[uint64]WaiterInfo
+	nextWaiterID uint64
+	// closed (and set to nil) once the queue becomes non-empty, created by the first waiter, see WaitNonEmpty
+	nonEmptyMutex sync.Mutex
+	nonEmpty      chan struct{}
+	// 1 == watermarks set (accessed atomically) && the callbacks (called one at a time), see SetWatermarks
+	watermarks         int32
+	watermarksMutex    sync.Mutex
+	highWatermark      int
+	lowWatermark       int
+	onHighWatermark    func()
+	onLowWatermark     func()
+	aboveHighWatermark bool
+	pendingWatermarks  []func()
+	callingWatermarks  bool
+	// element budget shared with other queues (*BoundedGroup, if any), see BoundedGroup.Register
+	group atomic.Value
 }
 
 func NewFixedFIFO(capacity int) *FixedFIFO {
@@ -21,18 +158,434 @@ func NewFixedFIFO(capacity int) *FixedFIFO {
 func (st *FixedFIFO) initialize(capacity int) {
 	st.queue = make(chan interface{}, capacity)
 	st.lockChan = make(chan struct{}, 1)
+	st.resumeChan = make(chan struct{})
+	close(st.resumeChan)
+	st.sweepWake = make(chan struct{})
+	st.metricPrefix = defaultMetricPrefix
+	st.clock.Store(clockHolder{realClock{}})
 }
 
 func (st *FixedFIFO) Enqueue(value interface{}) error {
+	defer st.checkWatermarks()
+
 	if st.IsLocked() {
-		return errors.New("The queue is locked")
+		return st.rejectLocked()
 	}
 
-	select {
-	case st.queue <- value:
+	if st.isAutoLocked() {
+		return st.rejectAutoLocked()
+	}
+
+	if st.tryEnqueue(value) {
 		return nil
+	}
+
+	if st.IsClosed() {
+		return st.rejectClosed()
+	}
+
+	switch OverflowPolicy(atomic.LoadInt32(&st.overflowPolicy)) {
+	case OverflowDropNewest:
+		atomic.AddUint64(&st.droppedTotal, 1)
+		st.discard(DeadLetterReasonOverflow, value)
+		return nil
+
+	case OverflowDropOldest:
+		dropped, err := st.enqueueDroppingOldest(value)
+		st.discard(DeadLetterReasonOverflow, dropped...)
+		return err
+	}
+
+	if st.getGroup() != nil && st.availableSlots() > 0 {
+		return st.rejectGroupFull()
+	}
+
+	return st.rejectFull()
+}
+
+// enqueueDroppingOldest enqueues the value dropping the oldest elements to make room for it, it returns the dropped ones
+func (st *FixedFIFO) enqueueDroppingOldest(value interface{}) ([]interface{}, error) {
+	// the sweeper must not drain the queue while the oldest elements are being dropped
+	st.sweepRWMutex.RLock()
+	defer st.sweepRWMutex.RUnlock()
+
+	var dropped []interface{}
+	// no room could be made in a zero capacity queue
+	for cap(st.queue) > 0 {
+		select {
+		case stored, ok := <-st.queue:
+			if !ok {
+				return dropped, st.rejectClosed()
+			}
+			atomic.AddUint64(&st.droppedTotal, 1)
+			st.countRemoved(1)
+			oldest, _ := st.unwrapAged(stored)
+			dropped = append(dropped, oldest)
+		default:
+			if atomic.LoadInt64(&st.reservedSlots) >= int64(cap(st.queue)) {
+				// nothing could ever be dropped: all the slots are reserved (see ReserveSlot)
+				return dropped, st.rejectFull()
+			}
+			if group := st.getGroup(); group != nil && group.GetLen() >= group.GetCap() {
+				// nothing to drop from this queue to get the budget back
+				return dropped, st.rejectGroupFull()
+			}
+		}
+
+		if st.tryEnqueue(value) {
+			return dropped, nil
+		}
+	}
+
+	return dropped, st.rejectFull()
+}
+
+// SetOverflowPolicy sets what Enqueue does once the queue is at full capacity. Default: OverflowReject.
+func (st *FixedFIFO) SetOverflowPolicy(policy OverflowPolicy) {
+	atomic.StoreInt32(&st.overflowPolicy, int32(policy))
+}
+
+// DroppedCount returns the total number of elements dropped by the overflow policy (see SetOverflowPolicy)
+func (st *FixedFIFO) DroppedCount() uint64 {
+	return atomic.LoadUint64(&st.droppedTotal)
+}
+
+// ResetDroppedCount resets the dropped elements counter (see DroppedCount)
+func (st *FixedFIFO) ResetDroppedCount() {
+	atomic.StoreUint64(&st.droppedTotal, 0)
+}
+
+// SetAutoLockOnFull makes the queue reject the enqueues once it has been at full capacity for longer than duration.
+// The auto lock gets lifted as soon as a consumer dequeues an element. It only affects the enqueues (the consumers
+// must be able to drain the queue) and it is independent from Lock/Unlock: Unlock does not lift the auto lock and
+// lifting the auto lock does not unlock a locked queue. A duration <= 0 disables it (default).
+func (st *FixedFIFO) SetAutoLockOnFull(duration time.Duration) {
+	atomic.StoreInt64(&st.autoLockOnFull, int64(duration))
+	atomic.StoreInt64(&st.fullSince, 0)
+	st.trackFullness()
+}
+
+// IsAutoLocked returns true whether the enqueues are being rejected because the queue has been at full capacity for
+// too long, see SetAutoLockOnFull
+func (st *FixedFIFO) IsAutoLocked() bool {
+	return st.isAutoLocked()
+}
+
+// isAutoLocked returns true whether the queue has been at full capacity for longer than the auto lock threshold
+func (st *FixedFIFO) isAutoLocked() bool {
+	threshold := time.Duration(atomic.LoadInt64(&st.autoLockOnFull))
+	if threshold <= 0 {
+		return false
+	}
+
+	if len(st.queue) < cap(st.queue) {
+		// auto unlock
+		atomic.StoreInt64(&st.fullSince, 0)
+		return false
+	}
+
+	now := st.getClock().Now().UnixNano()
+	fullSince := atomic.LoadInt64(&st.fullSince)
+	if fullSince == 0 {
+		atomic.CompareAndSwapInt64(&st.fullSince, 0, now)
+		return false
+	}
+
+	return time.Duration(now-fullSince) >= threshold
+}
+
+// trackFullness records the time the queue got full, if the auto lock is enabled (see SetAutoLockOnFull)
+func (st *FixedFIFO) trackFullness() {
+	if atomic.LoadInt64(&st.autoLockOnFull) <= 0 || len(st.queue) < cap(st.queue) {
+		return
+	}
+
+	atomic.CompareAndSwapInt64(&st.fullSince, 0, st.getClock().Now().UnixNano())
+}
+
+// tryEnqueue enqueues an element without blocking, returning false if the queue is at full capacity (or closed)
+func (st *FixedFIFO) tryEnqueue(value interface{}) bool {
+	st.takeTurn(fixedFIFOOperationEnqueue)
+
+	st.enqueueRWMutex.RLock()
+	// no slot could be reserved while holding the read lock
+	if atomic.LoadInt64(&st.reservedSlots) == 0 {
+		defer st.enqueueRWMutex.RUnlock()
+		return st.tryEnqueueLocked(value)
+	}
+	st.enqueueRWMutex.RUnlock()
+
+	// the available slots (reserved ones excluded) must be checked exclusively
+	st.enqueueRWMutex.Lock()
+	defer st.enqueueRWMutex.Unlock()
+
+	return st.tryEnqueueLocked(value)
+}
+
+// tryEnqueueLocked is tryEnqueue for callers already holding st.enqueueRWMutex: write, or read if no slot is reserved
+func (st *FixedFIFO) tryEnqueueLocked(value interface{}) bool {
+	if st.IsClosed() || st.availableSlots() <= 0 {
+		return false
+	}
+
+	group := st.getGroup()
+	if !group.reserve(1) {
+		return false
+	}
+	if !st.sendLocked(value) {
+		group.release(1)
+		return false
+	}
+
+	return true
+}
+
+// sendLocked sends an element to the channel without blocking, its group's budget (if any) must be already reserved.
+// It must be called holding st.enqueueRWMutex and the queue must not be closed.
+func (st *FixedFIFO) sendLocked(value interface{}) bool {
+	select {
+	case st.queue <- st.wrapAged(value):
+		st.countEnqueued(1)
+		st.trackFullness()
+		return true
 	default:
-		return errors.New("FixedFIFO queue is at full capacity")
+		return false
+	}
+}
+
+// EnqueueIfDownstreamReady enqueues an element only if downstream is not at full capacity, returning whether it was
+// enqueued. No element could be enqueued into downstream while checking it (its enqueues wait), so downstream keeps
+// having an available slot at least until the element gets enqueued. The overflow policy is not applied: an error is
+// returned if this queue is at full capacity.
+func (st *FixedFIFO) EnqueueIfDownstreamReady(value interface{}, downstream *FixedFIFO) (bool, error) {
+	if st == downstream {
+		return false, errors.New("the downstream queue must be a different queue")
+	}
+
+	if st.IsLocked() {
+		return false, st.rejectLocked()
+	}
+
+	if st.isAutoLocked() {
+		return false, st.rejectAutoLocked()
+	}
+
+	unlock := lockEnqueuePair(st, downstream)
+	if downstream.availableSlots() <= 0 {
+		unlock()
+		return false, nil
+	}
+	enqueued := st.tryEnqueueLocked(value)
+	unlock()
+	st.checkWatermarks()
+
+	if !enqueued {
+		if st.IsClosed() {
+			return false, st.rejectClosed()
+		}
+		return false, st.rejectFull()
+	}
+
+	return true, nil
+}
+
+// lockEnqueuePair locks both queues' enqueues (for writing: no element could be enqueued in the meantime), following a
+// consistent order to avoid deadlocks. It returns the function to unlock them.
+func lockEnqueuePair(st, other *FixedFIFO) func() {
+	first, second := st, other
+	if uintptr(unsafe.Pointer(second)) < uintptr(unsafe.Pointer(first)) {
+		first, second = second, first
+	}
+
+	first.enqueueRWMutex.Lock()
+	second.enqueueRWMutex.Lock()
+
+	return func() {
+		second.enqueueRWMutex.Unlock()
+		first.enqueueRWMutex.Unlock()
+	}
+}
+
+// availableSlots returns the number of slots available for new elements: neither used nor reserved (see ReserveSlot)
+func (st *FixedFIFO) availableSlots() int {
+	return cap(st.queue) - len(st.queue) - int(atomic.LoadInt64(&st.reservedSlots))
+}
+
+// ReserveSlot reserves a slot for an element to be enqueued later, returning an error if there is no available slot:
+// commit enqueues the element into the reserved slot (it never fails, even if the queue got locked in the meantime),
+// cancel releases the slot. Only the first call to commit or cancel takes effect. The reserved slots are not available
+// to other enqueues (they count as used), but they are not counted by GetLen until committed. Committing into a closed
+// queue drops the element.
+func (st *FixedFIFO) ReserveSlot() (commit func(interface{}), cancel func(), err error) {
+	if st.IsLocked() {
+		return nil, nil, st.rejectLocked()
+	}
+
+	if st.isAutoLocked() {
+		return nil, nil, st.rejectAutoLocked()
+	}
+
+	st.enqueueRWMutex.Lock()
+	defer st.enqueueRWMutex.Unlock()
+
+	if st.IsClosed() {
+		return nil, nil, st.rejectClosed()
+	}
+
+	if st.availableSlots() <= 0 {
+		return nil, nil, st.rejectFull()
+	}
+	if !st.getGroup().reserve(1) {
+		return nil, nil, st.rejectGroupFull()
+	}
+	atomic.AddInt64(&st.reservedSlots, 1)
+
+	var once sync.Once
+	commit = func(value interface{}) {
+		once.Do(func() {
+			defer st.checkWatermarks()
+			st.enqueueRWMutex.Lock()
+			defer st.enqueueRWMutex.Unlock()
+
+			atomic.AddInt64(&st.reservedSlots, -1)
+			// there is room for it (the slot and its group's budget were reserved), unless the queue got closed
+			if st.IsClosed() || !st.sendLocked(value) {
+				st.getGroup().release(1)
+			}
+		})
+	}
+	cancel = func() {
+		once.Do(func() {
+			st.enqueueRWMutex.Lock()
+			defer st.enqueueRWMutex.Unlock()
+
+			atomic.AddInt64(&st.reservedSlots, -1)
+			st.getGroup().release(1)
+		})
+	}
+
+	return commit, cancel, nil
+}
+
+// EnqueueBatchOrWait enqueues all the given elements at once (in order, without other elements in between) waiting
+// until there are enough available slots for all of them. An error will be returned if ctx gets done before.
+func (st *FixedFIFO) EnqueueBatchOrWait(ctx context.Context, values []interface{}) error {
+	if len(values) > cap(st.queue) {
+		return fmt.Errorf("the batch exceeds the queue's capacity: %v > %v", len(values), cap(st.queue))
+	}
+
+	var (
+		backoff = enqueueBackoffMin
+		// see SetTrackWaiters
+		untrack func()
+	)
+	for {
+		if st.IsLocked() {
+			return st.rejectLocked()
+		}
+
+		if st.isAutoLocked() {
+			return st.rejectAutoLocked()
+		}
+
+		if st.IsClosed() {
+			return st.rejectClosed()
+		}
+
+		if st.tryEnqueueBatch(values) {
+			st.checkWatermarks()
+			return nil
+		}
+
+		if untrack == nil {
+			untrack = st.trackWaiter(WaiterOperationEnqueueSlot)
+			defer untrack()
+		}
+		atomic.AddInt64(&st.enqueueWaiters, 1)
+		select {
+		case <-ctx.Done():
+			atomic.AddInt64(&st.enqueueWaiters, -1)
+			return ctx.Err()
+		case <-st.getClock().After(backoff):
+			atomic.AddInt64(&st.enqueueWaiters, -1)
+		}
+
+		backoff *= 2
+		if backoff > enqueueBackoffMax {
+			backoff = enqueueBackoffMax
+		}
+	}
+}
+
+// tryEnqueueBatch enqueues all the given elements if there are enough available slots, returning false otherwise
+func (st *FixedFIFO) tryEnqueueBatch(values []interface{}) bool {
+	// no other enqueue could take place in the meantime
+	st.enqueueRWMutex.Lock()
+	defer st.enqueueRWMutex.Unlock()
+
+	if st.IsClosed() || st.availableSlots() < len(values) || !st.getGroup().reserve(len(values)) {
+		return false
+	}
+
+	for _, value := range values {
+		st.queue <- st.wrapAged(value)
+	}
+	st.countEnqueued(len(values))
+	st.trackFullness()
+
+	return true
+}
+
+// EnqueueOrWaitForSlotWithBackoff enqueues an element, retrying while the queue is at full capacity.
+// The wait between retries grows exponentially (from enqueueBackoffMin up to enqueueBackoffMax).
+// An error will be returned if no slot gets available after maxWait.
+func (st *FixedFIFO) EnqueueOrWaitForSlotWithBackoff(value interface{}, maxWait time.Duration) error {
+	var (
+		clock    = st.getClock()
+		deadline = clock.Now().Add(maxWait)
+		backoff  = enqueueBackoffMin
+		// see SetTrackWaiters
+		untrack func()
+	)
+
+	for {
+		if st.IsLocked() {
+			return st.rejectLocked()
+		}
+
+		if st.isAutoLocked() {
+			return st.rejectAutoLocked()
+		}
+
+		if st.IsClosed() {
+			return st.rejectClosed()
+		}
+
+		if st.tryEnqueue(value) {
+			st.checkWatermarks()
+			return nil
+		}
+
+		remaining := deadline.Sub(clock.Now())
+		if remaining <= 0 {
+			return fmt.Errorf("timeout waiting for an available slot after %v", maxWait)
+		}
+
+		if backoff > remaining {
+			backoff = remaining
+		}
+		if untrack == nil {
+			untrack = st.trackWaiter(WaiterOperationEnqueueSlot)
+			defer untrack()
+		}
+		atomic.AddInt64(&st.enqueueWaiters, 1)
+		<-clock.After(backoff)
+		atomic.AddInt64(&st.enqueueWaiters, -1)
+
+		backoff *= 2
+		if backoff > enqueueBackoffMax {
+			backoff = enqueueBackoffMax
+		}
 	}
 }
 
@@ -41,33 +594,365 @@ func (st *FixedFIFO) Dequeue() (interface{}, error) {
 		return nil, errors.New("The queue is locked")
 	}
 
+	if err := st.waitWhileDequeuePaused(context.Background()); err != nil {
+		return nil, err
+	}
+
+	st.takeTurn(fixedFIFOOperationDequeue)
+
+	return st.tryDequeue()
+}
+
+// DequeueOrDefault dequeues an element, returning def instead if no element could be dequeued (empty, locked or paused
+// queue). It neither blocks nor allocates if there is no element.
+func (st *FixedFIFO) DequeueOrDefault(def interface{}) interface{} {
+	if st.IsLocked() || st.IsDequeuePaused() {
+		return def
+	}
+
+	st.takeTurn(fixedFIFOOperationDequeue)
+
+	value, err := st.tryDequeue()
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// DequeueOrWaitForNextElement dequeues an element, waiting for a new element if the queue is empty
+func (st *FixedFIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementContext dequeues an element, waiting for a new element if the queue is empty. An error
+// will be returned if ctx gets done before. The lock is checked before waiting: locking the queue does not wake up the
+// waiting consumers.
+func (st *FixedFIFO) DequeueOrWaitForNextElementContext(ctx context.Context) (interface{}, error) {
+	for {
+		if st.IsLocked() {
+			return nil, errors.New("The queue is locked")
+		}
+
+		if err := st.waitWhileDequeuePaused(ctx); err != nil {
+			return nil, err
+		}
+
+		st.takeTurn(fixedFIFOOperationDequeue)
+
+		st.sweepRWMutex.RLock()
+		value, expired, err := st.tryDequeueUnexpired()
+		if err == errFixedFIFOEmpty {
+			var waitExpired []interface{}
+			value, waitExpired, err = st.waitForNextElement(ctx)
+			expired = append(expired, waitExpired...)
+		}
+		st.sweepRWMutex.RUnlock()
+
+		st.expire(expired)
+		st.checkWatermarks()
+		if err == errFixedFIFOEmpty {
+			// woken up by the sweeper (or an expired element was received): try again
+			continue
+		}
+		return value, err
+	}
+}
+
+// waitForNextElement waits for the next element, returning errFixedFIFOEmpty if the received element has expired or
+// the sweeper needs st.sweepRWMutex. It must be called holding st.sweepRWMutex (read).
+func (st *FixedFIFO) waitForNextElement(ctx context.Context) (interface{}, []interface{}, error) {
+	if err := st.addDequeueWaiter(); err != nil {
+		return nil, nil, err
+	}
+	defer atomic.AddInt64(&st.dequeueWaiters, -1)
+	defer st.trackWaiter(WaiterOperationDequeue)()
+
 	select {
-	case value, ok := <-st.queue:
-		if ok {
-			return value, nil
+	case stored, ok := <-st.queue:
+		if !ok {
+			return nil, nil, errors.New("internal channel is closed")
+		}
+
+		value, expired := st.unwrapAged(stored)
+		if expired {
+			st.countRemoved(1)
+			return nil, []interface{}{value}, errFixedFIFOEmpty
+		}
+		st.countDequeued(1)
+		return value, nil, nil
+	case <-st.sweepWake:
+		return nil, nil, errFixedFIFOEmpty
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// SetMaxWaiters limits the number of consumers concurrently waiting to dequeue (DequeueOrWaitForNextElement...,
+// paused dequeues): once n consumers are waiting, the next ones get a QueueError (QueueErrorCodeTooManyWaiters) instead
+// of waiting. The waiters neither spawn goroutines nor timers, but every one of them keeps its caller's goroutine
+// blocked. n <= 0 means unlimited (default).
+func (st *FixedFIFO) SetMaxWaiters(n int) {
+	atomic.StoreInt64(&st.maxWaiters, int64(n))
+}
+
+// addDequeueWaiter registers a new dequeue waiter, returning an error if there are already too many of them
+func (st *FixedFIFO) addDequeueWaiter() error {
+	waiters := atomic.AddInt64(&st.dequeueWaiters, 1)
+	if max := atomic.LoadInt64(&st.maxWaiters); max > 0 && waiters > max {
+		atomic.AddInt64(&st.dequeueWaiters, -1)
+		return NewQueueError(QueueErrorCodeTooManyWaiters, fmt.Sprintf("too many waiters (max: %v)", max))
+	}
+
+	return nil
+}
+
+// Channel returns a channel receiving the dequeued elements (fed by a goroutine waiting for them), e.g. to range over
+// the queue. The channel gets closed once ctx is done or the queue is closed (and drained). An element dequeued while
+// ctx gets done is enqueued back (at the tail), or discarded through the dead letter handler if it could not be (see
+// SetDeadLetterHandler). A locked (or paused) queue makes the goroutine wait until it gets unlocked.
+func (st *FixedFIFO) Channel(ctx context.Context) <-chan interface{} {
+	ch := make(chan interface{})
+
+	go func() {
+		defer close(ch)
+
+		for {
+			value, err := st.DequeueOrWaitForNextElementContext(ctx)
+			if err != nil {
+				if ctx.Err() != nil || (st.IsClosed() && st.GetLen() == 0) {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(workerLockedRetryWait):
+					// locked (or paused) queue
+					continue
+				}
+			}
+
+			select {
+			case ch <- value:
+			case <-ctx.Done():
+				st.requeue(value)
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// TryDequeueN dequeues up to max elements without blocking. An empty slice is returned if no element could be dequeued
+// (empty, locked or paused queue).
+func (st *FixedFIFO) TryDequeueN(max int) []interface{} {
+	if max <= 0 || st.IsLocked() || st.IsDequeuePaused() {
+		return []interface{}{}
+	}
+
+	ret := make([]interface{}, 0, max)
+	for len(ret) < max {
+		value, err := st.tryDequeue()
+		if err != nil {
+			return ret
 		}
-		return nil, errors.New("internal channel is closed")
+		ret = append(ret, value)
+	}
+
+	return ret
+}
+
+// ToFIFO moves all the enqueued elements (keeping their order) into a new unbounded FIFO, leaving this queue empty. No
+// other element could be enqueued while the elements are being moved. It works even if the queue is locked or dequeuing
+// is paused, and this queue remains usable afterward.
+func (st *FixedFIFO) ToFIFO() *FIFO {
+	// same order as the max age sweeper
+	st.lockSweep()
+	st.enqueueRWMutex.Lock()
+
+	var (
+		fifo    = NewFIFO()
+		expired []interface{}
+	)
+	for {
+		value, dropped, err := st.tryDequeueUnexpired()
+		expired = append(expired, dropped...)
+		if err != nil {
+			break
+		}
+		fifo.slice = append(fifo.slice, value)
+	}
+
+	st.enqueueRWMutex.Unlock()
+	st.sweepRWMutex.Unlock()
+	st.expire(expired)
+	st.checkWatermarks()
+
+	return fifo
+}
+
+// PauseDequeue pauses dequeuing, elements could still be enqueued until the queue gets full.
+// Dequeue will either block or return an error (see SetDequeuePauseMode) until ResumeDequeue is called.
+func (st *FixedFIFO) PauseDequeue() {
+	st.pauseRWMutex.Lock()
+	defer st.pauseRWMutex.Unlock()
+
+	select {
+	case <-st.resumeChan:
+		// not paused yet
+		st.resumeChan = make(chan struct{})
+	default:
+	}
+}
+
+// ResumeDequeue resumes dequeuing, releasing all blocked Dequeue calls
+func (st *FixedFIFO) ResumeDequeue() {
+	st.pauseRWMutex.Lock()
+	defer st.pauseRWMutex.Unlock()
+
+	select {
+	case <-st.resumeChan:
+		// not paused
+	default:
+		close(st.resumeChan)
+	}
+}
+
+// IsDequeuePaused returns true whether dequeuing is paused
+func (st *FixedFIFO) IsDequeuePaused() bool {
+	st.pauseRWMutex.RLock()
+	defer st.pauseRWMutex.RUnlock()
+
+	select {
+	case <-st.resumeChan:
+		return false
+	default:
+		return true
+	}
+}
+
+// SetDequeuePauseMode sets how Dequeue behaves while dequeuing is paused. Default: DequeuePauseModeBlock.
+func (st *FixedFIFO) SetDequeuePauseMode(mode DequeuePauseMode) {
+	st.pauseRWMutex.Lock()
+	defer st.pauseRWMutex.Unlock()
+
+	st.pauseMode = mode
+}
+
+// waitWhileDequeuePaused blocks until dequeuing gets resumed, or returns an error if the pause mode is DequeuePauseModeError
+// or ctx gets done before
+func (st *FixedFIFO) waitWhileDequeuePaused(ctx context.Context) error {
+	st.pauseRWMutex.RLock()
+	resumeChan, mode := st.resumeChan, st.pauseMode
+	st.pauseRWMutex.RUnlock()
+
+	select {
+	case <-resumeChan:
+		return nil
 	default:
-		return nil, fmt.Errorf("queue is empty")
 	}
+
+	if mode == DequeuePauseModeError {
+		return errors.New("dequeue is paused")
+	}
+
+	if err := st.addDequeueWaiter(); err != nil {
+		return err
+	}
+	defer atomic.AddInt64(&st.dequeueWaiters, -1)
+	defer st.trackWaiter(WaiterOperationDequeue)()
+
+	select {
+	case <-resumeChan:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetClock sets the time provider for the time based features (throughput, waits, ...). A nil clock restores the real
+// time (default).
+func (st *FixedFIFO) SetClock(clock Clock) {
+	if clock == nil {
+		clock = realClock{}
+	}
+	st.clock.Store(clockHolder{clock})
+}
+
+// getClock returns the time provider
+func (st *FixedFIFO) getClock() Clock {
+	holder, ok := st.clock.Load().(clockHolder)
+	if !ok {
+		return realClock{}
+	}
+
+	return holder.clock
+}
+
+// SetFairnessMode sets how enqueuers and dequeuers are balanced under contention. Default: FairnessNone.
+func (st *FixedFIFO) SetFairnessMode(mode FairnessMode) {
+	atomic.StoreInt32(&st.fairnessMode, int32(mode))
 }
 
-// GetLen returns queue's length (total enqueued elements)
+// takeTurn yields the processor if the fairness mode is FairnessBalanced and the previous operation was of the same
+// kind as the given one
+func (st *FixedFIFO) takeTurn(operation int32) {
+	if FairnessMode(atomic.LoadInt32(&st.fairnessMode)) != FairnessBalanced {
+		return
+	}
+
+	if atomic.SwapInt32(&st.lastOperation, operation) == operation {
+		runtime.Gosched()
+	}
+}
+
+// GetLen returns queue's length (total enqueued elements). It is lock-free: it reads a counter updated by every
+// enqueue/dequeue, so it neither waits for the running operations nor unlocks a locked queue.
 func (st *FixedFIFO) GetLen() int {
-	st.Lock()
-	defer st.Unlock()
+	length := int(atomic.LoadInt64(&st.length))
+	// the element being dequeued could be counted before its enqueue (or the other way around)
+	if length < 0 {
+		return 0
+	}
+	if length > cap(st.queue) {
+		return cap(st.queue)
+	}
 
-	return len(st.queue)
+	return length
 }
 
-// GetCap returns the queue's capacity
+// GetCap returns the queue's capacity. A locked queue remains locked.
 func (st *FixedFIFO) GetCap() int {
-	st.Lock()
-	defer st.Unlock()
-
 	return cap(st.queue)
 }
 
+// GetLenAndCap returns both queue's length (see GetLen) and capacity. A locked queue remains locked.
+func (st *FixedFIFO) GetLenAndCap() (int, int) {
+	return st.GetLen(), cap(st.queue)
+}
+
+// Close closes the queue: no more elements could be enqueued. The already enqueued elements could still be dequeued,
+// Dequeue returns an error once all of them are gone. Closing an already closed queue returns an error.
+func (st *FixedFIFO) Close() error {
+	// no enqueue could be sending to the channel
+	st.enqueueRWMutex.Lock()
+	defer st.enqueueRWMutex.Unlock()
+
+	if !atomic.CompareAndSwapInt32(&st.closed, 0, 1) {
+		return errors.New("The queue is already closed")
+	}
+	close(st.queue)
+
+	return nil
+}
+
+// IsClosed returns true whether the queue was closed (see Close)
+func (st *FixedFIFO) IsClosed() bool {
+	return atomic.LoadInt32(&st.closed) == 1
+}
+
 func (st *FixedFIFO) Lock() {
 	// non-blocking fill the channel
 	select {
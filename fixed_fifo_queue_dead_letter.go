@@ -0,0 +1,35 @@
+package goconcurrentqueue
+
+// SetDeadLetterHandler sets a function to be called every time an element gets discarded: dropped by the overflow
+// policy (reason: DeadLetterReasonOverflow, see SetOverflowPolicy), expired (reason: DeadLetterReasonExpired, see
+// SetMaxAge) or not enqueued back after being dequeued by a worker (reason: DeadLetterReasonRequeueFailed, see FanIn
+// and Channel). The handler is executed outside the queue's locks. A nil handler disables it.
+func (st *FixedFIFO) SetDeadLetterHandler(handler func(value interface{}, reason string)) {
+	st.deadLetterRWMutex.Lock()
+	defer st.deadLetterRWMutex.Unlock()
+
+	st.deadLetterHandler = handler
+}
+
+// discard executes the dead letter handler (if any) for the given values
+func (st *FixedFIFO) discard(reason string, values ...interface{}) {
+	st.deadLetterRWMutex.RLock()
+	handler := st.deadLetterHandler
+	st.deadLetterRWMutex.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	for _, value := range values {
+		handler(value, reason)
+	}
+}
+
+// requeue enqueues back (at the tail) an already dequeued value, it gets discarded (reason:
+// DeadLetterReasonRequeueFailed) if it could not be enqueued (e.g. full, locked or closed queue)
+func (st *FixedFIFO) requeue(value interface{}) {
+	if st.Enqueue(value) != nil {
+		st.discard(DeadLetterReasonRequeueFailed, value)
+	}
+}
@@ -0,0 +1,217 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// agedElement is the element stored at FixedFIFO's channel while max age is enabled, see FixedFIFO.SetMaxAge
+type agedElement struct {
+	value      interface{}
+	enqueuedAt time.Time
+}
+
+// SetMaxAge makes the queue drop the elements that have been enqueued for d or longer, calling onExpire (if not nil)
+// for each one of them. The expired elements are removed by a background sweeper (see SetMaxAgeSweepInterval) and they
+// are never returned by Dequeue/TryDequeueN. Dequeues wait while the sweeper runs, so the order is preserved.
+//
+// The elements enqueued while max age was disabled never expire. A d <= 0 disables max age and stops the sweeper
+// (default), it must be called to release the sweeper once the queue is not used anymore.
+func (st *FixedFIFO) SetMaxAge(d time.Duration, onExpire func(interface{})) {
+	st.maxAgeMutex.Lock()
+	defer st.maxAgeMutex.Unlock()
+
+	st.onExpire = onExpire
+	atomic.StoreInt64(&st.maxAge, int64(d))
+	st.restartSweeper()
+}
+
+// SetMaxAgeSweepInterval sets how often the sweeper looks for expired elements (see SetMaxAge). An interval <= 0 makes
+// it run every max age (default). The interval is measured using the real time, no matter the clock (see SetClock).
+func (st *FixedFIFO) SetMaxAgeSweepInterval(interval time.Duration) {
+	st.maxAgeMutex.Lock()
+	defer st.maxAgeMutex.Unlock()
+
+	st.sweepInterval = interval
+	st.restartSweeper()
+}
+
+// restartSweeper stops the running sweeper (if any) and starts a new one if max age is enabled. It must be called
+// holding st.maxAgeMutex.
+func (st *FixedFIFO) restartSweeper() {
+	if st.sweepStop != nil {
+		close(st.sweepStop)
+		st.sweepStop = nil
+	}
+
+	maxAge := time.Duration(atomic.LoadInt64(&st.maxAge))
+	if maxAge <= 0 {
+		return
+	}
+
+	interval := st.sweepInterval
+	if interval <= 0 {
+		interval = maxAge
+	}
+	st.sweepStop = make(chan struct{})
+	go st.sweep(interval, st.sweepStop)
+}
+
+// sweep removes the expired elements every interval, until stop gets closed
+func (st *FixedFIFO) sweep(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			st.removeExpired()
+		}
+	}
+}
+
+// SweepExpired removes all the expired elements at once (see SetMaxAge), wherever they are at the queue, returning the
+// number of removed elements. Unlike the dequeues (dropping the expired elements they find at the head), it reclaims
+// the expired elements behind unexpired ones too, without waiting for the sweeper. onExpire and the dead letter handler
+// get called for each one of them, outside the queue's locks. No enqueue/dequeue takes place while the queue is swept.
+func (st *FixedFIFO) SweepExpired() (removed int) {
+	return st.removeExpired()
+}
+
+// removeExpired removes the expired elements, keeping the order of the rest, and returns the number of removed ones.
+// No enqueue/dequeue takes place in the meantime.
+func (st *FixedFIFO) removeExpired() int {
+	st.lockSweep()
+	st.enqueueRWMutex.Lock()
+
+	if st.IsClosed() {
+		// the kept elements could not be enqueued back
+		st.enqueueRWMutex.Unlock()
+		st.sweepRWMutex.Unlock()
+		return 0
+	}
+
+	var (
+		kept    = make([]interface{}, 0, len(st.queue))
+		expired = make([]interface{}, 0)
+	)
+	for drained := false; !drained; {
+		select {
+		case value, ok := <-st.queue:
+			if !ok {
+				drained = true
+				break
+			}
+			if unwrapped, isExpired := st.unwrapAged(value); isExpired {
+				expired = append(expired, unwrapped)
+			} else {
+				kept = append(kept, value)
+			}
+		default:
+			drained = true
+		}
+	}
+	// there is room for all of them: no other element could be enqueued in the meantime
+	for _, value := range kept {
+		st.queue <- value
+	}
+	st.countRemoved(len(expired))
+
+	st.enqueueRWMutex.Unlock()
+	st.sweepRWMutex.Unlock()
+
+	st.expire(expired)
+	st.checkWatermarks()
+
+	return len(expired)
+}
+
+// lockSweep exclusively locks st.sweepRWMutex, waking up the consumers waiting for the next element while holding it
+// (read) so they release it
+func (st *FixedFIFO) lockSweep() {
+	st.sweepWakeMutex.Lock()
+	defer st.sweepWakeMutex.Unlock()
+
+	close(st.sweepWake)
+	st.sweepRWMutex.Lock()
+	// no consumer could be waiting on it anymore
+	st.sweepWake = make(chan struct{})
+}
+
+// wrapAged returns the element to be stored at the channel: value plus its enqueue time if max age is enabled
+func (st *FixedFIFO) wrapAged(value interface{}) interface{} {
+	if atomic.LoadInt64(&st.maxAge) <= 0 {
+		return value
+	}
+
+	return agedElement{value: value, enqueuedAt: st.getClock().Now()}
+}
+
+// unwrapAged returns the element's value as it was enqueued, plus whether it has expired
+func (st *FixedFIFO) unwrapAged(stored interface{}) (interface{}, bool) {
+	aged, ok := stored.(agedElement)
+	if !ok {
+		return stored, false
+	}
+
+	maxAge := time.Duration(atomic.LoadInt64(&st.maxAge))
+	return aged.value, maxAge > 0 && st.getClock().Now().Sub(aged.enqueuedAt) >= maxAge
+}
+
+// expire calls onExpire (see SetMaxAge) and the dead letter handler (see SetDeadLetterHandler) for the given elements
+func (st *FixedFIFO) expire(values []interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	st.maxAgeMutex.Lock()
+	onExpire := st.onExpire
+	st.maxAgeMutex.Unlock()
+
+	if onExpire != nil {
+		for _, value := range values {
+			onExpire(value)
+		}
+	}
+	st.discard(DeadLetterReasonExpired, values...)
+}
+
+// tryDequeue dequeues the first not expired element without blocking, the expired ones get dropped (see SetMaxAge)
+func (st *FixedFIFO) tryDequeue() (interface{}, error) {
+	st.sweepRWMutex.RLock()
+	value, expired, err := st.tryDequeueUnexpired()
+	st.sweepRWMutex.RUnlock()
+
+	st.expire(expired)
+	st.checkWatermarks()
+
+	return value, err
+}
+
+// tryDequeueUnexpired is tryDequeue but it returns the dropped expired elements instead of calling onExpire. It must be
+// called holding st.sweepRWMutex (read).
+func (st *FixedFIFO) tryDequeueUnexpired() (interface{}, []interface{}, error) {
+	var expired []interface{}
+	for {
+		select {
+		case stored, ok := <-st.queue:
+			if !ok {
+				return nil, expired, errors.New("internal channel is closed")
+			}
+
+			value, isExpired := st.unwrapAged(stored)
+			if isExpired {
+				st.countRemoved(1)
+				expired = append(expired, value)
+				continue
+			}
+			st.countDequeued(1)
+			return value, expired, nil
+		default:
+			return nil, expired, errFixedFIFOEmpty
+		}
+	}
+}
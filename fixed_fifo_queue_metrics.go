@@ -0,0 +1,102 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMetricPrefix = "goconcurrentqueue"
+)
+
+// SetMetricPrefix sets the prefix for the metric names written by WriteMetrics. Default: "goconcurrentqueue".
+func (st *FixedFIFO) SetMetricPrefix(prefix string) {
+	st.metricsRWMutex.Lock()
+	defer st.metricsRWMutex.Unlock()
+
+	st.metricPrefix = prefix
+}
+
+// WriteMetrics writes the queue's metrics (length, capacity, enqueued/dequeued totals and waiters) using the Prometheus
+// text exposition format.
+func (st *FixedFIFO) WriteMetrics(w io.Writer) error {
+	st.metricsRWMutex.RLock()
+	prefix := st.metricPrefix
+	st.metricsRWMutex.RUnlock()
+
+	metrics := []struct {
+		name       string
+		metricType string
+		help       string
+		value      interface{}
+	}{
+		{"length", "gauge", "Number of enqueued elements.", st.GetLen()},
+		{"capacity", "gauge", "Queue's capacity.", cap(st.queue)},
+		{"enqueued_total", "counter", "Total number of enqueued elements.", atomic.LoadUint64(&st.enqueuedTotal)},
+		{"dequeued_total", "counter", "Total number of dequeued elements.", atomic.LoadUint64(&st.dequeuedTotal)},
+		{"enqueue_waiters", "gauge", "Number of producers waiting for an available slot.", atomic.LoadInt64(&st.enqueueWaiters)},
+		{"dequeue_waiters", "gauge", "Number of consumers waiting to dequeue.", atomic.LoadInt64(&st.dequeueWaiters)},
+	}
+
+	for _, metric := range metrics {
+		name := metric.name
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n%v %v\n", name, metric.help, name, metric.metricType, name, metric.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Throughput returns the enqueued and dequeued elements per second over the given window (up to 1 minute)
+func (st *FixedFIFO) Throughput(window time.Duration) (enqueueRate, dequeueRate float64) {
+	now := st.getClock().Now()
+	return st.enqueueRate.rate(now, window), st.dequeueRate.rate(now, window)
+}
+
+// StartLengthSampling samples the queue's length every interval (using the real time), keeping the latest 1024 samples
+// (see LengthPercentiles). Calling it again discards the previous samples and restarts the sampling. An interval <= 0
+// stops the sampling.
+func (st *FixedFIFO) StartLengthSampling(interval time.Duration) {
+	st.lengthSampler.start(interval, st.GetLen)
+}
+
+// StopLengthSampling stops the sampling started by StartLengthSampling, keeping the samples
+func (st *FixedFIFO) StopLengthSampling() {
+	st.lengthSampler.stop()
+}
+
+// LengthPercentiles returns the 50th, 95th and 99th percentiles of the sampled lengths (see StartLengthSampling), 0 if
+// there are no samples
+func (st *FixedFIFO) LengthPercentiles() (p50, p95, p99 int) {
+	return st.lengthSampler.percentiles()
+}
+
+// countEnqueued keeps track of n enqueued elements (see GetLen and Throughput)
+func (st *FixedFIFO) countEnqueued(n int) {
+	if length := atomic.AddInt64(&st.length, int64(n)); length > 0 && length <= int64(n) {
+		// empty -> non-empty
+		st.signalNonEmpty()
+	}
+	atomic.AddUint64(&st.enqueuedTotal, uint64(n))
+	st.enqueueRate.add(st.getClock().Now(), uint64(n))
+}
+
+// countDequeued keeps track of n dequeued elements (see GetLen and Throughput)
+func (st *FixedFIFO) countDequeued(n int) {
+	st.countRemoved(n)
+	atomic.AddUint64(&st.dequeuedTotal, uint64(n))
+	st.dequeueRate.add(st.getClock().Now(), uint64(n))
+}
+
+// countRemoved keeps track of n elements leaving the queue: dequeued, dropped or expired (see GetLen and BoundedGroup)
+func (st *FixedFIFO) countRemoved(n int) {
+	atomic.AddInt64(&st.length, -int64(n))
+	st.getGroup().release(n)
+}
@@ -0,0 +1,119 @@
+package goconcurrentqueue
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ***************************************************************************************
+// ** WriteMetrics / SetMetricPrefix
+// ***************************************************************************************
+
+// errorWriter fails on every write
+type errorWriter struct{}
+
+func (w errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+// metrics using the default prefix
+func (suite *FixedFIFOTestSuite) TestWriteMetricsSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	suite.fifo.Dequeue()
+
+	var buf bytes.Buffer
+	suite.NoError(suite.fifo.WriteMetrics(&buf), "Unexpected error")
+
+	output := buf.String()
+	for _, line := range []string{
+		"# TYPE goconcurrentqueue_length gauge",
+		"goconcurrentqueue_length 1\n",
+		"goconcurrentqueue_capacity 500\n",
+		"# TYPE goconcurrentqueue_enqueued_total counter",
+		"goconcurrentqueue_enqueued_total 2\n",
+		"goconcurrentqueue_dequeued_total 1\n",
+		"goconcurrentqueue_enqueue_waiters 0\n",
+		"goconcurrentqueue_dequeue_waiters 0\n",
+	} {
+		suite.Contains(output, line, "Missing metric line")
+	}
+}
+
+// metrics using a custom prefix
+func (suite *FixedFIFOTestSuite) TestSetMetricPrefixSingleGR() {
+	suite.fifo.SetMetricPrefix("jobs")
+
+	var buf bytes.Buffer
+	suite.NoError(suite.fifo.WriteMetrics(&buf), "Unexpected error")
+	suite.Contains(buf.String(), "jobs_length 0\n", "Missing metric line")
+	suite.False(strings.Contains(buf.String(), defaultMetricPrefix), "The default prefix must not be used")
+}
+
+// writer errors are returned
+func (suite *FixedFIFOTestSuite) TestWriteMetricsErrorSingleGR() {
+	suite.Error(suite.fifo.WriteMetrics(errorWriter{}), "Writer errors must be returned")
+}
+
+// ***************************************************************************************
+// ** Throughput
+// ***************************************************************************************
+
+// enqueue/dequeue rates
+func (suite *FixedFIFOTestSuite) TestThroughputSingleGR() {
+	enqueueRate, dequeueRate := suite.fifo.Throughput(time.Second)
+	suite.Equal(float64(0), enqueueRate, "No enqueues expected")
+	suite.Equal(float64(0), dequeueRate, "No dequeues expected")
+
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.fifo.TryDequeueN(4)
+
+	enqueueRate, dequeueRate = suite.fifo.Throughput(time.Minute)
+	suite.Equal(float64(10)/60, enqueueRate, "Unexpected enqueue rate")
+	suite.Equal(float64(4)/60, dequeueRate, "Unexpected dequeue rate")
+}
+
+// ***************************************************************************************
+// ** StartLengthSampling / StopLengthSampling / LengthPercentiles
+// ***************************************************************************************
+
+// sampled lengths
+func (suite *FixedFIFOTestSuite) TestLengthSamplingSingleGR() {
+	p50, p95, p99 := suite.fifo.LengthPercentiles()
+	suite.Equal([]int{0, 0, 0}, []int{p50, p95, p99}, "No samples expected")
+
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.fifo.StartLengthSampling(time.Millisecond)
+	for {
+		if p50, _, _ = suite.fifo.LengthPercentiles(); p50 > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	suite.fifo.StopLengthSampling()
+
+	p50, p95, p99 = suite.fifo.LengthPercentiles()
+	suite.Equal([]int{3, 3, 3}, []int{p50, p95, p99}, "Wrong percentiles")
+}
+
+// sampling keeps a locked queue locked
+func (suite *FixedFIFOTestSuite) TestLengthSamplingLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.fifo.StartLengthSampling(time.Millisecond)
+	for {
+		if p50, _, _ := suite.fifo.LengthPercentiles(); p50 > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	suite.fifo.StopLengthSampling()
+
+	suite.True(suite.fifo.IsLocked(), "Sampling must not unlock the queue")
+}
@@ -0,0 +1,42 @@
+package goconcurrentqueue
+
+import (
+	"context"
+)
+
+// WaitNonEmpty waits until the queue has at least one element (see GetLen) or ctx is done (returning ctx's error),
+// without dequeuing it: there is no guarantee the element is still there once it returns. It gets woken up by the
+// enqueue turning the queue non-empty (no polling), e.g. to spin up the consumers before anything gets dequeued.
+func (st *FixedFIFO) WaitNonEmpty(ctx context.Context) error {
+	for {
+		// the channel must be taken before checking the length: the enqueue changing it would close it
+		st.nonEmptyMutex.Lock()
+		if st.nonEmpty == nil {
+			st.nonEmpty = make(chan struct{})
+		}
+		nonEmpty := st.nonEmpty
+		st.nonEmptyMutex.Unlock()
+
+		if st.GetLen() > 0 {
+			return nil
+		}
+
+		select {
+		case <-nonEmpty:
+			// the element could be already gone
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// signalNonEmpty wakes up the WaitNonEmpty callers once the queue becomes non-empty
+func (st *FixedFIFO) signalNonEmpty() {
+	st.nonEmptyMutex.Lock()
+	defer st.nonEmptyMutex.Unlock()
+
+	if st.nonEmpty != nil {
+		close(st.nonEmpty)
+		st.nonEmpty = nil
+	}
+}
@@ -0,0 +1,81 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// rejection reasons' indexes at FixedFIFO.rejections
+const (
+	fixedRejectionFull = iota
+	fixedRejectionClosed
+	fixedRejectionLocked
+	fixedRejectionReasonCount
+)
+
+// fixedRejectionReasons holds FixedFIFO's RejectionReason... constants by index
+var fixedRejectionReasons = [fixedRejectionReasonCount]string{
+	RejectionReasonFull,
+	RejectionReasonClosed,
+	RejectionReasonLocked,
+}
+
+// RejectionStats returns the number of rejected enqueues by reason (every reason listed below is included, even if
+// 0), e.g. to tell the capacity pressure from the enqueues attempted while the queue was locked:
+//   - RejectionReasonFull: the queue (or its group's budget, see BoundedGroup) was at full capacity
+//   - RejectionReasonClosed: the queue was closed, see Close
+//   - RejectionReasonLocked: the queue was locked (see Lock) or auto locked (see SetAutoLockOnFull)
+//
+// Only the rejected attempts get counted: EnqueueBatchOrWait and EnqueueOrWaitForSlotWithBackoff count nothing while
+// waiting for a slot (nor once their wait runs out), neither are the elements dropped by the overflow policy counted
+// (see SetOverflowPolicy). The counters are updated atomically, so the stats are not a snapshot taken at once.
+func (st *FixedFIFO) RejectionStats() map[string]uint64 {
+	ret := make(map[string]uint64, fixedRejectionReasonCount)
+	for i, reason := range fixedRejectionReasons {
+		ret[reason] = atomic.LoadUint64(&st.rejections[i])
+	}
+
+	return ret
+}
+
+// ResetRejectionStats resets the rejected enqueues counters, see RejectionStats
+func (st *FixedFIFO) ResetRejectionStats() {
+	for i := range st.rejections {
+		atomic.StoreUint64(&st.rejections[i], 0)
+	}
+}
+
+// countRejection keeps track of a rejected enqueue, see RejectionStats
+func (st *FixedFIFO) countRejection(reason int) {
+	atomic.AddUint64(&st.rejections[reason], 1)
+}
+
+// rejectLocked keeps track of an enqueue rejected because the queue is locked, returning its error
+func (st *FixedFIFO) rejectLocked() error {
+	st.countRejection(fixedRejectionLocked)
+	return errors.New("The queue is locked")
+}
+
+// rejectAutoLocked keeps track of an enqueue rejected because the queue is auto locked, returning its error
+func (st *FixedFIFO) rejectAutoLocked() error {
+	st.countRejection(fixedRejectionLocked)
+	return errors.New("The queue is auto locked (full for too long)")
+}
+
+// rejectClosed keeps track of an enqueue rejected because the queue is closed, returning its error
+func (st *FixedFIFO) rejectClosed() error {
+	st.countRejection(fixedRejectionClosed)
+	return errors.New("The queue is closed")
+}
+
+// rejectFull keeps track of an enqueue rejected because the queue is at full capacity, returning its error
+func (st *FixedFIFO) rejectFull() error {
+	st.countRejection(fixedRejectionFull)
+	return errors.New("FixedFIFO queue is at full capacity")
+}
+
+// rejectGroupFull keeps track of an enqueue rejected because the queue's group budget is exhausted, returning its error
+func (st *FixedFIFO) rejectGroupFull() error {
+	st.countRejection(fixedRejectionFull)
+	return errors.New("The group's budget is exhausted")
+}
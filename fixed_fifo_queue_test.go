@@ -1,8 +1,11 @@
 package goconcurrentqueue
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -277,3 +280,1570 @@ func (suite *FixedFIFOTestSuite) TestUnlockSingleGR() {
 	suite.fifo.Unlock()
 	suite.True(suite.fifo.IsLocked() == false, "fifo.isLocked has to be false after fifo.Unlock()")
 }
+
+// ***************************************************************************************
+// ** GetLenAndCap
+// ***************************************************************************************
+
+// single GR GetLenAndCap
+func (suite *FixedFIFOTestSuite) TestGetLenAndCapSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+
+	len, cap := suite.fifo.GetLenAndCap()
+	suite.Equal(2, len, "unexpected length")
+	suite.Equal(fixedFIFOQueueCapacity, cap, "unexpected capacity")
+}
+
+// GetLenAndCap keeps a locked queue locked
+func (suite *FixedFIFOTestSuite) TestGetCapLockSingleGR() {
+	suite.fifo.Lock()
+
+	suite.Equal(fixedFIFOQueueCapacity, suite.fifo.GetCap(), "unexpected capacity")
+	suite.True(suite.fifo.IsLocked(), "GetCap must not unlock the queue")
+}
+
+func (suite *FixedFIFOTestSuite) TestGetLenAndCapLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	len, _ := suite.fifo.GetLenAndCap()
+	suite.Equal(1, len, "unexpected length")
+	suite.True(suite.fifo.IsLocked(), "GetLenAndCap must not unlock the queue")
+}
+
+// ***************************************************************************************
+// ** EnqueueOrWaitForSlotWithBackoff
+// ***************************************************************************************
+
+// enqueue into a queue having available slots
+func (suite *FixedFIFOTestSuite) TestEnqueueOrWaitForSlotWithBackoffSingleGR() {
+	suite.NoError(suite.fifo.EnqueueOrWaitForSlotWithBackoff(1, time.Millisecond), "no error expected when queue is not full")
+	suite.Equal(1, suite.fifo.GetLen(), "unexpected length")
+}
+
+// timeout waiting for a slot
+func (suite *FixedFIFOTestSuite) TestEnqueueOrWaitForSlotWithBackoffTimeoutSingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.Enqueue(1)
+
+	start := time.Now()
+	suite.Error(suite.fifo.EnqueueOrWaitForSlotWithBackoff(2, 20*time.Millisecond), "error expected when no slot gets available")
+	suite.True(time.Since(start) >= 20*time.Millisecond, "maxWait has to be respected")
+}
+
+// a locked queue does not allow to enqueue
+func (suite *FixedFIFOTestSuite) TestEnqueueOrWaitForSlotWithBackoffLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.EnqueueOrWaitForSlotWithBackoff(1, time.Millisecond), "Locked queue does not allow to enqueue elements")
+}
+
+// a slot gets available while waiting
+func (suite *FixedFIFOTestSuite) TestEnqueueOrWaitForSlotWithBackoffMultipleGRs() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.Enqueue(1)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Dequeue()
+	}()
+
+	suite.NoError(suite.fifo.EnqueueOrWaitForSlotWithBackoff(2, time.Second), "no error expected once a slot gets available")
+	val, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, val, "Wrong element's value")
+}
+
+// ***************************************************************************************
+// ** PauseDequeue / ResumeDequeue
+// ***************************************************************************************
+
+// not paused at initialization
+func (suite *FixedFIFOTestSuite) TestDequeueNotPausedAtInitialization() {
+	suite.False(suite.fifo.IsDequeuePaused(), "Dequeue must not be paused at initialization")
+}
+
+// enqueue is allowed while dequeue is paused
+func (suite *FixedFIFOTestSuite) TestPauseDequeueEnqueueSingleGR() {
+	suite.fifo.PauseDequeue()
+	suite.True(suite.fifo.IsDequeuePaused(), "Dequeue must be paused after PauseDequeue()")
+	suite.NoError(suite.fifo.Enqueue(1), "Enqueue must be allowed while dequeue is paused")
+	suite.Equal(1, suite.fifo.GetLen(), "unexpected length")
+}
+
+// paused dequeue returns an error (DequeuePauseModeError)
+func (suite *FixedFIFOTestSuite) TestPauseDequeueErrorModeSingleGR() {
+	suite.fifo.SetDequeuePauseMode(DequeuePauseModeError)
+	suite.fifo.Enqueue(1)
+	suite.fifo.PauseDequeue()
+
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "error expected while dequeue is paused")
+
+	suite.fifo.ResumeDequeue()
+	suite.False(suite.fifo.IsDequeuePaused(), "Dequeue must not be paused after ResumeDequeue()")
+	val, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error after ResumeDequeue()")
+	suite.Equal(1, val, "Wrong element's value")
+}
+
+// paused dequeue blocks until resumed (DequeuePauseModeBlock)
+func (suite *FixedFIFOTestSuite) TestPauseDequeueBlockModeMultipleGRs() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.PauseDequeue()
+	// multiple pauses should not matter
+	suite.fifo.PauseDequeue()
+
+	done := make(chan interface{})
+	go func() {
+		val, _ := suite.fifo.Dequeue()
+		done <- val
+	}()
+
+	select {
+	case <-done:
+		suite.Fail("Dequeue must block while dequeue is paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	suite.fifo.ResumeDequeue()
+	select {
+	case val := <-done:
+		suite.Equal(1, val, "Wrong element's value")
+	case <-time.After(time.Second):
+		suite.Fail("Dequeue must be released after ResumeDequeue()")
+	}
+}
+
+// ***************************************************************************************
+// ** EnqueueBatchOrWait
+// ***************************************************************************************
+
+// enqueue a batch having enough available slots
+func (suite *FixedFIFOTestSuite) TestEnqueueBatchOrWaitSingleGR() {
+	suite.NoError(suite.fifo.EnqueueBatchOrWait(context.Background(), []interface{}{1, 2, 3}), "Unexpected error")
+	suite.Equal(3, suite.fifo.GetLen(), "unexpected length")
+
+	for i := 1; i <= 3; i++ {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(i, val, "Wrong element's value")
+	}
+}
+
+// a batch larger than the capacity can't be enqueued
+func (suite *FixedFIFOTestSuite) TestEnqueueBatchOrWaitExceedsCapacitySingleGR() {
+	suite.fifo = NewFixedFIFO(2)
+	suite.Error(suite.fifo.EnqueueBatchOrWait(context.Background(), []interface{}{1, 2, 3}), "error expected for a batch larger than the capacity")
+	suite.Equal(0, suite.fifo.GetLen(), "No element must be enqueued")
+}
+
+// a locked queue does not allow to enqueue
+func (suite *FixedFIFOTestSuite) TestEnqueueBatchOrWaitLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Error(suite.fifo.EnqueueBatchOrWait(context.Background(), []interface{}{1}), "Locked queue does not allow to enqueue elements")
+}
+
+// context gets done while waiting for slots
+func (suite *FixedFIFOTestSuite) TestEnqueueBatchOrWaitContextSingleGR() {
+	suite.fifo = NewFixedFIFO(2)
+	suite.fifo.Enqueue(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	suite.Error(suite.fifo.EnqueueBatchOrWait(ctx, []interface{}{1, 2}), "error expected once the context is done")
+	suite.Equal(1, suite.fifo.GetLen(), "No element of the batch must be enqueued")
+}
+
+// the batch is enqueued once there are enough available slots, without other elements in between
+func (suite *FixedFIFOTestSuite) TestEnqueueBatchOrWaitMultipleGRs() {
+	suite.fifo = NewFixedFIFO(3)
+	suite.fifo.Enqueue(0)
+	suite.fifo.Enqueue(0)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Dequeue()
+		suite.fifo.Dequeue()
+	}()
+
+	suite.NoError(suite.fifo.EnqueueBatchOrWait(context.Background(), []interface{}{1, 2, 3}), "Unexpected error")
+	for i := 1; i <= 3; i++ {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(i, val, "Wrong element's value")
+	}
+}
+
+// ***************************************************************************************
+// ** SetFairnessMode
+// ***************************************************************************************
+
+// no operation tracking by default
+func (suite *FixedFIFOTestSuite) TestFairnessNoneSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Dequeue()
+	suite.Equal(int32(0), suite.fifo.lastOperation, "Operations must not be tracked using FairnessNone")
+}
+
+// balanced mode keeps track of the last operation
+func (suite *FixedFIFOTestSuite) TestFairnessBalancedSingleGR() {
+	suite.fifo.SetFairnessMode(FairnessBalanced)
+
+	suite.NoError(suite.fifo.Enqueue(1), "Unexpected error")
+	suite.NoError(suite.fifo.Enqueue(2), "Unexpected error")
+	suite.Equal(fixedFIFOOperationEnqueue, suite.fifo.lastOperation, "Unexpected last operation")
+
+	val, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, val, "Wrong element's value")
+	suite.Equal(fixedFIFOOperationDequeue, suite.fifo.lastOperation, "Unexpected last operation")
+}
+
+// balanced mode under contention
+func (suite *FixedFIFOTestSuite) TestFairnessBalancedMultipleGRs() {
+	var (
+		wg    sync.WaitGroup
+		total = 200
+	)
+	suite.fifo.SetFairnessMode(FairnessBalanced)
+
+	for i := 0; i < total; i++ {
+		wg.Add(2)
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+		go func() {
+			defer wg.Done()
+			suite.fifo.Dequeue()
+		}()
+	}
+	wg.Wait()
+
+	enqueued, dequeued := atomic.LoadUint64(&suite.fifo.enqueuedTotal), atomic.LoadUint64(&suite.fifo.dequeuedTotal)
+	suite.Equal(int(enqueued-dequeued), suite.fifo.GetLen(), "No element could be lost")
+}
+
+// ***************************************************************************************
+// ** SetOverflowPolicy / DroppedCount
+// ***************************************************************************************
+
+// full queue rejects new elements by default
+func (suite *FixedFIFOTestSuite) TestOverflowRejectSingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.Enqueue(1)
+
+	suite.Error(suite.fifo.Enqueue(2), "error expected when queue is full")
+	suite.Equal(uint64(0), suite.fifo.DroppedCount(), "No dropped elements expected")
+}
+
+// drop the newest elements
+func (suite *FixedFIFOTestSuite) TestOverflowDropNewestSingleGR() {
+	suite.fifo = NewFixedFIFO(2)
+	suite.fifo.SetOverflowPolicy(OverflowDropNewest)
+	for i := 0; i < 5; i++ {
+		suite.NoError(suite.fifo.Enqueue(i), "no error expected dropping the newest elements")
+	}
+
+	suite.Equal(uint64(3), suite.fifo.DroppedCount(), "Unexpected dropped count")
+	for i := 0; i < 2; i++ {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(i, val, "The oldest elements must be kept")
+	}
+
+	suite.fifo.ResetDroppedCount()
+	suite.Equal(uint64(0), suite.fifo.DroppedCount(), "Dropped count must be 0 after ResetDroppedCount()")
+}
+
+// drop the oldest elements
+func (suite *FixedFIFOTestSuite) TestOverflowDropOldestSingleGR() {
+	suite.fifo = NewFixedFIFO(2)
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+	for i := 0; i < 5; i++ {
+		suite.NoError(suite.fifo.Enqueue(i), "no error expected dropping the oldest elements")
+	}
+
+	suite.Equal(uint64(3), suite.fifo.DroppedCount(), "Unexpected dropped count")
+	for i := 3; i < 5; i++ {
+		val, _ := suite.fifo.Dequeue()
+		suite.Equal(i, val, "The newest elements must be kept")
+	}
+}
+
+// a zero capacity queue can't make room
+func (suite *FixedFIFOTestSuite) TestOverflowDropOldestZeroCapacitySingleGR() {
+	suite.fifo = NewFixedFIFO(0)
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+	suite.Error(suite.fifo.Enqueue(1), "error expected when queue has no capacity")
+}
+
+// concurrent drops are counted
+func (suite *FixedFIFOTestSuite) TestOverflowDropOldestMultipleGRs() {
+	var (
+		wg    sync.WaitGroup
+		total = 100
+	)
+	suite.fifo = NewFixedFIFO(10)
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(value int) {
+			defer wg.Done()
+			suite.NoError(suite.fifo.Enqueue(value), "no error expected dropping the oldest elements")
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(10, suite.fifo.GetLen(), "The queue must be full")
+	suite.Equal(uint64(total-10), suite.fifo.DroppedCount(), "Unexpected dropped count")
+}
+
+// ***************************************************************************************
+// ** TryDequeueN
+// ***************************************************************************************
+
+// dequeue up to max elements
+func (suite *FixedFIFOTestSuite) TestTryDequeueNSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.Equal([]interface{}{0, 1, 2}, suite.fifo.TryDequeueN(3), "Unexpected dequeued elements")
+	suite.Equal([]interface{}{3, 4}, suite.fifo.TryDequeueN(3), "Unexpected dequeued elements")
+
+	elements := suite.fifo.TryDequeueN(3)
+	suite.NotNil(elements, "An empty non nil slice expected")
+	suite.Len(elements, 0, "No elements expected from an empty queue")
+}
+
+// locked, paused or closed queue
+func (suite *FixedFIFOTestSuite) TestTryDequeueNUnavailableSingleGR() {
+	suite.fifo.Enqueue(1)
+
+	suite.fifo.Lock()
+	suite.Len(suite.fifo.TryDequeueN(1), 0, "Locked queue does not allow to dequeue elements")
+	suite.fifo.Unlock()
+
+	suite.fifo.PauseDequeue()
+	suite.Len(suite.fifo.TryDequeueN(1), 0, "Paused queue does not allow to dequeue elements")
+	suite.fifo.ResumeDequeue()
+
+	suite.Len(suite.fifo.TryDequeueN(0), 0, "No elements expected for max == 0")
+
+	close(suite.fifo.queue)
+	suite.Equal([]interface{}{1}, suite.fifo.TryDequeueN(2), "Unexpected dequeued elements from a closed channel")
+}
+
+// ***************************************************************************************
+// ** SetClock
+// ***************************************************************************************
+
+// waits use the given clock
+func (suite *FixedFIFOTestSuite) TestSetClockSingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.SetClock(newFakeClock())
+	suite.fifo.Enqueue(1)
+
+	done := make(chan error)
+	go func() {
+		done <- suite.fifo.EnqueueOrWaitForSlotWithBackoff(2, time.Hour)
+	}()
+
+	select {
+	case err := <-done:
+		suite.Error(err, "error expected when no slot gets available")
+	case <-time.After(time.Second):
+		suite.Fail("maxWait must be measured using the clock")
+	}
+
+	suite.fifo.SetClock(nil)
+	suite.Equal(realClock{}, suite.fifo.getClock(), "The real time must be used")
+}
+
+// ***************************************************************************************
+// ** SetAutoLockOnFull
+// ***************************************************************************************
+
+// enqueues get rejected once the queue is full for too long, until a consumer drains it
+func (suite *FixedFIFOTestSuite) TestSetAutoLockOnFullSingleGR() {
+	clock := newFakeClock()
+	suite.fifo = NewFixedFIFO(2)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetAutoLockOnFull(time.Second)
+
+	suite.NoError(suite.fifo.Enqueue(1), "Unexpected error")
+	suite.NoError(suite.fifo.Enqueue(2), "Unexpected error")
+	suite.False(suite.fifo.IsAutoLocked(), "The queue must not be auto locked before the threshold")
+
+	clock.Advance(time.Second)
+	suite.True(suite.fifo.IsAutoLocked(), "The queue must be auto locked once full for longer than the threshold")
+	suite.Error(suite.fifo.Enqueue(3), "Enqueue must be rejected while the queue is auto locked")
+	suite.Error(suite.fifo.EnqueueOrWaitForSlotWithBackoff(3, time.Second), "Enqueue must be rejected while the queue is auto locked")
+	suite.False(suite.fifo.IsLocked(), "The auto lock does not lock the queue")
+
+	// consumers drain the queue
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Dequeue must be allowed while the queue is auto locked")
+	suite.Equal(1, value, "Wrong element's value")
+	suite.False(suite.fifo.IsAutoLocked(), "The auto lock must be lifted once the queue is below its capacity")
+
+	// the threshold is measured again from the moment the queue gets full
+	suite.NoError(suite.fifo.Enqueue(3), "Unexpected error")
+	clock.Advance(500 * time.Millisecond)
+	suite.False(suite.fifo.IsAutoLocked(), "The queue must not be auto locked before the threshold")
+}
+
+// the auto lock is independent from Lock/Unlock
+func (suite *FixedFIFOTestSuite) TestSetAutoLockOnFullManualLockSingleGR() {
+	clock := newFakeClock()
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetAutoLockOnFull(time.Second)
+	suite.fifo.Enqueue(1)
+	clock.Advance(time.Second)
+
+	suite.fifo.Lock()
+	suite.fifo.Unlock()
+	suite.True(suite.fifo.IsAutoLocked(), "Unlock must not lift the auto lock")
+
+	suite.fifo.Lock()
+	suite.fifo.SetAutoLockOnFull(0)
+	suite.False(suite.fifo.IsAutoLocked(), "No auto lock expected once disabled")
+	suite.True(suite.fifo.IsLocked(), "Disabling the auto lock must not unlock the queue")
+}
+
+// ***************************************************************************************
+// ** ToFIFO
+// ***************************************************************************************
+
+// the elements are moved to a FIFO keeping their order
+func (suite *FixedFIFOTestSuite) TestToFIFOSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.fifo.Lock()
+
+	fifo := suite.fifo.ToFIFO()
+	suite.Equal(5, fifo.GetLen(), "Wrong FIFO's length")
+	suite.Equal(0, len(suite.fifo.queue), "Empty queue expected")
+	for i := 0; i < 5; i++ {
+		value, err := fifo.Dequeue()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(i, value, "Wrong element's value")
+	}
+
+	// the original queue remains usable
+	suite.fifo.Unlock()
+	suite.NoError(suite.fifo.Enqueue(10), "Unexpected error")
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(10, value, "Wrong element's value")
+
+	suite.Equal(0, suite.fifo.ToFIFO().GetLen(), "Empty FIFO expected for an empty queue")
+}
+
+// concurrent enqueues while converting, no element gets lost
+func (suite *FixedFIFOTestSuite) TestToFIFOMultipleGRs() {
+	var (
+		totalGRs = 50
+		wg       sync.WaitGroup
+	)
+	suite.fifo = NewFixedFIFO(totalGRs)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+
+	fifo := suite.fifo.ToFIFO()
+	wg.Wait()
+
+	suite.Equal(totalGRs, fifo.GetLen()+suite.fifo.GetLen(), "No element must be lost")
+}
+
+// ***************************************************************************************
+// ** SetMaxAge
+// ***************************************************************************************
+
+// expired elements are never dequeued
+func (suite *FixedFIFOTestSuite) TestSetMaxAgeDequeueSingleGR() {
+	var (
+		clock   = newFakeClock()
+		expired []interface{}
+	)
+	suite.fifo.SetClock(clock)
+	suite.fifo.Enqueue(0)
+	suite.fifo.SetMaxAge(time.Hour, func(value interface{}) { expired = append(expired, value) })
+	defer suite.fifo.SetMaxAge(0, nil)
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	clock.Advance(time.Hour)
+	suite.fifo.Enqueue(3)
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(0, value, "Elements enqueued while max age was disabled never expire")
+	value, err = suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(3, value, "Expired elements must be skipped")
+	suite.Equal([]interface{}{1, 2}, expired, "onExpire must be called for the expired elements")
+
+	suite.fifo.Enqueue(4)
+	clock.Advance(time.Hour)
+	suite.Len(suite.fifo.TryDequeueN(5), 0, "Expired elements must be skipped")
+	suite.Equal([]interface{}{1, 2, 4}, expired, "onExpire must be called for the expired elements")
+}
+
+// the sweeper removes the expired elements keeping the order of the rest
+func (suite *FixedFIFOTestSuite) TestSetMaxAgeSweeperSingleGR() {
+	var (
+		clock   = newFakeClock()
+		expired = make(chan interface{}, 10)
+	)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetMaxAgeSweepInterval(time.Millisecond)
+	suite.fifo.SetMaxAge(time.Hour, func(value interface{}) { expired <- value })
+	defer suite.fifo.SetMaxAge(0, nil)
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	clock.Advance(time.Hour)
+	suite.fifo.Enqueue(3)
+	suite.fifo.Enqueue(4)
+
+	for _, expected := range []interface{}{1, 2} {
+		select {
+		case value := <-expired:
+			suite.Equal(expected, value, "Wrong expired element")
+		case <-time.After(time.Second):
+			suite.FailNow("The sweeper must remove the expired elements")
+		}
+	}
+	suite.Equal(2, suite.fifo.GetLen(), "The expired elements must be removed")
+
+	fifo := suite.fifo.ToFIFO()
+	suite.Equal([]interface{}{3, 4}, dequeueAll(fifo), "The order must be preserved")
+}
+
+// dequeues while the sweeper runs
+func (suite *FixedFIFOTestSuite) TestSetMaxAgeMultipleGRs() {
+	var (
+		totalElements = 200
+		expired       int32
+		dequeued      int32
+		wg            sync.WaitGroup
+	)
+	suite.fifo = NewFixedFIFO(totalElements)
+	suite.fifo.SetMaxAgeSweepInterval(time.Millisecond)
+	suite.fifo.SetMaxAge(time.Millisecond, func(interface{}) { atomic.AddInt32(&expired, 1) })
+	defer suite.fifo.SetMaxAge(0, nil)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < totalElements; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		previous := -1
+		for i := 0; i < totalElements; i++ {
+			if value, err := suite.fifo.Dequeue(); err == nil {
+				suite.True(value.(int) > previous, "The order must be preserved")
+				previous = value.(int)
+				atomic.AddInt32(&dequeued, 1)
+			}
+		}
+	}()
+	wg.Wait()
+
+	time.Sleep(20 * time.Millisecond)
+	suite.Equal(int32(totalElements), atomic.LoadInt32(&expired)+atomic.LoadInt32(&dequeued), "Every element must be either dequeued or expired")
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// an already enqueued element gets dequeued right away
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementSingleGR() {
+	suite.fifo.Enqueue(1)
+
+	value, err := suite.fifo.DequeueOrWaitForNextElement()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, value, "Wrong element's value")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+	suite.Error(err, "error expected once the context is done")
+
+	suite.fifo.Lock()
+	_, err = suite.fifo.DequeueOrWaitForNextElement()
+	suite.Error(err, "The queue is locked")
+}
+
+// waits for the next element
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementMultipleGRs() {
+	var (
+		totalGRs = 5
+		wg       sync.WaitGroup
+		values   = make(chan interface{}, totalGRs)
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := suite.fifo.DequeueOrWaitForNextElement()
+			suite.NoError(err, "Unexpected error")
+			values <- value
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < totalGRs; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	wg.Wait()
+
+	suite.Len(values, totalGRs, "Every waiting consumer must get an element")
+}
+
+// ***************************************************************************************
+// ** Close / IsClosed
+// ***************************************************************************************
+
+// no more elements could be enqueued, the enqueued ones could be dequeued
+func (suite *FixedFIFOTestSuite) TestCloseSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.False(suite.fifo.IsClosed(), "The queue must not be closed")
+
+	suite.NoError(suite.fifo.Close(), "Unexpected error")
+	suite.True(suite.fifo.IsClosed(), "The queue must be closed")
+	suite.Error(suite.fifo.Close(), "error expected closing a closed queue")
+
+	suite.Error(suite.fifo.Enqueue(2), "error expected enqueuing into a closed queue")
+	suite.Error(suite.fifo.EnqueueOrWaitForSlotWithBackoff(2, time.Second), "error expected enqueuing into a closed queue")
+	suite.Error(suite.fifo.EnqueueBatchOrWait(context.Background(), []interface{}{2}), "error expected enqueuing into a closed queue")
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "The enqueued elements must be dequeued")
+	suite.Equal(1, value, "Wrong element's value")
+	_, err = suite.fifo.Dequeue()
+	suite.Error(err, "error expected once all the elements were dequeued")
+}
+
+// the overflow policies on a closed queue
+func (suite *FixedFIFOTestSuite) TestCloseOverflowPolicySingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+	suite.fifo.Enqueue(1)
+	suite.fifo.Close()
+
+	suite.Error(suite.fifo.Enqueue(2), "error expected enqueuing into a closed queue")
+}
+
+// concurrent enqueues while closing: no panic
+func (suite *FixedFIFOTestSuite) TestCloseMultipleGRs() {
+	var (
+		totalGRs = 50
+		wg       sync.WaitGroup
+	)
+	suite.fifo = NewFixedFIFO(totalGRs)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+	suite.fifo.Close()
+	wg.Wait()
+
+	suite.True(suite.fifo.IsClosed(), "The queue must be closed")
+}
+
+// ***************************************************************************************
+// ** Channel
+// ***************************************************************************************
+
+// range over the channel until the queue gets closed
+func (suite *FixedFIFOTestSuite) TestChannelCloseSingleGR() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.fifo.Close()
+
+	values := make([]interface{}, 0)
+	for value := range suite.fifo.Channel(context.Background()) {
+		values = append(values, value)
+	}
+	suite.Equal([]interface{}{0, 1, 2}, values, "Wrong received elements")
+}
+
+// the channel gets closed once ctx is done, the element waiting to be received is enqueued back
+func (suite *FixedFIFOTestSuite) TestChannelContextSingleGR() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := suite.fifo.Channel(ctx)
+
+	suite.fifo.Enqueue(1)
+	suite.Equal(1, <-ch, "Wrong received element")
+
+	suite.fifo.Enqueue(2)
+	// let the goroutine dequeue the element
+	for suite.fifo.GetLen() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			// the element was received before the goroutine noticed ctx
+			_, ok = <-ch
+		} else {
+			suite.Equal(1, suite.fifo.GetLen(), "The element waiting to be received must be enqueued back")
+		}
+		suite.False(ok, "The channel must be closed")
+	case <-time.After(2 * time.Second):
+		suite.Fail("The channel must be closed once ctx is done")
+	}
+}
+
+// elements enqueued by multiple goroutines are received once
+func (suite *FixedFIFOTestSuite) TestChannelMultipleGRs() {
+	var (
+		totalGRs = 10
+		wg       sync.WaitGroup
+	)
+	ch := suite.fifo.Channel(context.Background())
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.EnqueueOrWaitForSlotWithBackoff(value, time.Second)
+		}(i)
+	}
+
+	received := make(map[interface{}]bool)
+	for i := 0; i < totalGRs; i++ {
+		received[<-ch] = true
+	}
+	wg.Wait()
+	suite.fifo.Close()
+
+	_, ok := <-ch
+	suite.False(ok, "The channel must be closed once the queue gets closed")
+	suite.Equal(totalGRs, len(received), "Every element must be received once")
+}
+
+// ***************************************************************************************
+// ** SetMaxWaiters
+// ***************************************************************************************
+
+// the waiters beyond the limit get an error
+func (suite *FixedFIFOTestSuite) TestMaxWaitersSingleGR() {
+	suite.fifo.SetMaxWaiters(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result := make(chan error)
+	go func() {
+		_, err := suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+		result <- err
+	}()
+	for atomic.LoadInt64(&suite.fifo.dequeueWaiters) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+	suite.Error(err, "error expected beyond the max number of waiters")
+	queueError, ok := err.(*QueueError)
+	suite.True(ok, "A *QueueError is expected")
+	if ok {
+		suite.Equal(QueueErrorCodeTooManyWaiters, queueError.Code(), "Wrong error's code")
+	}
+
+	// an available element does not need to wait
+	suite.fifo.Enqueue(1)
+	suite.NoError(<-result, "The waiter must get the element")
+	suite.fifo.Enqueue(2)
+	value, err := suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, value, "Wrong element's value")
+}
+
+// unlimited waiters by default
+func (suite *FixedFIFOTestSuite) TestMaxWaitersUnlimitedMultipleGRs() {
+	var (
+		totalGRs = 20
+		wg       sync.WaitGroup
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			_, err := suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+			suite.Equal(context.Canceled, err, "Waiters must only fail once ctx is done")
+		}()
+	}
+	for atomic.LoadInt64(&suite.fifo.dequeueWaiters) < int64(totalGRs) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	suite.Equal(int64(0), atomic.LoadInt64(&suite.fifo.dequeueWaiters), "No waiter must be left")
+}
+
+// ***************************************************************************************
+// ** EnqueueIfDownstreamReady
+// ***************************************************************************************
+
+// the element gets enqueued only if downstream has available slots
+func (suite *FixedFIFOTestSuite) TestEnqueueIfDownstreamReadySingleGR() {
+	downstream := NewFixedFIFO(1)
+
+	enqueued, err := suite.fifo.EnqueueIfDownstreamReady(1, downstream)
+	suite.NoError(err, "Unexpected error")
+	suite.True(enqueued, "The element must be enqueued while downstream has available slots")
+
+	downstream.Enqueue(100)
+	enqueued, err = suite.fifo.EnqueueIfDownstreamReady(2, downstream)
+	suite.NoError(err, "Unexpected error")
+	suite.False(enqueued, "The element must not be enqueued while downstream is full")
+	suite.Equal(1, suite.fifo.GetLen(), "Wrong length")
+
+	_, err = suite.fifo.EnqueueIfDownstreamReady(3, suite.fifo)
+	suite.Error(err, "error expected using the same queue as downstream")
+}
+
+// errors enqueuing into the queue
+func (suite *FixedFIFOTestSuite) TestEnqueueIfDownstreamReadyErrorsSingleGR() {
+	downstream := NewFixedFIFO(1)
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.Enqueue(1)
+
+	enqueued, err := suite.fifo.EnqueueIfDownstreamReady(2, downstream)
+	suite.Error(err, "error expected enqueuing into a full queue")
+	suite.False(enqueued, "The element must not be enqueued into a full queue")
+
+	suite.fifo.Lock()
+	_, err = suite.fifo.EnqueueIfDownstreamReady(2, downstream)
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+}
+
+// two queues being each other's downstream must not deadlock
+func (suite *FixedFIFOTestSuite) TestEnqueueIfDownstreamReadyMultipleGRs() {
+	var (
+		wg    sync.WaitGroup
+		other = NewFixedFIFO(100)
+	)
+	suite.fifo = NewFixedFIFO(100)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.EnqueueIfDownstreamReady(value, other)
+		}(i)
+		go func(value int) {
+			defer wg.Done()
+			other.EnqueueIfDownstreamReady(value, suite.fifo)
+		}(i)
+	}
+	wg.Wait()
+
+	suite.True(suite.fifo.GetLen()+other.GetLen() <= 200, "Wrong total length")
+}
+
+// ***************************************************************************************
+// ** DequeueOrDefault
+// ***************************************************************************************
+
+// the default is returned if no element could be dequeued
+func (suite *FixedFIFOTestSuite) TestDequeueOrDefaultSingleGR() {
+	suite.Equal(-1, suite.fifo.DequeueOrDefault(-1), "The default is expected for an empty queue")
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Equal(-1, suite.fifo.DequeueOrDefault(-1), "The default is expected for a locked queue")
+	suite.fifo.Unlock()
+
+	suite.fifo.PauseDequeue()
+	suite.Equal(-1, suite.fifo.DequeueOrDefault(-1), "The default is expected for a paused queue (no blocking)")
+	suite.fifo.ResumeDequeue()
+
+	suite.Equal(1, suite.fifo.DequeueOrDefault(-1), "The enqueued element is expected")
+	suite.Equal(0, suite.fifo.GetLen(), "The element must be dequeued")
+}
+
+// polling an empty queue does not allocate
+func (suite *FixedFIFOTestSuite) TestDequeueOrDefaultAllocsSingleGR() {
+	var def interface{} = -1
+	allocs := testing.AllocsPerRun(100, func() {
+		suite.fifo.DequeueOrDefault(def)
+	})
+	suite.Equal(float64(0), allocs, "No allocations expected for an empty queue")
+}
+
+// ***************************************************************************************
+// ** ReserveSlot
+// ***************************************************************************************
+
+// reserved slots are not available to other enqueues
+func (suite *FixedFIFOTestSuite) TestReserveSlotSingleGR() {
+	suite.fifo = NewFixedFIFO(2)
+	suite.fifo.Enqueue(1)
+
+	commit, cancel, err := suite.fifo.ReserveSlot()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(1, suite.fifo.GetLen(), "Reserved slots must not be counted by GetLen")
+	suite.Error(suite.fifo.Enqueue(3), "The reserved slot must not be available")
+	ctx, cancelWait := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelWait()
+	suite.Error(suite.fifo.EnqueueBatchOrWait(ctx, []interface{}{3}), "The reserved slot must not be available")
+	_, _, err = suite.fifo.ReserveSlot()
+	suite.Error(err, "error expected reserving a slot at a full queue")
+
+	commit(2)
+	cancel()
+	commit(4)
+	suite.Equal(2, suite.fifo.GetLen(), "Only the first commit must take effect")
+	for _, expected := range []int{1, 2} {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(expected, value, "Wrong element's value")
+	}
+}
+
+// cancel releases the slot
+func (suite *FixedFIFOTestSuite) TestReserveSlotCancelSingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+
+	commit, cancel, _ := suite.fifo.ReserveSlot()
+	suite.Error(suite.fifo.Enqueue(1), "The reserved slot must not be available")
+	cancel()
+	commit(2)
+
+	suite.NoError(suite.fifo.Enqueue(1), "The slot must be released")
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(1, value, "A canceled reservation must not be committed")
+}
+
+// commit never fails, OverflowDropOldest does not loop forever
+func (suite *FixedFIFOTestSuite) TestReserveSlotCommitSingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+
+	commit, _, _ := suite.fifo.ReserveSlot()
+	suite.Error(suite.fifo.Enqueue(1), "Nothing could be dropped to make room")
+
+	suite.fifo.Lock()
+	commit(2)
+	suite.Equal(1, suite.fifo.GetLen(), "Commit must enqueue into a locked queue")
+	suite.fifo.Unlock()
+
+	_, _, err := suite.fifo.ReserveSlot()
+	suite.Error(err, "error expected reserving a slot at a full queue")
+}
+
+// concurrent reservations and enqueues never exceed the capacity
+func (suite *FixedFIFOTestSuite) TestReserveSlotMultipleGRs() {
+	var (
+		totalGRs = 50
+		wg       sync.WaitGroup
+		commits  int32
+	)
+	suite.fifo = NewFixedFIFO(10)
+
+	wg.Add(2 * totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			if commit, _, err := suite.fifo.ReserveSlot(); err == nil {
+				commit(value)
+				atomic.AddInt32(&commits, 1)
+			}
+		}(i)
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(10, suite.fifo.GetLen(), "The queue must be full")
+	suite.True(atomic.LoadInt32(&commits) <= 10, "No more slots than the capacity could be reserved")
+}
+
+// the auto lock also applies to the reservations
+func (suite *FixedFIFOTestSuite) TestReserveSlotAutoLockSingleGR() {
+	clock := newFakeClock()
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetAutoLockOnFull(time.Second)
+	suite.fifo.Enqueue(1)
+	suite.fifo.IsAutoLocked()
+	clock.Advance(time.Minute)
+
+	_, _, err := suite.fifo.ReserveSlot()
+	suite.Error(err, "error expected reserving a slot at an auto locked queue")
+}
+
+// ctx gets watched while dequeuing is paused
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementContextPausedSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.PauseDequeue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err, "ctx must be done while waiting for the resume")
+	suite.Equal(int64(0), atomic.LoadInt64(&suite.fifo.dequeueWaiters), "No waiter must be left")
+	suite.Equal(1, suite.fifo.GetLen(), "The element must be kept at the queue")
+}
+
+// a consumer waiting for the next element does not block the sweeper
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementSweeperSingleGR() {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		value, err := suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(2, value, "Wrong element's value")
+	}()
+	for atomic.LoadInt64(&suite.fifo.dequeueWaiters) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	moved := make(chan *FIFO)
+	go func() { moved <- suite.fifo.ToFIFO() }()
+	select {
+	case fifo := <-moved:
+		suite.Equal(0, fifo.GetLen(), "No element was enqueued")
+	case <-time.After(time.Second):
+		suite.FailNow("A waiting consumer must not block the sweeper")
+	}
+
+	suite.fifo.Enqueue(2)
+	<-done
+}
+
+// waiting dequeues while the sweeper runs
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementSweeperMultipleGRs() {
+	var (
+		totalElements = 200
+		expired       int32
+		dequeued      int32
+		wg            sync.WaitGroup
+	)
+	suite.fifo = NewFixedFIFO(totalElements)
+	suite.fifo.SetMaxAgeSweepInterval(time.Millisecond)
+	suite.fifo.SetMaxAge(time.Millisecond, func(interface{}) { atomic.AddInt32(&expired, 1) })
+	defer suite.fifo.SetMaxAge(0, nil)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < totalElements; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		previous := -1
+		for i := 0; i < totalElements; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+			value, err := suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+			cancel()
+			if err == nil {
+				suite.True(value.(int) > previous, "The order must be preserved")
+				previous = value.(int)
+				atomic.AddInt32(&dequeued, 1)
+			}
+		}
+	}()
+	wg.Wait()
+
+	time.Sleep(20 * time.Millisecond)
+	suite.Equal(int32(totalElements), atomic.LoadInt32(&expired)+atomic.LoadInt32(&dequeued), "Every element must be either dequeued or expired")
+}
+
+// ***************************************************************************************
+// ** SetDeadLetterHandler
+// ***************************************************************************************
+
+// elements dropped by the overflow policy
+func (suite *FixedFIFOTestSuite) TestDeadLetterHandlerOverflowSingleGR() {
+	var (
+		dropped []interface{}
+		reasons []string
+	)
+	suite.fifo = NewFixedFIFO(2)
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		dropped = append(dropped, value)
+		reasons = append(reasons, reason)
+	})
+
+	suite.fifo.SetOverflowPolicy(OverflowDropNewest)
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+	suite.fifo.Enqueue(3)
+
+	suite.Equal([]interface{}{2, 0}, dropped, "The dropped elements must be discarded")
+	suite.Equal([]string{DeadLetterReasonOverflow, DeadLetterReasonOverflow}, reasons, "Unexpected reasons")
+
+	suite.fifo.SetDeadLetterHandler(nil)
+	suite.fifo.Enqueue(4)
+	suite.Len(dropped, 2, "The handler must not be called once disabled")
+}
+
+// expired elements
+func (suite *FixedFIFOTestSuite) TestDeadLetterHandlerExpiredSingleGR() {
+	var (
+		clock   = newFakeClock()
+		dropped []interface{}
+	)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		suite.Equal(DeadLetterReasonExpired, reason, "Unexpected reason")
+		dropped = append(dropped, value)
+	})
+	suite.fifo.SetMaxAge(time.Hour, nil)
+	defer suite.fifo.SetMaxAge(0, nil)
+
+	suite.fifo.Enqueue(1)
+	clock.Advance(time.Hour)
+	suite.fifo.Enqueue(2)
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, value, "Expired elements must be skipped")
+	suite.Equal([]interface{}{1}, dropped, "The expired elements must be discarded")
+}
+
+// the element waiting to be received gets discarded if it could not be enqueued back
+func (suite *FixedFIFOTestSuite) TestChannelContextLockedSingleGR() {
+	dropped := make(chan interface{}, 1)
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		suite.Equal(DeadLetterReasonRequeueFailed, reason, "Unexpected reason")
+		dropped <- value
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.fifo.Channel(ctx)
+
+	suite.fifo.Enqueue(1)
+	// let the goroutine dequeue the element
+	for suite.fifo.GetLen() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	suite.fifo.Lock()
+	cancel()
+
+	select {
+	case value := <-dropped:
+		suite.Equal(1, value, "The element waiting to be received must be discarded")
+	case <-time.After(2 * time.Second):
+		suite.Fail("The element waiting to be received must be discarded")
+	}
+}
+
+// ***************************************************************************************
+// ** GetLen (length counter)
+// ***************************************************************************************
+
+// GetLen keeps a locked queue locked
+func (suite *FixedFIFOTestSuite) TestGetLenLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	suite.Equal(1, suite.fifo.GetLen(), "unexpected length")
+	suite.True(suite.fifo.IsLocked(), "GetLen must not unlock the queue")
+}
+
+// the length counter follows the dropped and expired elements
+func (suite *FixedFIFOTestSuite) TestGetLenDroppedSingleGR() {
+	clock := newFakeClock()
+	suite.fifo = NewFixedFIFO(2)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.Equal(2, suite.fifo.GetLen(), "The dropped elements must not be counted")
+
+	suite.fifo.SetMaxAge(time.Hour, nil)
+	defer suite.fifo.SetMaxAge(0, nil)
+	suite.fifo.Dequeue()
+	suite.fifo.Dequeue()
+	suite.fifo.Enqueue(5)
+	clock.Advance(time.Hour)
+	suite.fifo.Dequeue()
+	suite.Equal(0, suite.fifo.GetLen(), "The expired elements must not be counted")
+}
+
+// the length counter never diverges from the enqueued elements
+func (suite *FixedFIFOTestSuite) TestGetLenCounterMultipleGRs() {
+	var (
+		totalGRs = 5
+		clock    = newFakeClock()
+		wg       sync.WaitGroup
+	)
+	suite.fifo = NewFixedFIFO(50)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetOverflowPolicy(OverflowDropOldest)
+	// no background sweeps: the expired elements get removed by the dequeues and SweepExpired
+	suite.fifo.SetMaxAgeSweepInterval(time.Hour)
+	suite.fifo.SetMaxAge(time.Minute, nil)
+	defer suite.fifo.SetMaxAge(0, nil)
+
+	for wave := 0; wave < 10; wave++ {
+		wg.Add(3 * totalGRs)
+		for i := 0; i < totalGRs; i++ {
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 30; j++ {
+					suite.fifo.Enqueue(j)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					suite.fifo.Dequeue()
+					suite.fifo.TryDequeueN(2)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				clock.Advance(10 * time.Second)
+				suite.fifo.SweepExpired()
+			}()
+		}
+		wg.Wait()
+
+		suite.Equal(int64(len(suite.fifo.queue)), atomic.LoadInt64(&suite.fifo.length),
+			"The counter must match the enqueued elements (wave %v)", wave)
+	}
+}
+
+// ***************************************************************************************
+// ** SetTrackWaiters / WaitingOperations
+// ***************************************************************************************
+
+// no waiter gets tracked by default
+func (suite *FixedFIFOTestSuite) TestWaitingOperationsDisabledSingleGR() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	go suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+	for atomic.LoadInt64(&suite.fifo.dequeueWaiters) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	suite.Len(suite.fifo.WaitingOperations(), 0, "No waiter must be tracked by default")
+}
+
+// the blocked operations get tracked while they wait
+func (suite *FixedFIFOTestSuite) TestWaitingOperationsSingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.fifo.SetTrackWaiters(true)
+	suite.fifo.Enqueue(0)
+
+	// enqueue-slot waiter
+	enqueued := make(chan struct{})
+	go func() {
+		defer close(enqueued)
+		suite.fifo.EnqueueBatchOrWait(context.Background(), []interface{}{1})
+	}()
+	for atomic.LoadInt64(&suite.fifo.enqueueWaiters) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// dequeue waiter
+	suite.fifo.PauseDequeue()
+	ctx, cancel := context.WithCancel(context.Background())
+	dequeued := make(chan struct{})
+	go func() {
+		defer close(dequeued)
+		suite.fifo.DequeueOrWaitForNextElementContext(ctx)
+	}()
+	for atomic.LoadInt64(&suite.fifo.dequeueWaiters) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waiters := suite.fifo.WaitingOperations()
+	suite.Len(waiters, 2, "Every waiting operation must be tracked")
+	suite.Equal(WaiterOperationEnqueueSlot, waiters[0].Operation, "The oldest waiter first")
+	suite.Equal(WaiterOperationDequeue, waiters[1].Operation, "Wrong operation")
+
+	cancel()
+	<-dequeued
+	suite.fifo.ResumeDequeue()
+	suite.fifo.Dequeue()
+	<-enqueued
+	suite.Len(suite.fifo.WaitingOperations(), 0, "The waiters must be untracked once they stop waiting")
+}
+
+// ***************************************************************************************
+// ** WaitNonEmpty
+// ***************************************************************************************
+
+// returns at once if there are elements, without dequeuing them
+func (suite *FixedFIFOTestSuite) TestWaitNonEmptySingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.NoError(suite.fifo.WaitNonEmpty(context.Background()), "Unexpected error")
+	suite.Equal(1, suite.fifo.GetLen(), "WaitNonEmpty must not dequeue")
+}
+
+// ctx cancellation while the queue is empty
+func (suite *FixedFIFOTestSuite) TestWaitNonEmptyContextSingleGR() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	suite.Equal(context.DeadlineExceeded, suite.fifo.WaitNonEmpty(ctx), "ctx's error expected")
+}
+
+// every waiter gets woken up by the enqueue
+func (suite *FixedFIFOTestSuite) TestWaitNonEmptyMultipleGRs() {
+	const waiters = 5
+	errs := make(chan error, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			errs <- suite.fifo.WaitNonEmpty(context.Background())
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	suite.fifo.Enqueue(1)
+
+	for i := 0; i < waiters; i++ {
+		select {
+		case err := <-errs:
+			suite.NoError(err, "Unexpected error")
+		case <-time.After(2 * time.Second):
+			suite.FailNow("The waiters must be woken up by the enqueue")
+		}
+	}
+	suite.Equal(1, suite.fifo.GetLen(), "WaitNonEmpty must not dequeue")
+}
+
+// ***************************************************************************************
+// ** SetWatermarks
+// ***************************************************************************************
+
+// the callbacks get called only on the transitions
+func (suite *FixedFIFOTestSuite) TestSetWatermarksSingleGR() {
+	var highs, lows int
+	suite.fifo.SetWatermarks(3, 1, func() { highs++ }, func() { lows++ })
+
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.Equal(1, highs, "onHigh must be called once the length rises above high")
+
+	// in between low and high
+	suite.fifo.Dequeue()
+	suite.fifo.Dequeue()
+	suite.fifo.Dequeue()
+	suite.Equal(0, lows, "onLow must not be called while the length is not below low")
+	suite.fifo.Enqueue(5)
+	suite.Equal(1, highs, "onHigh must not be called again until onLow gets called")
+
+	suite.fifo.TryDequeueN(3)
+	suite.Equal(1, lows, "onLow must be called once the length falls below low")
+
+	for i := 0; i < 4; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.Equal(2, highs, "onHigh must be called again after onLow")
+
+	suite.fifo.SetWatermarks(0, 0, nil, nil)
+	suite.fifo.TryDequeueN(4)
+	suite.Equal(1, lows, "The watermarks must be disabled")
+}
+
+// the callbacks could call the queue
+func (suite *FixedFIFOTestSuite) TestSetWatermarksReentrantSingleGR() {
+	suite.fifo.SetWatermarks(1, 1, func() { suite.fifo.Dequeue() }, nil)
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	suite.Equal(1, suite.fifo.GetLen(), "onHigh must be able to dequeue")
+}
+
+// the callbacks get called in the transitions' order, alternating
+func (suite *FixedFIFOTestSuite) TestSetWatermarksMultipleGRs() {
+	var (
+		mutex     sync.Mutex
+		callbacks []string
+	)
+	record := func(name string) func() {
+		return func() {
+			mutex.Lock()
+			callbacks = append(callbacks, name)
+			mutex.Unlock()
+		}
+	}
+	suite.fifo.SetWatermarks(5, 2, record("high"), record("low"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				suite.fifo.Enqueue(j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				suite.fifo.Dequeue()
+			}
+		}()
+	}
+	wg.Wait()
+	suite.fifo.TryDequeueN(suite.fifo.GetCap())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, name := range callbacks {
+		if i%2 == 0 {
+			suite.Equal("high", name, "onHigh must be followed by onLow")
+		} else {
+			suite.Equal("low", name, "onLow must be followed by onHigh")
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** SweepExpired
+// ***************************************************************************************
+
+// the expired elements get removed wherever they are
+func (suite *FixedFIFOTestSuite) TestSweepExpiredSingleGR() {
+	var (
+		clock   = newFakeClock()
+		expired []interface{}
+		dead    []string
+	)
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) { dead = append(dead, reason) })
+	suite.fifo.Enqueue(0)
+	suite.fifo.SetMaxAge(time.Hour, func(value interface{}) { expired = append(expired, value) })
+	defer suite.fifo.SetMaxAge(0, nil)
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	clock.Advance(time.Hour)
+	suite.fifo.Enqueue(3)
+
+	suite.Equal(2, suite.fifo.SweepExpired(), "Wrong number of removed elements")
+	suite.Equal([]interface{}{1, 2}, expired, "onExpire must be called for the removed elements")
+	suite.Equal([]string{DeadLetterReasonExpired, DeadLetterReasonExpired}, dead, "Wrong dead letter reasons")
+	suite.Equal(2, suite.fifo.GetLen(), "Wrong length after the sweep")
+	suite.Equal([]interface{}{0, 3}, suite.fifo.TryDequeueN(2), "The order of the rest must be kept")
+	suite.Equal(0, suite.fifo.SweepExpired(), "Nothing to remove")
+}
+
+// concurrent sweeps: every expired element is removed once
+func (suite *FixedFIFOTestSuite) TestSweepExpiredMultipleGRs() {
+	clock := newFakeClock()
+	suite.fifo.SetClock(clock)
+	suite.fifo.SetMaxAge(time.Hour, nil)
+	defer suite.fifo.SetMaxAge(0, nil)
+	for i := 0; i < suite.fifo.GetCap(); i++ {
+		suite.fifo.Enqueue(i)
+	}
+	clock.Advance(time.Hour)
+
+	var (
+		wg      sync.WaitGroup
+		removed int64
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			atomic.AddInt64(&removed, int64(suite.fifo.SweepExpired()))
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(int64(suite.fifo.GetCap()), removed, "Every expired element must be removed once")
+	suite.Equal(0, suite.fifo.GetLen(), "The queue must be empty")
+}
+
+// ***************************************************************************************
+// ** RejectionStats / ResetRejectionStats
+// ***************************************************************************************
+
+// every rejected enqueue gets counted by reason
+func (suite *FixedFIFOTestSuite) TestRejectionStatsSingleGR() {
+	suite.fifo = NewFixedFIFO(1)
+	suite.Equal(map[string]uint64{
+		RejectionReasonFull:   0,
+		RejectionReasonClosed: 0,
+		RejectionReasonLocked: 0,
+	}, suite.fifo.RejectionStats(), "Every reason must be included")
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	suite.fifo.ReserveSlot()
+	// dropped elements are not rejected
+	suite.fifo.SetOverflowPolicy(OverflowDropNewest)
+	suite.fifo.Enqueue(3)
+	suite.fifo.SetOverflowPolicy(OverflowReject)
+
+	suite.fifo.Lock()
+	suite.fifo.Enqueue(4)
+	suite.fifo.Unlock()
+
+	suite.fifo.Dequeue()
+	suite.fifo.Close()
+	suite.fifo.Enqueue(5)
+
+	suite.Equal(map[string]uint64{
+		RejectionReasonFull:   2,
+		RejectionReasonClosed: 1,
+		RejectionReasonLocked: 1,
+	}, suite.fifo.RejectionStats(), "Wrong rejection stats")
+
+	suite.fifo.ResetRejectionStats()
+	for reason, count := range suite.fifo.RejectionStats() {
+		suite.Equal(uint64(0), count, "The %v counter must be reset", reason)
+	}
+}
+
+// concurrent rejections
+func (suite *FixedFIFOTestSuite) TestRejectionStatsMultipleGRs() {
+	suite.fifo = NewFixedFIFO(10)
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				suite.fifo.Enqueue(j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(uint64(200), suite.fifo.RejectionStats()[RejectionReasonFull], "Every rejection must be counted")
+}
@@ -0,0 +1,88 @@
+package goconcurrentqueue
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Operations waiting on a FixedFIFO, see WaiterInfo
+const (
+	WaiterOperationDequeue     = "dequeue"
+	WaiterOperationEnqueueSlot = "enqueue-slot"
+)
+
+// WaiterInfo describes an operation blocked on a FixedFIFO, see FixedFIFO.WaitingOperations
+type WaiterInfo struct {
+	// WaiterOperationDequeue (waiting for an element or for dequeuing to be resumed) or WaiterOperationEnqueueSlot
+	// (waiting for an available slot)
+	Operation string
+	// time the operation started waiting (see FixedFIFO.SetClock)
+	Since time.Time
+}
+
+// waiterInfos sorts the waiters by Since (the oldest first)
+type waiterInfos []WaiterInfo
+
+func (st waiterInfos) Len() int           { return len(st) }
+func (st waiterInfos) Less(i, j int) bool { return st[i].Since.Before(st[j].Since) }
+func (st waiterInfos) Swap(i, j int)      { st[i], st[j] = st[j], st[i] }
+
+// untrackNothing is returned by trackWaiter while the waiters are not tracked
+func untrackNothing() {}
+
+// SetTrackWaiters enables/disables tracking the blocked operations, see WaitingOperations. Disabled by default: it
+// costs a single atomic load per wait while disabled. The operations already waiting while it gets enabled are not
+// tracked.
+func (st *FixedFIFO) SetTrackWaiters(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&st.trackWaiters, 1)
+	} else {
+		atomic.StoreInt32(&st.trackWaiters, 0)
+	}
+}
+
+// WaitingOperations returns the operations currently waiting (the oldest first), e.g. to find stuck consumers. It
+// requires waiters tracking (see SetTrackWaiters), otherwise it returns an empty slice.
+func (st *FixedFIFO) WaitingOperations() []WaiterInfo {
+	st.waitersMutex.Lock()
+	ret := make([]WaiterInfo, 0, len(st.waiters))
+	for _, info := range st.waiters {
+		ret = append(ret, info)
+	}
+	st.waitersMutex.Unlock()
+
+	sort.Stable(waiterInfos(ret))
+
+	return ret
+}
+
+// trackWaiter registers an operation that starts waiting (if the waiters are tracked), returning the function to be
+// called once it stops waiting
+func (st *FixedFIFO) trackWaiter(operation string) (untrack func()) {
+	if atomic.LoadInt32(&st.trackWaiters) == 0 {
+		return untrackNothing
+	}
+
+	info := WaiterInfo{
+		Operation: operation,
+		Since:     st.getClock().Now(),
+	}
+
+	st.waitersMutex.Lock()
+	defer st.waitersMutex.Unlock()
+
+	if st.waiters == nil {
+		st.waiters = make(map[uint64]WaiterInfo)
+	}
+	st.nextWaiterID++
+	id := st.nextWaiterID
+	st.waiters[id] = info
+
+	return func() {
+		st.waitersMutex.Lock()
+		defer st.waitersMutex.Unlock()
+
+		delete(st.waiters, id)
+	}
+}
@@ -0,0 +1,71 @@
+package goconcurrentqueue
+
+import (
+	"sync/atomic"
+)
+
+// SetWatermarks sets the functions to be called once the length (see GetLen) rises above high (onHigh) and once it
+// falls back below low (onLow), e.g. to pause the producers and to resume them. Each one gets called only on its
+// transition: nothing gets called while the length moves in between low and high. The callbacks are executed outside
+// the queue's locks, one at a time and in the transitions' order. A low greater than high is taken as high, a nil
+// callback skips its transition. high <= 0 disables the watermarks (default). onHigh gets called right away if the
+// length is already above high.
+func (st *FixedFIFO) SetWatermarks(high, low int, onHigh, onLow func()) {
+	if low > high {
+		low = high
+	}
+
+	st.watermarksMutex.Lock()
+	st.highWatermark = high
+	st.lowWatermark = low
+	st.onHighWatermark = onHigh
+	st.onLowWatermark = onLow
+	st.aboveHighWatermark = false
+	if high > 0 {
+		atomic.StoreInt32(&st.watermarks, 1)
+	} else {
+		atomic.StoreInt32(&st.watermarks, 0)
+	}
+	st.watermarksMutex.Unlock()
+
+	st.checkWatermarks()
+}
+
+// checkWatermarks calls the watermark callbacks if the length has crossed a watermark (see SetWatermarks). It must be
+// called after the length changes, not holding any queue's lock.
+func (st *FixedFIFO) checkWatermarks() {
+	if atomic.LoadInt32(&st.watermarks) == 0 {
+		return
+	}
+
+	st.watermarksMutex.Lock()
+	length := st.GetLen()
+	switch {
+	case !st.aboveHighWatermark && st.highWatermark > 0 && length > st.highWatermark:
+		st.aboveHighWatermark = true
+		st.pendingWatermarks = append(st.pendingWatermarks, st.onHighWatermark)
+	case st.aboveHighWatermark && length < st.lowWatermark:
+		st.aboveHighWatermark = false
+		st.pendingWatermarks = append(st.pendingWatermarks, st.onLowWatermark)
+	}
+
+	// the callbacks already get called by another goroutine (or by a callback calling the queue in the meantime)
+	if st.callingWatermarks {
+		st.watermarksMutex.Unlock()
+		return
+	}
+
+	st.callingWatermarks = true
+	for len(st.pendingWatermarks) > 0 {
+		callback := st.pendingWatermarks[0]
+		st.pendingWatermarks = st.pendingWatermarks[1:]
+
+		st.watermarksMutex.Unlock()
+		if callback != nil {
+			callback()
+		}
+		st.watermarksMutex.Lock()
+	}
+	st.callingWatermarks = false
+	st.watermarksMutex.Unlock()
+}
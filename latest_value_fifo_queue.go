@@ -0,0 +1,99 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// LatestValueFIFO is a concurrent queue keeping only the latest enqueued element (mailbox): Enqueue replaces the stored
+// element (if any), so consumers always get the freshest value. Its capacity is 1.
+type LatestValueFIFO struct {
+	// 1 == locked (accessed atomically)
+	locked int32
+	// the stored element (capacity 1)
+	queue chan interface{}
+	// held by the producers, so replacing the stored element is atomic
+	enqueueMutex sync.Mutex
+}
+
+// NewLatestValueFIFO returns a new LatestValueFIFO queue
+func NewLatestValueFIFO() *LatestValueFIFO {
+	queue := &LatestValueFIFO{}
+	queue.initialize()
+
+	return queue
+}
+
+func (st *LatestValueFIFO) initialize() {
+	st.queue = make(chan interface{}, 1)
+}
+
+// Enqueue stores an element, replacing the stored one (if any)
+func (st *LatestValueFIFO) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return errors.New("The queue is locked")
+	}
+
+	st.enqueueMutex.Lock()
+	defer st.enqueueMutex.Unlock()
+
+	select {
+	case <-st.queue:
+	default:
+	}
+	// no other producer could fill the slot in the meantime
+	st.queue <- value
+
+	return nil
+}
+
+// Dequeue dequeues the stored element, returning an error if there is none
+func (st *LatestValueFIFO) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, errors.New("The queue is locked")
+	}
+
+	select {
+	case value := <-st.queue:
+		return value, nil
+	default:
+		return nil, fmt.Errorf("queue is empty")
+	}
+}
+
+// DequeueOrWaitForNextElement dequeues the stored element, waiting for the next one if there is none. The lock is
+// checked before waiting: locking the queue does not wake up the waiting consumers.
+func (st *LatestValueFIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, errors.New("The queue is locked")
+	}
+
+	return <-st.queue, nil
+}
+
+// GetLen returns the number of stored elements (0 or 1)
+func (st *LatestValueFIFO) GetLen() int {
+	return len(st.queue)
+}
+
+// GetCap returns the queue's capacity (1)
+func (st *LatestValueFIFO) GetCap() int {
+	return cap(st.queue)
+}
+
+// Lock locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *LatestValueFIFO) Lock() {
+	atomic.StoreInt32(&st.locked, 1)
+}
+
+// Unlock unlocks the queue
+func (st *LatestValueFIFO) Unlock() {
+	atomic.StoreInt32(&st.locked, 0)
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *LatestValueFIFO) IsLocked() bool {
+	return atomic.LoadInt32(&st.locked) == 1
+}
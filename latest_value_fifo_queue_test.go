@@ -0,0 +1,121 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LatestValueFIFOTestSuite struct {
+	suite.Suite
+	fifo *LatestValueFIFO
+}
+
+func (suite *LatestValueFIFOTestSuite) SetupTest() {
+	suite.fifo = NewLatestValueFIFO()
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestLatestValueFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(LatestValueFIFOTestSuite))
+}
+
+// ***************************************************************************************
+// ** Queue interface
+// ***************************************************************************************
+
+// LatestValueFIFO implements the Queue interface
+func (suite *LatestValueFIFOTestSuite) TestQueueInterface() {
+	var queue Queue = suite.fifo
+
+	suite.NoError(queue.Enqueue(testValue), "Unexpected error")
+	suite.Equal(1, queue.GetLen(), "Unexpected length")
+	suite.Equal(1, queue.GetCap(), "Unexpected capacity")
+
+	value, err := queue.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(testValue, value, "Wrong element's value")
+}
+
+// ***************************************************************************************
+// ** Enqueue / Dequeue
+// ***************************************************************************************
+
+// only the latest element is kept
+func (suite *LatestValueFIFOTestSuite) TestEnqueueDequeueSingleGR() {
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.fifo.Enqueue(i), "Enqueue must never fail for a full queue")
+	}
+	suite.Equal(1, suite.fifo.GetLen(), "Wrong queue's length")
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(2, value, "The latest element is expected")
+
+	_, err = suite.fifo.Dequeue()
+	suite.Error(err, "Empty queue error expected")
+}
+
+// single lock verification
+func (suite *LatestValueFIFOTestSuite) TestLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.True(suite.fifo.IsLocked(), "The queue must be locked")
+
+	suite.Error(suite.fifo.Enqueue(2), "Locked queue does not allow to enqueue elements")
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+	_, err = suite.fifo.DequeueOrWaitForNextElement()
+	suite.Error(err, "Locked queue does not allow to dequeue elements")
+
+	suite.fifo.Unlock()
+	suite.False(suite.fifo.IsLocked(), "The queue must be unlocked")
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// the consumer waits for the next element
+func (suite *LatestValueFIFOTestSuite) TestDequeueOrWaitForNextElementSingleGR() {
+	result := make(chan interface{})
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+
+	select {
+	case <-result:
+		suite.Fail("The consumer must wait for an element")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	suite.fifo.Enqueue(1)
+	suite.Equal(1, <-result, "Wrong element's value")
+}
+
+// concurrent producers never block, consumers get the latest values
+func (suite *LatestValueFIFOTestSuite) TestEnqueueMultipleGRs() {
+	var (
+		totalGRs = 20
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			for c := 0; c < 10; c++ {
+				suite.NoError(suite.fifo.Enqueue(value), "Unexpected error")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(1, suite.fifo.GetLen(), "Only one element must be kept")
+}
@@ -0,0 +1,109 @@
+package goconcurrentqueue
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// number of length samples kept by lengthSampler (the latest ones)
+	lengthSamples = 1024
+)
+
+// lengthSampler samples a queue's length periodically, keeping the latest lengthSamples samples
+type lengthSampler struct {
+	mutex   sync.Mutex
+	samples *ring
+	// closed to stop the sampling goroutine (nil == not sampling)
+	stopChan chan struct{}
+}
+
+// start discards the previous samples and starts sampling getLen every interval (restarting the sampling if it was
+// already running). An interval <= 0 just stops the sampling.
+func (st *lengthSampler) start(interval time.Duration, getLen func() int) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.stopSampling()
+	if interval <= 0 {
+		return
+	}
+
+	st.samples = newRing(lengthSamples)
+	st.stopChan = make(chan struct{})
+	go st.sample(interval, getLen, st.stopChan)
+}
+
+// stop stops the sampling, keeping the samples
+func (st *lengthSampler) stop() {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.stopSampling()
+}
+
+// stopSampling stops the sampling goroutine (if any). It must be called holding st.mutex.
+func (st *lengthSampler) stopSampling() {
+	if st.stopChan != nil {
+		close(st.stopChan)
+		st.stopChan = nil
+	}
+}
+
+// sample adds a sample every interval, until stop gets closed
+func (st *lengthSampler) sample(interval time.Duration, getLen func() int, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			st.add(getLen())
+		}
+	}
+}
+
+// add adds a sample
+func (st *lengthSampler) add(length int) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.samples != nil {
+		st.samples.add(length)
+	}
+}
+
+// percentiles returns the 50th, 95th and 99th percentiles (nearest rank) of the samples, 0 if there are no samples
+func (st *lengthSampler) percentiles() (p50, p95, p99 int) {
+	st.mutex.Lock()
+	var values []interface{}
+	if st.samples != nil {
+		values = st.samples.slice()
+	}
+	st.mutex.Unlock()
+
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+
+	lengths := make([]int, len(values))
+	for i, value := range values {
+		lengths[i] = value.(int)
+	}
+	sort.Ints(lengths)
+
+	return percentile(lengths, 50), percentile(lengths, 95), percentile(lengths, 99)
+}
+
+// percentile returns the p-th percentile (nearest rank) of the given sorted values
+func percentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
@@ -0,0 +1,68 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LengthSamplerTestSuite struct {
+	suite.Suite
+	sampler *lengthSampler
+}
+
+func (suite *LengthSamplerTestSuite) SetupTest() {
+	suite.sampler = &lengthSampler{}
+	// a huge interval: the samples are added by the tests
+	suite.sampler.start(1<<62, func() int { return 0 })
+}
+
+func (suite *LengthSamplerTestSuite) TearDownTest() {
+	suite.sampler.stop()
+}
+
+// ***************************************************************************************
+// ** percentiles
+// ***************************************************************************************
+
+// nearest rank percentiles
+func (suite *LengthSamplerTestSuite) TestPercentiles() {
+	for i := 100; i >= 1; i-- {
+		suite.sampler.add(i)
+	}
+
+	p50, p95, p99 := suite.sampler.percentiles()
+	suite.Equal(50, p50, "Wrong 50th percentile")
+	suite.Equal(95, p95, "Wrong 95th percentile")
+	suite.Equal(99, p99, "Wrong 99th percentile")
+}
+
+// only the latest samples are kept
+func (suite *LengthSamplerTestSuite) TestPercentilesBoundedSamples() {
+	for i := 0; i < lengthSamples; i++ {
+		suite.sampler.add(1000)
+	}
+	for i := 0; i < lengthSamples; i++ {
+		suite.sampler.add(1)
+	}
+
+	p50, p95, p99 := suite.sampler.percentiles()
+	suite.Equal([]int{1, 1, 1}, []int{p50, p95, p99}, "The oldest samples must be discarded")
+}
+
+// restarting the sampling discards the samples
+func (suite *LengthSamplerTestSuite) TestRestart() {
+	suite.sampler.add(5)
+	suite.sampler.start(1<<62, func() int { return 0 })
+
+	p50, _, _ := suite.sampler.percentiles()
+	suite.Equal(0, p50, "The samples must be discarded")
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestLengthSamplerTestSuite(t *testing.T) {
+	suite.Run(t, new(LengthSamplerTestSuite))
+}
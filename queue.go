@@ -18,3 +18,21 @@ type Queue interface {
 	// Return true whether the queue is locked
 	IsLocked() bool
 }
+
+// QueueReader interface with the consumer side of a queue, see FIFO.Reader
+type QueueReader interface {
+	// Dequeue element
+	Dequeue() (interface{}, error)
+	// Dequeue element, waiting for a new one if the queue is empty
+	DequeueOrWaitForNextElement() (interface{}, error)
+	// Get the first element, keeping it at the queue
+	Peek() (interface{}, error)
+	// Get number of enqueued elements
+	GetLen() int
+}
+
+// QueueWriter interface with the producer side of a queue, see FIFO.Writer
+type QueueWriter interface {
+	// Enqueue element
+	Enqueue(interface{}) error
+}
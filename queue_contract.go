@@ -0,0 +1,86 @@
+package goconcurrentqueue
+
+// TestingT is the minimal interface needed by AssertQueueContract, *testing.T implements it
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertQueueContract verifies that q behaves as the queues of this package do: FIFO order, errors dequeuing from an
+// empty queue and the lock behavior (no enqueue/dequeue allowed while locked). Every violation gets reported by
+// t.Errorf, it returns true whether q fulfills the contract.
+//
+// q must be empty, unlocked and able to hold at least 3 elements. It is left empty and unlocked.
+func AssertQueueContract(t TestingT, q Queue) bool {
+	ok := true
+	fail := func(format string, args ...interface{}) {
+		ok = false
+		t.Errorf(format, args...)
+	}
+
+	// empty queue
+	if length := q.GetLen(); length != 0 {
+		fail("empty queue expected, got length %v", length)
+	}
+	if _, err := q.Dequeue(); err == nil {
+		fail("error expected dequeuing from an empty queue")
+	}
+
+	// FIFO order
+	values := []interface{}{1, 2, 3}
+	for _, value := range values {
+		if err := q.Enqueue(value); err != nil {
+			fail("unexpected error enqueuing %v: %v", value, err)
+		}
+	}
+	if length := q.GetLen(); length != len(values) {
+		fail("length %v expected, got %v", len(values), length)
+	}
+	if capacity := q.GetCap(); capacity < q.GetLen() {
+		fail("capacity %v is smaller than the length %v", capacity, q.GetLen())
+	}
+	for _, expected := range values {
+		value, err := q.Dequeue()
+		if err != nil {
+			fail("unexpected error dequeuing: %v", err)
+			continue
+		}
+		if value != expected {
+			fail("%v expected to be dequeued (FIFO order), got %v", expected, value)
+		}
+	}
+	if length := q.GetLen(); length != 0 {
+		fail("empty queue expected once all the elements were dequeued, got length %v", length)
+	}
+
+	// lock
+	if err := q.Enqueue(values[0]); err != nil {
+		fail("unexpected error enqueuing %v: %v", values[0], err)
+	}
+	q.Lock()
+	if !q.IsLocked() {
+		fail("locked queue expected after Lock")
+	}
+	if err := q.Enqueue(values[1]); err == nil {
+		fail("error expected enqueuing into a locked queue")
+	}
+	if _, err := q.Dequeue(); err == nil {
+		fail("error expected dequeuing from a locked queue")
+	}
+
+	q.Unlock()
+	if q.IsLocked() {
+		fail("unlocked queue expected after Unlock")
+	}
+	// drain the queue (whatever the locked queue accepted)
+	value, err := q.Dequeue()
+	if err != nil || value != values[0] {
+		fail("%v expected to be dequeued after Unlock, got %v (error: %v)", values[0], value, err)
+	}
+	for q.GetLen() > 0 {
+		if _, err := q.Dequeue(); err != nil {
+			break
+		}
+	}
+
+	return ok
+}
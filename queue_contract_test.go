@@ -0,0 +1,94 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueContractTestSuite struct {
+	suite.Suite
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueContractTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueContractTestSuite))
+}
+
+// ***************************************************************************************
+// ** AssertQueueContract
+// ***************************************************************************************
+
+// recordingT records the reported errors
+type recordingT struct {
+	errors []string
+}
+
+func (st *recordingT) Errorf(format string, args ...interface{}) {
+	st.errors = append(st.errors, fmt.Sprintf(format, args...))
+}
+
+// lifoQueue is a (wrong) Queue implementation: LIFO order and it ignores the lock
+type lifoQueue struct {
+	slice  []interface{}
+	locked bool
+}
+
+func (st *lifoQueue) Enqueue(value interface{}) error {
+	st.slice = append(st.slice, value)
+	return nil
+}
+
+func (st *lifoQueue) Dequeue() (interface{}, error) {
+	if len(st.slice) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+	value := st.slice[len(st.slice)-1]
+	st.slice = st.slice[:len(st.slice)-1]
+	return value, nil
+}
+
+func (st *lifoQueue) GetLen() int    { return len(st.slice) }
+func (st *lifoQueue) GetCap() int    { return cap(st.slice) }
+func (st *lifoQueue) Lock()          { st.locked = true }
+func (st *lifoQueue) Unlock()        { st.locked = false }
+func (st *lifoQueue) IsLocked() bool { return st.locked }
+
+// the package's queues fulfill the contract
+func (suite *QueueContractTestSuite) TestQueuesSingleGR() {
+	queues := map[string]Queue{
+		"FIFO":             NewFIFO(),
+		"FixedFIFO":        NewFixedFIFO(3),
+		"RetryFIFO":        NewRetryFIFO(0),
+		"WeightedBandFIFO": NewWeightedBandFIFO([]int{2, 1}),
+		"SPSCFixedFIFO":    NewSPSCFixedFIFO(3),
+		"UnsafeFIFO":       NewUnsafeFIFO(),
+	}
+
+	for name, queue := range queues {
+		t := &recordingT{}
+		suite.Truef(AssertQueueContract(t, queue), "%v must fulfill the contract", name)
+		suite.Emptyf(t.errors, "No errors expected for %v", name)
+		suite.Equalf(0, queue.GetLen(), "%v must be left empty", name)
+		suite.Falsef(queue.IsLocked(), "%v must be left unlocked", name)
+	}
+}
+
+// violations get reported
+func (suite *QueueContractTestSuite) TestViolationsSingleGR() {
+	t := &recordingT{}
+	suite.False(AssertQueueContract(t, &lifoQueue{}), "lifoQueue must not fulfill the contract")
+	suite.NotEmpty(t.errors, "The violations must be reported")
+
+	for _, expected := range []string{
+		"1 expected to be dequeued (FIFO order), got 3",
+		"error expected enqueuing into a locked queue",
+		"error expected dequeuing from a locked queue",
+	} {
+		suite.Contains(t.errors, expected, "Missing violation")
+	}
+}
@@ -0,0 +1,34 @@
+package goconcurrentqueue
+
+// QueueError codes
+const (
+	QueueErrorCodeTooManyWaiters  = "too-many-waiters"
+	QueueErrorCodeSequenceGap     = "sequence-gap"
+	QueueErrorCodeTypeMismatch    = "type-mismatch"
+	QueueErrorCodeVersionConflict = "version-conflict"
+	QueueErrorCodeElementTooLarge = "element-too-large"
+)
+
+// QueueError is an error carrying a code (QueueErrorCode...) to identify its cause without parsing the message
+type QueueError struct {
+	code    string
+	message string
+}
+
+// NewQueueError returns a new QueueError
+func NewQueueError(code string, message string) *QueueError {
+	return &QueueError{
+		code:    code,
+		message: message,
+	}
+}
+
+// Error returns the error's message
+func (st *QueueError) Error() string {
+	return st.message
+}
+
+// Code returns the error's code
+func (st *QueueError) Code() string {
+	return st.code
+}
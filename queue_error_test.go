@@ -0,0 +1,27 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueErrorTestSuite struct {
+	suite.Suite
+}
+
+// code and message
+func (suite *QueueErrorTestSuite) TestCodeAndMessage() {
+	err := NewQueueError(QueueErrorCodeTooManyWaiters, "too many waiters")
+
+	suite.Equal(QueueErrorCodeTooManyWaiters, err.Code(), "Wrong error's code")
+	suite.Equal("too many waiters", err.Error(), "Wrong error's message")
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueErrorTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueErrorTestSuite))
+}
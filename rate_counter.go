@@ -0,0 +1,64 @@
+package goconcurrentqueue
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// rateCounter's bucket width && total buckets (the max window is rateBucketWidth * rateBuckets)
+	rateBucketWidth = 100 * time.Millisecond
+	rateBuckets     = 600
+)
+
+// rateCounter counts events using time buckets, to calculate events-per-second over a sliding window. It is lock-free:
+// it must be 64-bit aligned (e.g. the first field of a struct) on 32-bit platforms.
+type rateCounter struct {
+	// every bucket (accessed atomically) keeps the slot (time / rateBucketWidth, lower 32 bits) it belongs to in the
+	// upper 32 bits and the events in the lower 32 bits
+	buckets [rateBuckets]uint64
+}
+
+// add adds n events that happened at the given time
+func (st *rateCounter) add(now time.Time, n uint64) {
+	slot := now.UnixNano() / int64(rateBucketWidth)
+	bucket := &st.buckets[slot%rateBuckets]
+	tag := uint64(uint32(slot)) << 32
+
+	for {
+		old := atomic.LoadUint64(bucket)
+		updated := tag | n
+		if old&^0xffffffff == tag {
+			updated = old + n
+		}
+		if atomic.CompareAndSwapUint64(bucket, old, updated) {
+			return
+		}
+	}
+}
+
+// rate returns the events-per-second over the window ending at the given time. Windows larger than
+// rateBucketWidth * rateBuckets are truncated.
+func (st *rateCounter) rate(now time.Time, window time.Duration) float64 {
+	if window <= 0 {
+		return 0
+	}
+	if window > rateBucketWidth*rateBuckets {
+		window = rateBucketWidth * rateBuckets
+	}
+
+	var (
+		current = now.UnixNano() / int64(rateBucketWidth)
+		total   = int64((window + rateBucketWidth - 1) / rateBucketWidth)
+		events  uint64
+	)
+
+	for slot := current - total + 1; slot <= current; slot++ {
+		bucket := atomic.LoadUint64(&st.buckets[slot%rateBuckets])
+		if bucket>>32 == uint64(uint32(slot)) {
+			events += bucket & 0xffffffff
+		}
+	}
+
+	return float64(events) / window.Seconds()
+}
@@ -0,0 +1,77 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type rateCounterTestSuite struct {
+	suite.Suite
+	counter *rateCounter
+	now     time.Time
+}
+
+func (suite *rateCounterTestSuite) SetupTest() {
+	suite.counter = &rateCounter{}
+	suite.now = time.Unix(1000, 0)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestRateCounterTestSuite(t *testing.T) {
+	suite.Run(t, new(rateCounterTestSuite))
+}
+
+// ***************************************************************************************
+// ** add / rate
+// ***************************************************************************************
+
+// no events
+func (suite *rateCounterTestSuite) TestNoEvents() {
+	suite.Equal(float64(0), suite.counter.rate(suite.now, time.Second), "No events expected")
+	suite.Equal(float64(0), suite.counter.rate(suite.now, 0), "No events expected for an empty window")
+}
+
+// events within the window
+func (suite *rateCounterTestSuite) TestRate() {
+	suite.counter.add(suite.now.Add(-1500*time.Millisecond), 100)
+	suite.counter.add(suite.now.Add(-500*time.Millisecond), 10)
+	suite.counter.add(suite.now, 10)
+
+	suite.Equal(float64(20), suite.counter.rate(suite.now, time.Second), "Only the events within the window must be counted")
+	suite.Equal(float64(60), suite.counter.rate(suite.now, 2*time.Second), "Only the events within the window must be counted")
+}
+
+// old buckets are overwritten
+func (suite *rateCounterTestSuite) TestOverwrite() {
+	suite.counter.add(suite.now, 10)
+	later := suite.now.Add(rateBucketWidth * rateBuckets)
+	suite.counter.add(later, 1)
+
+	suite.Equal(float64(1), suite.counter.rate(later, time.Second), "Old events must not be counted")
+}
+
+// concurrent events
+func (suite *rateCounterTestSuite) TestAddMultipleGRs() {
+	var (
+		totalGRs = 10
+		wg       sync.WaitGroup
+	)
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				suite.counter.add(suite.now, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(float64(totalGRs*100), suite.counter.rate(suite.now, time.Second), "Every event must be counted")
+}
@@ -0,0 +1,148 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+)
+
+// retryElement is the element stored at RetryFIFO's internal queue
+type retryElement struct {
+	value   interface{}
+	attempt int
+}
+
+// RetryFIFO is a FIFO concurrent queue that tracks how many times each value was enqueued (attempts).
+// Values enqueued more than maxAttempts times are moved to the dead letters (see DeadLetters) instead of being enqueued.
+//
+// Values are tracked by equality, so they must be comparable (usable as map keys): uncomparable values (slices, maps,
+// ...) get rejected. The attempt counter of a value is kept until Ack is called for it.
+type RetryFIFO struct {
+	fifo        *FIFO
+	maxAttempts int
+	// attempts per value
+	attempts    map[interface{}]int
+	deadLetters []interface{}
+	mutex       sync.Mutex
+}
+
+// NewRetryFIFO returns a new RetryFIFO concurrent queue. maxAttempts <= 0 means unlimited attempts.
+func NewRetryFIFO(maxAttempts int) *RetryFIFO {
+	ret := &RetryFIFO{}
+	ret.initialize(maxAttempts)
+
+	return ret
+}
+
+func (st *RetryFIFO) initialize(maxAttempts int) {
+	st.fifo = NewFIFO()
+	st.maxAttempts = maxAttempts
+	st.attempts = make(map[interface{}]int)
+	st.deadLetters = make([]interface{}, 0)
+}
+
+// Enqueue enqueues an element, same as EnqueueRetry
+func (st *RetryFIFO) Enqueue(value interface{}) error {
+	return st.EnqueueRetry(value)
+}
+
+// EnqueueRetry enqueues an element increasing its attempt counter. If the value exceeds the max attempts it is moved
+// to the dead letters instead (no error is returned in such case) and the dead letter handler gets called. An error is
+// returned if the value is not comparable.
+func (st *RetryFIFO) EnqueueRetry(value interface{}) error {
+	if !hashable(value) {
+		return fmt.Errorf("uncomparable value of type %T: its attempts could not be tracked", value)
+	}
+
+	st.mutex.Lock()
+
+	attempt := st.attempts[value] + 1
+	if st.maxAttempts > 0 && attempt > st.maxAttempts {
+		delete(st.attempts, value)
+		st.deadLetters = append(st.deadLetters, value)
+		st.mutex.Unlock()
+
+		st.fifo.discard(DeadLetterReasonRetryExhausted, value)
+		return nil
+	}
+	defer st.mutex.Unlock()
+
+	if err := st.fifo.Enqueue(retryElement{value: value, attempt: attempt}); err != nil {
+		return err
+	}
+	st.attempts[value] = attempt
+
+	return nil
+}
+
+// Dequeue dequeues an element, same as DequeueRetry but without returning the attempt
+func (st *RetryFIFO) Dequeue() (interface{}, error) {
+	value, _, err := st.DequeueRetry()
+	return value, err
+}
+
+// DequeueRetry dequeues an element returning its attempt number (starting at 1)
+func (st *RetryFIFO) DequeueRetry() (interface{}, int, error) {
+	element, err := st.fifo.Dequeue()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	retry := element.(retryElement)
+	return retry.value, retry.attempt, nil
+}
+
+// Ack stops tracking the attempts of the given value, it should be called once the value was successfully processed.
+// A later EnqueueRetry(value) will be considered as the first attempt.
+func (st *RetryFIFO) Ack(value interface{}) {
+	if !hashable(value) {
+		// it could not have been enqueued
+		return
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	delete(st.attempts, value)
+}
+
+// SetDeadLetterHandler sets a function to be called every time a value exceeds the max attempts (reason:
+// DeadLetterReasonRetryExhausted). The handler is executed outside the queue's lock.
+func (st *RetryFIFO) SetDeadLetterHandler(handler func(value interface{}, reason string)) {
+	st.fifo.SetDeadLetterHandler(handler)
+}
+
+// DeadLetters returns the values that exceeded the max attempts
+func (st *RetryFIFO) DeadLetters() []interface{} {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	ret := make([]interface{}, len(st.deadLetters))
+	copy(ret, st.deadLetters)
+
+	return ret
+}
+
+// GetLen returns the number of enqueued elements
+func (st *RetryFIFO) GetLen() int {
+	return st.fifo.GetLen()
+}
+
+// GetCap returns the queue's capacity
+func (st *RetryFIFO) GetCap() int {
+	return st.fifo.GetCap()
+}
+
+// Lock locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *RetryFIFO) Lock() {
+	st.fifo.Lock()
+}
+
+// Unlock unlocks the queue
+func (st *RetryFIFO) Unlock() {
+	st.fifo.Unlock()
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *RetryFIFO) IsLocked() bool {
+	return st.fifo.IsLocked()
+}
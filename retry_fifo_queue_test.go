@@ -0,0 +1,157 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	retryFIFOMaxAttempts = 3
+)
+
+type RetryFIFOTestSuite struct {
+	suite.Suite
+	fifo *RetryFIFO
+}
+
+func (suite *RetryFIFOTestSuite) SetupTest() {
+	suite.fifo = NewRetryFIFO(retryFIFOMaxAttempts)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestRetryFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryFIFOTestSuite))
+}
+
+// ***************************************************************************************
+// ** Queue interface
+// ***************************************************************************************
+
+// RetryFIFO implements the Queue interface
+func (suite *RetryFIFOTestSuite) TestQueueInterface() {
+	var queue Queue = suite.fifo
+
+	suite.NoError(queue.Enqueue(testValue), "Unexpected error")
+	suite.Equal(1, queue.GetLen(), "Unexpected length")
+
+	val, err := queue.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(testValue, val, "Wrong element's value")
+
+	queue.Lock()
+	suite.True(queue.IsLocked(), "Queue must be locked after Lock()")
+	suite.Error(queue.Enqueue(1), "Locked queue does not allow to enqueue elements")
+	queue.Unlock()
+	suite.False(queue.IsLocked(), "Queue must be unlocked after Unlock()")
+}
+
+// ***************************************************************************************
+// ** EnqueueRetry / DequeueRetry
+// ***************************************************************************************
+
+// attempts get increased on every EnqueueRetry
+func (suite *RetryFIFOTestSuite) TestAttemptsSingleGR() {
+	for i := 1; i <= retryFIFOMaxAttempts; i++ {
+		suite.NoError(suite.fifo.EnqueueRetry(testValue), "Unexpected error")
+
+		val, attempt, err := suite.fifo.DequeueRetry()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(testValue, val, "Wrong element's value")
+		suite.Equal(i, attempt, "Wrong attempt")
+	}
+	suite.Len(suite.fifo.DeadLetters(), 0, "No dead letters expected")
+
+	// max attempts exceeded
+	suite.NoError(suite.fifo.EnqueueRetry(testValue), "Unexpected error")
+	suite.Equal(0, suite.fifo.GetLen(), "The value must not be enqueued after exceeding the max attempts")
+	suite.Equal([]interface{}{testValue}, suite.fifo.DeadLetters(), "The value must be moved to the dead letters")
+
+	// the attempts counter gets restarted
+	suite.NoError(suite.fifo.EnqueueRetry(testValue), "Unexpected error")
+	_, attempt, _ := suite.fifo.DequeueRetry()
+	suite.Equal(1, attempt, "Wrong attempt")
+}
+
+// Ack restarts the attempts counter
+func (suite *RetryFIFOTestSuite) TestAckSingleGR() {
+	suite.fifo.EnqueueRetry(1)
+	suite.fifo.DequeueRetry()
+	suite.fifo.Ack(1)
+
+	suite.fifo.EnqueueRetry(1)
+	_, attempt, _ := suite.fifo.DequeueRetry()
+	suite.Equal(1, attempt, "Wrong attempt after Ack")
+}
+
+// uncomparable values are rejected (no panic)
+func (suite *RetryFIFOTestSuite) TestEnqueueRetryUncomparableSingleGR() {
+	suite.Error(suite.fifo.EnqueueRetry([]int{1}), "error expected for an uncomparable value")
+	suite.Error(suite.fifo.Enqueue(map[int]int{}), "error expected for an uncomparable value")
+	suite.fifo.Ack([]int{1})
+	suite.Equal(0, suite.fifo.GetLen(), "Uncomparable values must not be enqueued")
+}
+
+// dequeue an empty queue
+func (suite *RetryFIFOTestSuite) TestDequeueRetryEmptyQueueSingleGR() {
+	val, attempt, err := suite.fifo.DequeueRetry()
+	suite.Error(err, "Can't dequeue an empty queue")
+	suite.Nil(val, "Can't get a value different than nil from an empty queue")
+	suite.Equal(0, attempt, "Wrong attempt")
+}
+
+// unlimited attempts
+func (suite *RetryFIFOTestSuite) TestUnlimitedAttemptsSingleGR() {
+	suite.fifo = NewRetryFIFO(0)
+	for i := 0; i < 10; i++ {
+		suite.fifo.EnqueueRetry(1)
+	}
+	suite.Equal(10, suite.fifo.GetLen(), "Unexpected length")
+	suite.Len(suite.fifo.DeadLetters(), 0, "No dead letters expected")
+}
+
+// concurrent retries
+func (suite *RetryFIFOTestSuite) TestEnqueueRetryMultipleGRs() {
+	var (
+		wg       sync.WaitGroup
+		totalGRs = retryFIFOMaxAttempts + 1
+	)
+
+	for i := 0; i < totalGRs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			suite.fifo.EnqueueRetry(testValue)
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(retryFIFOMaxAttempts, suite.fifo.GetLen(), "Unexpected length")
+	suite.Len(suite.fifo.DeadLetters(), 1, "Only one dead letter expected")
+}
+
+// ***************************************************************************************
+// ** SetDeadLetterHandler
+// ***************************************************************************************
+
+// the dead letter handler gets called once a value exceeds the max attempts
+func (suite *RetryFIFOTestSuite) TestDeadLetterHandlerSingleGR() {
+	var (
+		values  []interface{}
+		reasons []string
+	)
+	suite.fifo.SetDeadLetterHandler(func(value interface{}, reason string) {
+		values = append(values, value)
+		reasons = append(reasons, reason)
+	})
+
+	for i := 0; i <= retryFIFOMaxAttempts; i++ {
+		suite.fifo.EnqueueRetry(testValue)
+	}
+	suite.Equal([]interface{}{testValue}, values, "Unexpected discarded values")
+	suite.Equal([]string{DeadLetterReasonRetryExhausted}, reasons, "Unexpected reasons")
+}
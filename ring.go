@@ -0,0 +1,45 @@
+package goconcurrentqueue
+
+// ring is a fixed size circular buffer that keeps the latest added values.
+// It is not concurrent-safe, callers must provide their own synchronization.
+type ring struct {
+	values []interface{}
+	// next position to write
+	next int
+	// total stored values (up to len(values))
+	total int
+}
+
+func newRing(size int) *ring {
+	return &ring{
+		values: make([]interface{}, size),
+	}
+}
+
+// add adds a value, overwriting the oldest one if the ring is full
+func (st *ring) add(value interface{}) {
+	if len(st.values) == 0 {
+		return
+	}
+
+	st.values[st.next] = value
+	st.next = (st.next + 1) % len(st.values)
+	if st.total < len(st.values) {
+		st.total++
+	}
+}
+
+// slice returns a copy of the stored values, from the oldest to the newest
+func (st *ring) slice() []interface{} {
+	ret := make([]interface{}, st.total)
+	start := st.next - st.total
+	if start < 0 {
+		start += len(st.values)
+	}
+
+	for i := 0; i < st.total; i++ {
+		ret[i] = st.values[(start+i)%len(st.values)]
+	}
+
+	return ret
+}
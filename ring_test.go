@@ -0,0 +1,55 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ringTestSuite struct {
+	suite.Suite
+	ring *ring
+}
+
+func (suite *ringTestSuite) SetupTest() {
+	suite.ring = newRing(3)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestRingTestSuite(t *testing.T) {
+	suite.Run(t, new(ringTestSuite))
+}
+
+// ***************************************************************************************
+// ** add / slice
+// ***************************************************************************************
+
+// no values at initialization
+func (suite *ringTestSuite) TestEmptyAtInitialization() {
+	suite.Len(suite.ring.slice(), 0, "No values expected at initialization")
+}
+
+// values are returned from the oldest to the newest
+func (suite *ringTestSuite) TestAddNotFull() {
+	suite.ring.add(1)
+	suite.ring.add(2)
+	suite.Equal([]interface{}{1, 2}, suite.ring.slice(), "Unexpected ring values")
+}
+
+// the oldest values are overwritten once the ring is full
+func (suite *ringTestSuite) TestAddOverwrite() {
+	for i := 0; i < 5; i++ {
+		suite.ring.add(i)
+	}
+	suite.Equal([]interface{}{2, 3, 4}, suite.ring.slice(), "Only the latest values are expected")
+}
+
+// a zero size ring does not keep any value
+func (suite *ringTestSuite) TestZeroSize() {
+	suite.ring = newRing(0)
+	suite.ring.add(1)
+	suite.Len(suite.ring.slice(), 0, "A zero size ring must not keep values")
+}
@@ -0,0 +1,100 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// SPSCFixedFIFO is a fixed capacity FIFO (First In First Out) queue for exactly one producer goroutine and one consumer
+// goroutine (single producer / single consumer). It avoids the channel and the mutexes: the elements are kept at a
+// preallocated ring, the producer only moves the tail and the consumer only moves the head (both atomically).
+//
+// It is NOT safe to enqueue from more than one goroutine at once, nor to dequeue from more than one goroutine at once.
+// GetLen, GetCap, Lock, Unlock and IsLocked could be called from any goroutine.
+type SPSCFixedFIFO struct {
+	// 64-bit indexes (accessed atomically) are kept first to guarantee their alignment on 32-bit platforms.
+	// head: next element to be dequeued (moved by the consumer) && tail: next slot to be filled (moved by the producer)
+	head uint64
+	tail uint64
+	// 1 == locked (accessed atomically)
+	locked int32
+	buffer []interface{}
+}
+
+// NewSPSCFixedFIFO returns a new SPSCFixedFIFO queue, see SPSCFixedFIFO for the single producer / single consumer
+// constraint
+func NewSPSCFixedFIFO(capacity int) *SPSCFixedFIFO {
+	queue := &SPSCFixedFIFO{}
+	queue.initialize(capacity)
+
+	return queue
+}
+
+func (st *SPSCFixedFIFO) initialize(capacity int) {
+	st.buffer = make([]interface{}, capacity)
+}
+
+// Enqueue enqueues an element. It must be called only from the producer goroutine.
+func (st *SPSCFixedFIFO) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return errors.New("The queue is locked")
+	}
+
+	tail := atomic.LoadUint64(&st.tail)
+	if tail-atomic.LoadUint64(&st.head) >= uint64(len(st.buffer)) {
+		return errors.New("SPSCFixedFIFO queue is at full capacity")
+	}
+
+	st.buffer[tail%uint64(len(st.buffer))] = value
+	atomic.StoreUint64(&st.tail, tail+1)
+
+	return nil
+}
+
+// Dequeue dequeues an element. It must be called only from the consumer goroutine.
+func (st *SPSCFixedFIFO) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, errors.New("The queue is locked")
+	}
+
+	head := atomic.LoadUint64(&st.head)
+	if head == atomic.LoadUint64(&st.tail) {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	index := head % uint64(len(st.buffer))
+	value := st.buffer[index]
+	// do not keep a reference to the dequeued element
+	st.buffer[index] = nil
+	atomic.StoreUint64(&st.head, head+1)
+
+	return value, nil
+}
+
+// GetLen returns queue's length (total enqueued elements)
+func (st *SPSCFixedFIFO) GetLen() int {
+	// the head is read first: the tail could only move forward in the meantime
+	head := atomic.LoadUint64(&st.head)
+	return int(atomic.LoadUint64(&st.tail) - head)
+}
+
+// GetCap returns the queue's capacity
+func (st *SPSCFixedFIFO) GetCap() int {
+	return len(st.buffer)
+}
+
+// Lock locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *SPSCFixedFIFO) Lock() {
+	atomic.StoreInt32(&st.locked, 1)
+}
+
+// Unlock unlocks the queue
+func (st *SPSCFixedFIFO) Unlock() {
+	atomic.StoreInt32(&st.locked, 0)
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *SPSCFixedFIFO) IsLocked() bool {
+	return atomic.LoadInt32(&st.locked) == 1
+}
@@ -0,0 +1,62 @@
+package goconcurrentqueue
+
+import (
+	"runtime"
+	"testing"
+)
+
+// ***************************************************************************************
+// ** Single producer / single consumer: SPSCFixedFIFO vs FixedFIFO
+// ***************************************************************************************
+
+// one producer gr && one consumer gr (b.N elements) - SPSCFixedFIFO
+func BenchmarkSPSCFixedFIFOProducerConsumer(b *testing.B) {
+	benchmarkProducerConsumer(b, NewSPSCFixedFIFO(1000))
+}
+
+// one producer gr && one consumer gr (b.N elements) - FixedFIFO
+func BenchmarkFixedFIFOProducerConsumer(b *testing.B) {
+	benchmarkProducerConsumer(b, NewFixedFIFO(1000))
+}
+
+// single goroutine - enqueue && dequeue 1 element - SPSCFixedFIFO
+func BenchmarkSPSCFixedFIFOEnqueueDequeueSingleGR(b *testing.B) {
+	fifo := NewSPSCFixedFIFO(5)
+	for i := 0; i < b.N; i++ {
+		fifo.Enqueue(i)
+		fifo.Dequeue()
+	}
+}
+
+// single goroutine - enqueue && dequeue 1 element - FixedFIFO
+func BenchmarkFixedFIFOEnqueueDequeueSingleGR(b *testing.B) {
+	fifo := NewFixedFIFO(5)
+	for i := 0; i < b.N; i++ {
+		fifo.Enqueue(i)
+		fifo.Dequeue()
+	}
+}
+
+// benchmarkProducerConsumer moves b.N elements from a producer gr to a consumer gr (yielding while full / empty)
+func benchmarkProducerConsumer(b *testing.B, queue Queue) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < b.N; {
+			if _, err := queue.Dequeue(); err != nil {
+				runtime.Gosched()
+				continue
+			}
+			i++
+		}
+	}()
+
+	for i := 0; i < b.N; {
+		if queue.Enqueue(i) != nil {
+			runtime.Gosched()
+			continue
+		}
+		i++
+	}
+	<-done
+}
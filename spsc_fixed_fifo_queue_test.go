@@ -0,0 +1,126 @@
+package goconcurrentqueue
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	spscFixedFIFOCapacity = 10
+)
+
+type SPSCFixedFIFOTestSuite struct {
+	suite.Suite
+	fifo *SPSCFixedFIFO
+}
+
+func (suite *SPSCFixedFIFOTestSuite) SetupTest() {
+	suite.fifo = NewSPSCFixedFIFO(spscFixedFIFOCapacity)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestSPSCFixedFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(SPSCFixedFIFOTestSuite))
+}
+
+// ***************************************************************************************
+// ** Queue interface
+// ***************************************************************************************
+
+// SPSCFixedFIFO implements the Queue interface
+func (suite *SPSCFixedFIFOTestSuite) TestQueueInterface() {
+	var queue Queue = suite.fifo
+
+	suite.NoError(queue.Enqueue(testValue), "Unexpected error")
+	suite.Equal(1, queue.GetLen(), "Unexpected length")
+	suite.Equal(spscFixedFIFOCapacity, queue.GetCap(), "Unexpected capacity")
+
+	value, err := queue.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(testValue, value, "Wrong element's value")
+}
+
+// ***************************************************************************************
+// ** Enqueue / Dequeue
+// ***************************************************************************************
+
+// elements are dequeued in order, wrapping around the ring
+func (suite *SPSCFixedFIFOTestSuite) TestEnqueueDequeueSingleGR() {
+	for round := 0; round < 3; round++ {
+		for i := 0; i < spscFixedFIFOCapacity; i++ {
+			suite.NoError(suite.fifo.Enqueue(i), "Unexpected error")
+		}
+		suite.Error(suite.fifo.Enqueue(spscFixedFIFOCapacity), "Full capacity error expected")
+		suite.Equal(spscFixedFIFOCapacity, suite.fifo.GetLen(), "Wrong queue's length")
+
+		for i := 0; i < spscFixedFIFOCapacity; i++ {
+			value, err := suite.fifo.Dequeue()
+			suite.NoError(err, "Unexpected error")
+			suite.Equal(i, value, "Wrong element's value")
+		}
+		_, err := suite.fifo.Dequeue()
+		suite.Error(err, "Empty queue error expected")
+	}
+
+	for _, slot := range suite.fifo.buffer {
+		suite.Nil(slot, "No references to the dequeued elements expected")
+	}
+}
+
+// zero capacity queue
+func (suite *SPSCFixedFIFOTestSuite) TestZeroCapacitySingleGR() {
+	suite.fifo = NewSPSCFixedFIFO(0)
+
+	suite.Error(suite.fifo.Enqueue(1), "Full capacity error expected")
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "Empty queue error expected")
+}
+
+// locked queue
+func (suite *SPSCFixedFIFOTestSuite) TestLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.True(suite.fifo.IsLocked(), "The queue must be locked")
+
+	suite.Error(suite.fifo.Enqueue(2), "The queue is locked")
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "The queue is locked")
+
+	suite.fifo.Unlock()
+	suite.False(suite.fifo.IsLocked(), "The queue must be unlocked")
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(1, value, "Wrong element's value")
+}
+
+// one producer && one consumer
+func (suite *SPSCFixedFIFOTestSuite) TestProducerConsumerMultipleGRs() {
+	totalElements := 10000
+
+	go func() {
+		for i := 0; i < totalElements; {
+			if suite.fifo.Enqueue(i) != nil {
+				// full queue
+				runtime.Gosched()
+				continue
+			}
+			i++
+		}
+	}()
+
+	for i := 0; i < totalElements; {
+		value, err := suite.fifo.Dequeue()
+		if err != nil {
+			// empty queue
+			runtime.Gosched()
+			continue
+		}
+		suite.Equal(i, value, "Wrong element's order")
+		i++
+	}
+	suite.Equal(0, suite.fifo.GetLen(), "Empty queue expected")
+}
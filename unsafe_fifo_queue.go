@@ -0,0 +1,81 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// UnsafeFIFO is a FIFO (First In First Out) queue without any synchronization, for single goroutine use (e.g.
+// single-threaded pipelines, benchmarks). Its ordering and errors are the same as FIFO's ones.
+//
+// It is NOT safe for concurrent use: all of its methods (Lock/Unlock included) must be called from the same goroutine
+// (or be externally synchronized).
+type UnsafeFIFO struct {
+	slice    []interface{}
+	isLocked bool
+}
+
+// NewUnsafeFIFO returns a new UnsafeFIFO queue, see UnsafeFIFO for the single goroutine constraint
+func NewUnsafeFIFO() *UnsafeFIFO {
+	ret := &UnsafeFIFO{}
+	ret.initialize()
+
+	return ret
+}
+
+func (st *UnsafeFIFO) initialize() {
+	st.slice = make([]interface{}, 0)
+}
+
+// Enqueue enqueues an element
+func (st *UnsafeFIFO) Enqueue(value interface{}) error {
+	if st.isLocked {
+		return errors.New("The queue is locked")
+	}
+
+	st.slice = append(st.slice, value)
+
+	return nil
+}
+
+// Dequeue dequeues an element
+func (st *UnsafeFIFO) Dequeue() (interface{}, error) {
+	if st.isLocked {
+		return nil, errors.New("The queue is locked")
+	}
+
+	if len(st.slice) == 0 {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	elementToReturn := st.slice[0]
+	st.slice = st.slice[1:]
+
+	return elementToReturn, nil
+}
+
+// GetLen returns the number of enqueued elements
+func (st *UnsafeFIFO) GetLen() int {
+	return len(st.slice)
+}
+
+// GetCap returns the queue's capacity
+func (st *UnsafeFIFO) GetCap() int {
+	return cap(st.slice)
+}
+
+// Lock locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *UnsafeFIFO) Lock() {
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *UnsafeFIFO) Unlock() {
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *UnsafeFIFO) IsLocked() bool {
+	return st.isLocked
+}
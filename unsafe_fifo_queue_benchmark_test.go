@@ -0,0 +1,27 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+)
+
+// ***************************************************************************************
+// ** Single goroutine: UnsafeFIFO vs FIFO
+// ***************************************************************************************
+
+// single goroutine - enqueue && dequeue 1 element - UnsafeFIFO
+func BenchmarkUnsafeFIFOEnqueueDequeueSingleGR(b *testing.B) {
+	fifo := NewUnsafeFIFO()
+	for i := 0; i < b.N; i++ {
+		fifo.Enqueue(i)
+		fifo.Dequeue()
+	}
+}
+
+// single goroutine - enqueue && dequeue 1 element - FIFO
+func BenchmarkFIFOEnqueueDequeueSingleGR(b *testing.B) {
+	fifo := NewFIFO()
+	for i := 0; i < b.N; i++ {
+		fifo.Enqueue(i)
+		fifo.Dequeue()
+	}
+}
@@ -0,0 +1,81 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type UnsafeFIFOTestSuite struct {
+	suite.Suite
+	fifo *UnsafeFIFO
+}
+
+func (suite *UnsafeFIFOTestSuite) SetupTest() {
+	suite.fifo = NewUnsafeFIFO()
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestUnsafeFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(UnsafeFIFOTestSuite))
+}
+
+// ***************************************************************************************
+// ** Queue interface
+// ***************************************************************************************
+
+// UnsafeFIFO implements the Queue interface
+func (suite *UnsafeFIFOTestSuite) TestQueueInterface() {
+	var queue Queue = suite.fifo
+
+	suite.NoError(queue.Enqueue(testValue), "Unexpected error")
+	suite.Equal(1, queue.GetLen(), "Unexpected length")
+
+	value, err := queue.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(testValue, value, "Wrong element's value")
+}
+
+// ***************************************************************************************
+// ** Enqueue / Dequeue
+// ***************************************************************************************
+
+// elements are dequeued in order
+func (suite *UnsafeFIFOTestSuite) TestEnqueueDequeueSingleGR() {
+	for i := 0; i < 10; i++ {
+		suite.NoError(suite.fifo.Enqueue(i), "Unexpected error")
+	}
+	suite.Equal(10, suite.fifo.GetLen(), "Wrong queue's length")
+
+	for i := 0; i < 10; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err, "Unexpected error")
+		suite.Equal(i, value, "Wrong element's value")
+	}
+
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "Empty queue error expected")
+}
+
+// same errors as FIFO
+func (suite *UnsafeFIFOTestSuite) TestErrorsSingleGR() {
+	fifo := NewFIFO()
+	_, expected := fifo.Dequeue()
+	_, err := suite.fifo.Dequeue()
+	suite.Equal(expected.Error(), err.Error(), "Wrong empty queue error")
+
+	fifo.Lock()
+	suite.fifo.Lock()
+	suite.True(suite.fifo.IsLocked(), "The queue must be locked")
+	suite.Equal(fifo.Enqueue(1).Error(), suite.fifo.Enqueue(1).Error(), "Wrong locked queue error")
+	_, expected = fifo.Dequeue()
+	_, err = suite.fifo.Dequeue()
+	suite.Equal(expected.Error(), err.Error(), "Wrong locked queue error")
+
+	suite.fifo.Unlock()
+	suite.False(suite.fifo.IsLocked(), "The queue must be unlocked")
+	suite.NoError(suite.fifo.Enqueue(1), "Unexpected error")
+}
@@ -0,0 +1,171 @@
+package goconcurrentqueue
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// WeightedBandFIFO is a concurrent queue made of priority bands (band 0 == highest priority), each one being a FIFO.
+// Dequeue follows a weighted round-robin across the bands: up to weights[i] elements are dequeued from band i before
+// moving to the next one, so the high priority bands are favored but the low priority ones never starve. Empty bands
+// are skipped.
+type WeightedBandFIFO struct {
+	bands   [][]interface{}
+	weights []int
+	// band being dequeued && elements it could still dequeue before moving to the next one
+	current int
+	credit  int
+	rwmutex sync.RWMutex
+	// lock
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+}
+
+// NewWeightedBandFIFO returns a new WeightedBandFIFO concurrent queue having len(weights) bands, weights[i] being the
+// number of consecutive elements band i gets to dequeue per round. Weights <= 0 are taken as 1, no weights at all means
+// a single band.
+func NewWeightedBandFIFO(weights []int) *WeightedBandFIFO {
+	ret := &WeightedBandFIFO{}
+	ret.initialize(weights)
+
+	return ret
+}
+
+func (st *WeightedBandFIFO) initialize(weights []int) {
+	if len(weights) == 0 {
+		weights = []int{1}
+	}
+
+	st.weights = make([]int, len(weights))
+	st.bands = make([][]interface{}, len(weights))
+	for i, weight := range weights {
+		if weight <= 0 {
+			weight = 1
+		}
+		st.weights[i] = weight
+		st.bands[i] = make([]interface{}, 0)
+	}
+	st.credit = st.weights[0]
+}
+
+// Enqueue enqueues an element at the lowest priority band
+func (st *WeightedBandFIFO) Enqueue(value interface{}) error {
+	return st.EnqueueBand(value, len(st.weights)-1)
+}
+
+// EnqueueBand enqueues an element at the given band (0 == highest priority)
+func (st *WeightedBandFIFO) EnqueueBand(value interface{}, band int) error {
+	if st.IsLocked() {
+		return errors.New("The queue is locked")
+	}
+
+	if band < 0 || band >= len(st.weights) {
+		return fmt.Errorf("band out of bounds: %v", band)
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.bands[band] = append(st.bands[band], value)
+
+	return nil
+}
+
+// Dequeue dequeues an element following the weighted round-robin across the bands
+func (st *WeightedBandFIFO) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, errors.New("The queue is locked")
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	// every band gets checked once (plus the current one again, in case it was the only one having elements)
+	for i := 0; i <= len(st.bands); i++ {
+		band := st.bands[st.current]
+		if len(band) == 0 || st.credit == 0 {
+			st.nextBand()
+			continue
+		}
+
+		elementToReturn := band[0]
+		st.bands[st.current] = band[1:]
+		st.credit--
+		if st.credit == 0 {
+			st.nextBand()
+		}
+
+		return elementToReturn, nil
+	}
+
+	return nil, fmt.Errorf("queue is empty")
+}
+
+// nextBand moves the round-robin to the next band. It must be called holding st.rwmutex.
+func (st *WeightedBandFIFO) nextBand() {
+	st.current = (st.current + 1) % len(st.bands)
+	st.credit = st.weights[st.current]
+}
+
+// GetBandLen returns the number of elements enqueued at the given band
+func (st *WeightedBandFIFO) GetBandLen(band int) (int, error) {
+	if band < 0 || band >= len(st.weights) {
+		return 0, fmt.Errorf("band out of bounds: %v", band)
+	}
+
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return len(st.bands[band]), nil
+}
+
+// GetLen returns the number of enqueued elements (all bands)
+func (st *WeightedBandFIFO) GetLen() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	total := 0
+	for _, band := range st.bands {
+		total += len(band)
+	}
+
+	return total
+}
+
+// GetCap returns the queue's capacity (all bands)
+func (st *WeightedBandFIFO) GetCap() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	total := 0
+	for _, band := range st.bands {
+		total += cap(band)
+	}
+
+	return total
+}
+
+// Lock locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *WeightedBandFIFO) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *WeightedBandFIFO) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *WeightedBandFIFO) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
@@ -0,0 +1,160 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WeightedBandFIFOTestSuite struct {
+	suite.Suite
+	fifo *WeightedBandFIFO
+}
+
+func (suite *WeightedBandFIFOTestSuite) SetupTest() {
+	suite.fifo = NewWeightedBandFIFO([]int{3, 2, 1})
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestWeightedBandFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(WeightedBandFIFOTestSuite))
+}
+
+// ***************************************************************************************
+// ** Queue interface
+// ***************************************************************************************
+
+// WeightedBandFIFO implements the Queue interface
+func (suite *WeightedBandFIFOTestSuite) TestQueueInterface() {
+	var queue Queue = suite.fifo
+
+	suite.NoError(queue.Enqueue(testValue), "Unexpected error")
+	suite.Equal(1, queue.GetLen(), "Unexpected length")
+	bandLen, _ := suite.fifo.GetBandLen(2)
+	suite.Equal(1, bandLen, "Enqueue must use the lowest priority band")
+
+	value, err := queue.Dequeue()
+	suite.NoError(err, "Unexpected error")
+	suite.Equal(testValue, value, "Wrong element's value")
+
+	_, err = queue.Dequeue()
+	suite.Error(err, "Empty queue error expected")
+}
+
+// ***************************************************************************************
+// ** EnqueueBand / Dequeue
+// ***************************************************************************************
+
+// dequeue follows the weighted round-robin
+func (suite *WeightedBandFIFOTestSuite) TestWeightedRoundRobinSingleGR() {
+	for i := 0; i < 6; i++ {
+		suite.fifo.EnqueueBand("high", 0)
+		suite.fifo.EnqueueBand("medium", 1)
+		suite.fifo.EnqueueBand("low", 2)
+	}
+
+	expected := []interface{}{
+		"high", "high", "high", "medium", "medium", "low",
+		"high", "high", "high", "medium", "medium", "low",
+	}
+	for i, expectedValue := range expected {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err, "Unexpected error")
+		suite.Equalf(expectedValue, value, "Wrong band at dequeue #%v", i)
+	}
+
+	// no high priority elements: the rest of the bands keep their weights
+	expected = []interface{}{"medium", "medium", "low", "low", "low", "low"}
+	for i, expectedValue := range expected {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err, "Unexpected error")
+		suite.Equalf(expectedValue, value, "Wrong band at dequeue #%v", i)
+	}
+
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "Empty queue error expected")
+}
+
+// elements are dequeued in FIFO order within a band
+func (suite *WeightedBandFIFOTestSuite) TestBandOrderSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.EnqueueBand(i, 1)
+	}
+
+	for i := 0; i < 5; i++ {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(i, value, "Wrong element's value")
+	}
+}
+
+// invalid band
+func (suite *WeightedBandFIFOTestSuite) TestEnqueueBandOutOfRangeSingleGR() {
+	suite.Error(suite.fifo.EnqueueBand(1, -1), "band out of bounds error expected")
+	suite.Error(suite.fifo.EnqueueBand(1, 3), "band out of bounds error expected")
+	_, err := suite.fifo.GetBandLen(3)
+	suite.Error(err, "band out of bounds error expected")
+}
+
+// invalid weights
+func (suite *WeightedBandFIFOTestSuite) TestNewWeightedBandFIFOWeightsSingleGR() {
+	fifo := NewWeightedBandFIFO(nil)
+	suite.NoError(fifo.EnqueueBand(1, 0), "A single band expected")
+	suite.Error(fifo.EnqueueBand(1, 1), "A single band expected")
+
+	fifo = NewWeightedBandFIFO([]int{0, -1})
+	suite.Equal([]int{1, 1}, fifo.weights, "Weights <= 0 must be taken as 1")
+}
+
+// locked queue
+func (suite *WeightedBandFIFOTestSuite) TestLockSingleGR() {
+	suite.fifo.EnqueueBand(1, 0)
+	suite.fifo.Lock()
+	suite.True(suite.fifo.IsLocked(), "The queue must be locked")
+
+	suite.Error(suite.fifo.EnqueueBand(2, 0), "The queue is locked")
+	_, err := suite.fifo.Dequeue()
+	suite.Error(err, "The queue is locked")
+
+	suite.fifo.Unlock()
+	suite.False(suite.fifo.IsLocked(), "The queue must be unlocked")
+}
+
+// concurrent enqueues/dequeues
+func (suite *WeightedBandFIFOTestSuite) TestEnqueueDequeueMultipleGRs() {
+	var (
+		totalGRs = 90
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		values   = make(map[int]struct{})
+	)
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func(value int) {
+			defer wg.Done()
+			suite.NoError(suite.fifo.EnqueueBand(value, value%3), "Unexpected error")
+		}(i)
+	}
+	wg.Wait()
+
+	wg.Add(totalGRs)
+	for i := 0; i < totalGRs; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := suite.fifo.Dequeue()
+			suite.NoError(err, "Unexpected error")
+
+			mutex.Lock()
+			values[value.(int)] = struct{}{}
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	suite.Len(values, totalGRs, "Every element must be dequeued once")
+	suite.Equal(0, suite.fifo.GetLen(), "Empty queue expected")
+}